@@ -0,0 +1,248 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "os"
+    "path"
+    "strings"
+    "time"
+
+    "github.com/gin-contrib/cors"
+    "github.com/gin-gonic/gin"
+    "social-media-backend/config"
+    "social-media-backend/controllers"
+    "social-media-backend/jwtkeys"
+    "social-media-backend/loginguard"
+    "social-media-backend/mailer"
+    "social-media-backend/oidc"
+    "social-media-backend/password"
+    "social-media-backend/policy"
+    "social-media-backend/repository"
+    "social-media-backend/services"
+)
+
+// configureControllers mengisi pengaturan package controllers (kunci JWT,
+// hash password, upload, mailer, dan sebagainya) dari cfg. config.DB harus
+// sudah terhubung.
+func configureControllers(cfg *config.Config) error {
+    keys, err := jwtkeys.Load(jwtkeys.Options{
+        SigningKeyFile:       cfg.JWT.SigningKeyFile,
+        SigningKeyID:         cfg.JWT.SigningKeyID,
+        Secret:               cfg.JWT.Secret,
+        SecretID:             cfg.JWT.SecretID,
+        VerificationKeyFiles: cfg.JWT.VerificationKeyFiles,
+        PreviousSecrets:      cfg.JWT.PreviousSecrets,
+    })
+    if err != nil {
+        return fmt.Errorf("Konfigurasi kunci JWT tidak valid: %w", err)
+    }
+    controllers.SigningKeys = keys
+    controllers.AccessTokenTTL = cfg.JWT.AccessTokenTTL
+    controllers.RefreshTokenTTL = cfg.JWT.RefreshTokenTTL
+
+    passwords, err := password.NewManager(password.Options{
+        Hasher:            cfg.Password.Hasher,
+        Argon2MemoryKiB:   cfg.Password.Argon2MemoryKiB,
+        Argon2Iterations:  cfg.Password.Argon2Iterations,
+        Argon2Parallelism: cfg.Password.Argon2Parallelism,
+        BcryptCost:        cfg.Password.BcryptCost,
+    })
+    if err != nil {
+        return fmt.Errorf("Konfigurasi hash password tidak valid: %w", err)
+    }
+    controllers.Passwords = passwords
+    passwordPolicy, err := password.NewPolicy(password.PolicyOptions{
+        MinLength:             cfg.Password.MinLength,
+        MaxLength:             cfg.Password.MaxLength,
+        MinEntropy:            cfg.Password.MinEntropy,
+        BreachedPasswordsFile: cfg.Password.BreachedPasswordsFile,
+    })
+    if err != nil {
+        return fmt.Errorf("Konfigurasi kebijakan password tidak valid: %w", err)
+    }
+    if passwordPolicy.Breached != nil {
+        log.Printf("Korpus password bocor dimuat: %d hash", passwordPolicy.Breached.Len())
+    }
+    controllers.PasswordPolicy = passwordPolicy
+
+    // Penghitung brute-force login disimpan di database jika aplikasi
+    // dijalankan di lebih dari satu instance.
+    if cfg.Security.LoginGuardStore == "database" {
+        controllers.LoginGuard.Store = loginguard.NewDBStore(config.DB)
+    }
+
+    // File upload disimpan di disk lokal (storage.backend "local").
+    if err := os.MkdirAll(cfg.Uploads.Dir, 0o755); err != nil {
+        return fmt.Errorf("Gagal membuat direktori upload: %w", err)
+    }
+    controllers.UploadDir = path.Clean(cfg.Uploads.Dir)
+    controllers.MaxUploadSize = cfg.Uploads.MaxFileSize
+    controllers.MaxUploadFiles = cfg.Uploads.MaxFiles
+
+    controllers.Mailer = mailer.New(mailer.SMTPMailer{
+        Host:     cfg.Mail.SMTPHost,
+        Port:     cfg.Mail.SMTPPort,
+        Username: cfg.Mail.SMTPUsername,
+        Password: cfg.Mail.SMTPPassword,
+        From:     cfg.Mail.From,
+    })
+    controllers.AppBaseURL = cfg.Server.BaseURL
+    controllers.FrontendURL = cfg.Server.FrontendURL
+    controllers.RequireEmailVerification = cfg.Features.RequireEmailVerification
+
+    // Provider login eksternal (OAuth2/OpenID Connect).
+    providers, err := oidc.ProvidersFromEnv(controllers.AppBaseURL)
+    if err != nil {
+        return fmt.Errorf("Konfigurasi OIDC tidak valid: %w", err)
+    }
+    controllers.OIDCProviders = providers
+    controllers.OIDCRedirectURL = cfg.Server.OIDCRedirectURL
+
+    // Masa tenggang sebelum akun yang dinonaktifkan dihapus permanen.
+    controllers.AccountDeletionGracePeriod = time.Duration(cfg.Security.AccountDeletionGraceDays) * 24 * time.Hour
+    return nil
+}
+
+// newRouter membuat router dengan semua endpoint aplikasi. Dipakai oleh main
+// dan oleh test end-to-end, sehingga route yang diuji sama dengan yang
+// berjalan di production.
+func newRouter(cfg *config.Config) *gin.Engine {
+    // Handler fitur sosial memakai service di atas repository GORM.
+    svc := services.New(repository.NewGorm(config.DB))
+    users := controllers.NewUserHandler(svc.Users)
+    profiles := controllers.NewProfileHandler(svc.Profiles)
+    follows := controllers.NewFollowHandler(svc.Follows)
+    blocks := controllers.NewBlockHandler(svc.Blocks)
+    feeds := controllers.NewFeedHandler(svc.Feeds)
+    chats := controllers.NewChatHandler(svc.Chats)
+
+    r := gin.Default()
+
+    // Konfigurasi CORS
+    r.Use(cors.New(cors.Config{
+        AllowOrigins:     cfg.CORS.AllowedOrigins,
+        AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+        AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+        ExposeHeaders:    []string{"Content-Length"},
+        AllowCredentials: true,
+        MaxAge:           cfg.CORS.MaxAge,
+    }))
+
+    // Endpoint autentikasi.
+    r.Static("/public", "./public")
+    // Path file upload diawali UploadDir; direktori di luar public perlu
+    // route static sendiri.
+    if !strings.HasPrefix(controllers.UploadDir+"/", "public/") {
+        r.Static("/"+controllers.UploadDir, controllers.UploadDir)
+    }
+    r.GET("/.well-known/jwks.json", controllers.JWKS)
+    if cfg.Features.Registration {
+        r.POST("/register", controllers.Register)
+    }
+    r.POST("/login", controllers.Login)
+    r.POST("/login/mfa", controllers.LoginMFA)
+    if cfg.Features.MagicLinkLogin {
+        r.POST("/login/magic-link", controllers.RequestMagicLink)
+        r.POST("/login/magic-link/consume", controllers.ConsumeMagicLink)
+    }
+    r.GET("/auth/:provider/login", controllers.OIDCLogin)
+    r.GET("/auth/:provider/callback", controllers.OIDCCallback)
+    r.POST("/token/refresh", controllers.RefreshToken)
+    r.GET("/verify-email", controllers.VerifyEmail)
+    r.POST("/password/forgot", controllers.ForgotPassword)
+    r.POST("/password/reset", controllers.ResetPassword)
+
+    // Group endpoint yang dilindungi oleh autentikasi. Endpoint dapat diakses
+    // dengan access token dari login atau dengan API token yang memiliki
+    // scope sesuai (RequireScope).
+    authorized := r.Group("/")
+    authorized.Use(controllers.AuthMiddleware())
+    {
+        // Endpoint profile user.
+        authorized.GET("/profile", controllers.RequireScope(policy.ScopeProfileRead), controllers.GetProfile)
+
+        // Endpoint follow.
+        authorized.POST("/follow/:id", controllers.RequireScope(policy.ScopeFollowsWrite), follows.FollowUser)
+        authorized.DELETE("/follow/:id", controllers.RequireScope(policy.ScopeFollowsWrite), follows.UnfollowUser)
+        authorized.GET("/followers", controllers.RequireScope(policy.ScopeFollowsRead), follows.GetFollowers)
+        authorized.GET("/following", controllers.RequireScope(policy.ScopeFollowsRead), follows.GetFollowing)
+
+        // Endpoint blokir user.
+        authorized.POST("/block/:id", controllers.RequireScope(policy.ScopeBlocksWrite), blocks.BlockUser)
+        authorized.DELETE("/block/:id", controllers.RequireScope(policy.ScopeBlocksWrite), blocks.UnblockUser)
+
+        // Endpoint profil publik user.
+        authorized.GET("/users/:username", controllers.RequireScope(policy.ScopeUsersRead), profiles.GetUserProfile)
+        authorized.GET("/users/:username/feeds", controllers.RequireScope(policy.ScopeFeedsRead), profiles.GetUserFeeds)
+        authorized.GET("/users/:username/followers", controllers.RequireScope(policy.ScopeFollowsRead), profiles.GetUserFollowers)
+        authorized.GET("/users/:username/following", controllers.RequireScope(policy.ScopeFollowsRead), profiles.GetUserFollowing)
+
+        // Endpoint feeds & comments.
+        authorized.GET("/feeds", controllers.RequireScope(policy.ScopeFeedsRead), feeds.GetFeeds)
+        authorized.POST("/feeds", controllers.RequireScope(policy.ScopeFeedsWrite), controllers.RequireVerifiedEmail(), feeds.CreateFeed)
+        authorized.PUT("/feeds/:feed_id", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.UpdateFeed)
+        authorized.DELETE("/feeds/:feed_id", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.DeleteFeed)
+        authorized.POST("/feeds/:feed_id/comments", controllers.RequireScope(policy.ScopeFeedsWrite), controllers.RequireVerifiedEmail(), feeds.CreateComment)
+        authorized.PUT("/comments/:id", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.UpdateComment)
+        authorized.DELETE("/comments/:id", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.DeleteComment)
+        authorized.POST("/feeds/:feed_id/like", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.LikeFeed)
+        authorized.POST("/feeds/:feed_id/dislike", controllers.RequireScope(policy.ScopeFeedsWrite), feeds.DislikeFeed)
+
+        // Endpoint chat.
+        authorized.GET("/users", controllers.RequireScope(policy.ScopeUsersRead), users.GetAllUsers)
+        authorized.POST("/chatrooms", controllers.RequireScope(policy.ScopeChatWrite), controllers.RequireVerifiedEmail(), chats.CreateChatroom)
+        authorized.GET("/chatrooms", controllers.RequireScope(policy.ScopeChatRead), chats.GetChatrooms)
+        authorized.GET("/chatrooms/:id/messages", controllers.RequireScope(policy.ScopeChatRead), chats.GetChatroomMessages)
+        authorized.POST("/chatrooms/:id/messages", controllers.RequireScope(policy.ScopeChatWrite), controllers.RequireVerifiedEmail(), chats.SendMessage)
+        authorized.DELETE("/chatrooms/:id", controllers.RequireScope(policy.ScopeChatWrite), chats.DeleteChatroom)
+        authorized.DELETE("/messages/:id", controllers.RequireScope(policy.ScopeChatWrite), chats.DeleteMessage)
+    }
+
+    // Endpoint sensitif yang hanya dapat diakses dari sesi login, tidak
+    // dengan API token.
+    sessionOnly := authorized.Group("/")
+    sessionOnly.Use(controllers.RequireSessionAuth())
+    {
+        sessionOnly.POST("/logout", controllers.Logout)
+
+        // Endpoint sesi/perangkat yang sedang login.
+        sessionOnly.GET("/sessions", controllers.GetSessions)
+        sessionOnly.DELETE("/sessions/:id", controllers.RevokeSession)
+        sessionOnly.DELETE("/sessions", controllers.RevokeOtherSessions)
+
+        // Endpoint API token (personal access token).
+        sessionOnly.GET("/tokens", controllers.GetAPITokens)
+        sessionOnly.POST("/tokens", controllers.CreateAPIToken)
+        sessionOnly.DELETE("/tokens/:id", controllers.RevokeAPIToken)
+
+        // Endpoint profile user.
+        sessionOnly.PUT("/profile", users.UpdateProfile)
+        sessionOnly.PUT("/profile/password", users.ChangePassword)
+        sessionOnly.DELETE("/profile", controllers.DeactivateAccount)
+        sessionOnly.POST("/verify-email/resend", controllers.ResendVerificationEmail)
+        sessionOnly.GET("/profile/security-events", controllers.GetSecurityEvents)
+
+        // Endpoint two-factor authentication (TOTP).
+        sessionOnly.POST("/profile/2fa/enroll", controllers.EnrollTOTP)
+        sessionOnly.POST("/profile/2fa/confirm", controllers.ConfirmTOTP)
+        sessionOnly.POST("/profile/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+        sessionOnly.DELETE("/profile/2fa", controllers.DisableTOTP)
+
+        // Endpoint moderasi: moderator dan admin dapat menghapus konten siapa pun.
+        admin := sessionOnly.Group("/admin")
+        admin.DELETE("/feeds/:feed_id", controllers.RequirePermission(policy.DeleteAnyFeed), feeds.DeleteFeed)
+        admin.DELETE("/comments/:id", controllers.RequirePermission(policy.DeleteAnyComment), feeds.DeleteComment)
+        admin.DELETE("/messages/:id", controllers.RequirePermission(policy.DeleteAnyMessage), chats.DeleteMessage)
+        admin.GET("/security-events", controllers.RequirePermission(policy.ViewAuditLog), controllers.AdminGetSecurityEvents)
+
+        // Endpoint pengelolaan role, hanya untuk admin.
+        roles := admin.Group("/users")
+        roles.Use(controllers.RequirePermission(policy.ManageRoles))
+        {
+            roles.GET("", controllers.AdminGetUsers)
+            roles.PUT("/:id/role", controllers.UpdateUserRole)
+        }
+    }
+    return r
+}
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment tempat aplikasi dijalankan.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config adalah seluruh konfigurasi aplikasi. Nilai dibaca berurutan dari
+// Default, file konfigurasi (YAML atau TOML, lokasinya dari CONFIG_FILE),
+// lalu environment variable, sehingga satu binary dapat dijalankan untuk
+// development, staging, dan production hanya dengan konfigurasi berbeda.
+//
+// Tag key adalah nama kunci di file konfigurasi dan tag env nama environment
+// variable-nya. Provider OIDC tidak termasuk di sini karena namanya dinamis;
+// lihat oidc.ProvidersFromEnv.
+type Config struct {
+	Env      string         `key:"env" env:"APP_ENV"`
+	Server   ServerConfig   `key:"server"`
+	Database DatabaseConfig `key:"database"`
+	CORS     CORSConfig     `key:"cors"`
+	JWT      JWTConfig      `key:"jwt"`
+	Uploads  UploadsConfig  `key:"uploads"`
+	Storage  StorageConfig  `key:"storage"`
+	Mail     MailConfig     `key:"mail"`
+	Password PasswordConfig `key:"password"`
+	Security SecurityConfig `key:"security"`
+	Features FeaturesConfig `key:"features"`
+}
+
+type ServerConfig struct {
+	Port string `key:"port" env:"PORT"`
+	// BaseURL adalah URL publik backend, dipakai untuk tautan di email.
+	BaseURL string `key:"base_url" env:"APP_BASE_URL"`
+	// FrontendURL dipakai untuk tautan yang dibuka di frontend.
+	FrontendURL string `key:"frontend_url" env:"FRONTEND_URL"`
+	// OIDCRedirectURL adalah halaman frontend tujuan setelah login OIDC.
+	OIDCRedirectURL string `key:"oidc_redirect_url" env:"OIDC_REDIRECT_URL"`
+}
+
+// DatabaseConfig memilih driver (mysql, postgres, atau sqlite) dan DSN-nya;
+// lihat Dialector untuk contoh DSN tiap driver.
+type DatabaseConfig struct {
+	Driver string `key:"driver" env:"DB_DRIVER"`
+	DSN    string `key:"dsn" env:"DB_DSN"`
+	// MigrateOnStart menjalankan migrasi yang tertunda saat server start.
+	// Jika false, server menolak start selama masih ada migrasi tertunda.
+	MigrateOnStart bool `key:"migrate_on_start" env:"DB_MIGRATE_ON_START"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string      `key:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	MaxAge         time.Duration `key:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// JWTConfig mengatur masa berlaku token dan kunci penandatangan; lihat
+// jwtkeys.Options untuk format tiap kunci.
+type JWTConfig struct {
+	AccessTokenTTL       time.Duration `key:"access_token_ttl" env:"JWT_ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL      time.Duration `key:"refresh_token_ttl" env:"JWT_REFRESH_TOKEN_TTL"`
+	SigningKeyFile       string        `key:"signing_key_file" env:"JWT_SIGNING_KEY_FILE"`
+	SigningKeyID         string        `key:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	Secret               string        `key:"secret" env:"JWT_SECRET"`
+	SecretID             string        `key:"secret_id" env:"JWT_SECRET_ID"`
+	VerificationKeyFiles []string      `key:"verification_key_files" env:"JWT_VERIFICATION_KEY_FILES"`
+	PreviousSecrets      []string      `key:"previous_secrets" env:"JWT_PREVIOUS_SECRETS"`
+}
+
+type UploadsConfig struct {
+	// Dir relatif terhadap working directory dan sekaligus prefix URL file.
+	Dir         string `key:"dir" env:"UPLOAD_DIR"`
+	MaxFileSize int64  `key:"max_file_size" env:"UPLOAD_MAX_FILE_SIZE"` // byte
+	MaxFiles    int    `key:"max_files" env:"UPLOAD_MAX_FILES"`
+}
+
+type StorageConfig struct {
+	// Backend penyimpanan file upload. Saat ini hanya "local".
+	Backend string `key:"backend" env:"STORAGE_BACKEND"`
+}
+
+type MailConfig struct {
+	SMTPHost     string `key:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `key:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `key:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `key:"smtp_password" env:"SMTP_PASSWORD"`
+	From         string `key:"from" env:"MAIL_FROM"`
+}
+
+type PasswordConfig struct {
+	Hasher                string  `key:"hasher" env:"PASSWORD_HASHER"`
+	Argon2MemoryKiB       uint32  `key:"argon2_memory_kib" env:"PASSWORD_ARGON2_MEMORY_KIB"`
+	Argon2Iterations      uint32  `key:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS"`
+	Argon2Parallelism     uint8   `key:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM"`
+	BcryptCost            int     `key:"bcrypt_cost" env:"PASSWORD_BCRYPT_COST"`
+	MinLength             int     `key:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength             int     `key:"max_length" env:"PASSWORD_MAX_LENGTH"`
+	MinEntropy            float64 `key:"min_entropy" env:"PASSWORD_MIN_ENTROPY"`
+	BreachedPasswordsFile string  `key:"breached_passwords_file" env:"BREACHED_PASSWORDS_FILE"`
+}
+
+type SecurityConfig struct {
+	// LoginGuardStore: "memory" atau "database" (untuk beberapa instance).
+	LoginGuardStore          string   `key:"login_guard_store" env:"LOGIN_GUARD_STORE"`
+	AdminEmails              []string `key:"admin_emails" env:"ADMIN_EMAILS"`
+	AccountDeletionGraceDays int      `key:"account_deletion_grace_days" env:"ACCOUNT_DELETION_GRACE_DAYS"`
+}
+
+type FeaturesConfig struct {
+	Registration             bool `key:"registration" env:"FEATURE_REGISTRATION"`
+	MagicLinkLogin           bool `key:"magic_link_login" env:"FEATURE_MAGIC_LINK_LOGIN"`
+	RequireEmailVerification bool `key:"require_email_verification" env:"REQUIRE_EMAIL_VERIFICATION"`
+}
+
+// Default mengembalikan konfigurasi bawaan yang cocok untuk development.
+// DSN database tidak memiliki default dan harus selalu diisi.
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Port:        "8080",
+			BaseURL:     "http://localhost:8080",
+			FrontendURL: "https://feedsapp.vercel.app",
+		},
+		Database: DatabaseConfig{Driver: "mysql"},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"https://feedsapp.vercel.app"},
+			MaxAge:         12 * time.Hour,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Uploads: UploadsConfig{
+			Dir:         "public/uploads",
+			MaxFileSize: 10 << 20,
+			MaxFiles:    10,
+		},
+		Storage: StorageConfig{Backend: "local"},
+		Mail:    MailConfig{SMTPPort: 587},
+		Password: PasswordConfig{
+			Hasher:            "argon2id",
+			Argon2MemoryKiB:   19456,
+			Argon2Iterations:  2,
+			Argon2Parallelism: 1,
+			BcryptCost:        12,
+			MinLength:         8,
+			MaxLength:         128,
+			MinEntropy:        40,
+		},
+		Security: SecurityConfig{
+			LoginGuardStore:          "memory",
+			AccountDeletionGraceDays: 30,
+		},
+		Features: FeaturesConfig{
+			Registration:   true,
+			MagicLinkLogin: true,
+		},
+	}
+}
+
+// Load membaca konfigurasi dari Default, file CONFIG_FILE (jika diisi), dan
+// environment variable, lalu memvalidasinya.
+func Load() (*Config, error) {
+	cfg := Default()
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		if err := cfg.LoadFile(file); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.LoadEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile menimpa cfg dengan isi file YAML (.yaml, .yml) atau TOML (.toml).
+// Kunci yang tidak dikenal dianggap kesalahan agar salah ketik tidak
+// diabaikan diam-diam.
+func (cfg *Config) LoadFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("format file konfigurasi tidak didukung: %s", file)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if err := applyValues(reflect.ValueOf(cfg).Elem(), values, ""); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// LoadEnv menimpa cfg dengan environment variable yang tidak kosong. getenv
+// biasanya os.Getenv. Daftar ditulis dipisahkan koma dan durasi dengan
+// format time.ParseDuration, misalnya "15m" atau "720h".
+func (cfg *Config) LoadEnv(getenv func(string) string) error {
+	return applyEnv(reflect.ValueOf(cfg).Elem(), getenv)
+}
+
+func applyEnv(v reflect.Value, getenv func(string) string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(v.Field(i), getenv); err != nil {
+				return err
+			}
+			continue
+		}
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		if raw := getenv(name); raw != "" {
+			if err := setValue(v.Field(i), raw); err != nil {
+				return fmt.Errorf("%s tidak valid: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyValues(v reflect.Value, values map[string]interface{}, prefix string) error {
+	t := v.Type()
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		fields[t.Field(i).Tag.Get("key")] = i
+	}
+	for key, raw := range values {
+		i, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("kunci tidak dikenal: %s%s", prefix, key)
+		}
+		if raw == nil {
+			continue // kunci tanpa nilai di YAML, tetap memakai nilai sebelumnya
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			section, ok := raw.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s%s harus berupa section", prefix, key)
+			}
+			if err := applyValues(field, section, prefix+key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := setValue(field, raw); err != nil {
+			return fmt.Errorf("%s%s tidak valid: %w", prefix, key, err)
+		}
+	}
+	return nil
+}
+
+// setValue mengisi field dari nilai string (environment variable) atau nilai
+// hasil decode file. Semua skalar diubah ke string lebih dulu sehingga aturan
+// parsing sama untuk kedua sumber.
+func setValue(field reflect.Value, raw interface{}) error {
+	if field.Kind() == reflect.Slice {
+		var items []string
+		switch value := raw.(type) {
+		case []interface{}:
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			items = strings.Split(fmt.Sprint(value), ",")
+		}
+		list := []string{}
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	}
+
+	if _, ok := raw.([]interface{}); ok {
+		return errors.New("harus berupa nilai tunggal")
+	}
+	value := strings.TrimSpace(fmt.Sprint(raw))
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint8, reflect.Uint32:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("tipe %s tidak didukung", field.Type())
+	}
+	return nil
+}
+
+// Validate memeriksa seluruh konfigurasi dan mengembalikan semua kesalahan
+// sekaligus, sehingga aplikasi gagal start dengan pesan yang lengkap.
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(cfg.Env, EnvDevelopment, EnvStaging, EnvProduction),
+		"env harus development, staging, atau production: %q", cfg.Env)
+	check(cfg.Server.Port != "", "server.port wajib diisi")
+	if port, err := strconv.Atoi(cfg.Server.Port); err == nil {
+		check(port > 0 && port < 65536, "server.port tidak valid: %s", cfg.Server.Port)
+	} else {
+		check(false, "server.port tidak valid: %q", cfg.Server.Port)
+	}
+	check(isURL(cfg.Server.BaseURL), "server.base_url harus berupa URL http(s): %q", cfg.Server.BaseURL)
+	check(isURL(cfg.Server.FrontendURL), "server.frontend_url harus berupa URL http(s): %q", cfg.Server.FrontendURL)
+	check(cfg.Server.OIDCRedirectURL == "" || isURL(cfg.Server.OIDCRedirectURL),
+		"server.oidc_redirect_url harus berupa URL http(s): %q", cfg.Server.OIDCRedirectURL)
+
+	check(oneOf(cfg.Database.Driver, "mysql", "postgres", "sqlite"),
+		"database.driver harus mysql, postgres, atau sqlite: %q", cfg.Database.Driver)
+	check(cfg.Database.DSN != "", "database.dsn (DB_DSN) wajib diisi")
+
+	check(len(cfg.CORS.AllowedOrigins) > 0, "cors.allowed_origins wajib diisi")
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		// Request CORS memakai credentials, sehingga wildcard tidak diizinkan.
+		check(isURL(origin), "cors.allowed_origins harus berisi URL http(s), bukan %q", origin)
+	}
+	check(cfg.CORS.MaxAge >= 0, "cors.max_age tidak boleh negatif")
+
+	check(cfg.JWT.AccessTokenTTL > 0, "jwt.access_token_ttl harus lebih dari nol")
+	check(cfg.JWT.RefreshTokenTTL > cfg.JWT.AccessTokenTTL, "jwt.refresh_token_ttl harus lebih lama dari jwt.access_token_ttl")
+	check(cfg.JWT.SigningKeyFile == "" || cfg.JWT.Secret == "", "jwt.signing_key_file dan jwt.secret tidak boleh diisi bersamaan")
+	if cfg.Env != EnvDevelopment {
+		// Kunci sementara membuat semua token tidak berlaku setelah restart.
+		check(cfg.JWT.SigningKeyFile != "" || cfg.JWT.Secret != "",
+			"jwt.signing_key_file atau jwt.secret wajib diisi di luar development")
+	}
+
+	check(isRelativeDir(cfg.Uploads.Dir), "uploads.dir harus berupa path relatif tanpa \"..\": %q", cfg.Uploads.Dir)
+	check(cfg.Uploads.MaxFileSize > 0, "uploads.max_file_size harus lebih dari nol")
+	check(cfg.Uploads.MaxFiles > 0, "uploads.max_files harus lebih dari nol")
+	check(cfg.Storage.Backend == "local", "storage.backend tidak didukung: %q", cfg.Storage.Backend)
+
+	check(cfg.Mail.SMTPPort > 0 && cfg.Mail.SMTPPort < 65536, "mail.smtp_port tidak valid: %d", cfg.Mail.SMTPPort)
+	check(cfg.Mail.SMTPHost == "" || cfg.Mail.From != "", "mail.from wajib diisi jika mail.smtp_host diisi")
+
+	check(oneOf(cfg.Password.Hasher, "argon2id", "bcrypt"), "password.hasher harus argon2id atau bcrypt: %q", cfg.Password.Hasher)
+	check(cfg.Password.Argon2MemoryKiB >= 8*1024 && cfg.Password.Argon2MemoryKiB <= 4*1024*1024,
+		"password.argon2_memory_kib harus antara 8192 dan 4194304")
+	check(cfg.Password.Argon2Iterations >= 1 && cfg.Password.Argon2Iterations <= 100, "password.argon2_iterations harus antara 1 dan 100")
+	check(cfg.Password.Argon2Parallelism >= 1, "password.argon2_parallelism minimal 1")
+	check(cfg.Password.BcryptCost >= 4 && cfg.Password.BcryptCost <= 31, "password.bcrypt_cost harus antara 4 dan 31")
+	check(cfg.Password.MinLength >= 1, "password.min_length minimal 1")
+	check(cfg.Password.MaxLength >= cfg.Password.MinLength, "password.max_length lebih kecil dari password.min_length")
+	check(cfg.Password.MinEntropy >= 0, "password.min_entropy tidak boleh negatif")
+
+	check(oneOf(cfg.Security.LoginGuardStore, "memory", "database"),
+		"security.login_guard_store harus memory atau database: %q", cfg.Security.LoginGuardStore)
+	check(cfg.Security.AccountDeletionGraceDays >= 0, "security.account_deletion_grace_days tidak boleh negatif")
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, options ...string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+func isRelativeDir(dir string) bool {
+	if dir == "" || path.IsAbs(dir) || filepath.IsAbs(dir) {
+		return false
+	}
+	for _, part := range strings.Split(path.Clean(dir), "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
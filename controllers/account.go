@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"social-media-backend/config"
+	"social-media-backend/models"
+)
+
+const (
+	eventAccountDeactivated = "account_deactivated"
+	eventAccountReactivated = "account_reactivated"
+	eventAccountPurged      = "account_purged"
+)
+
+// AccountDeletionGracePeriod adalah lama akun yang dinonaktifkan masih dapat
+// dipulihkan dengan login kembali. Setelah itu data akun dihapus permanen
+// oleh PurgeDeactivatedAccounts. main mengisinya dari
+// ACCOUNT_DELETION_GRACE_DAYS.
+var AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+var errAccountDeleted = errors.New("akun sudah dihapus")
+
+// accountPurgePending bernilai true jika masa tenggang akun sudah habis dan
+// akun tinggal menunggu dihapus permanen.
+func accountPurgePending(user models.User) bool {
+	return user.DeactivatedAt != nil && time.Since(*user.DeactivatedAt) > AccountDeletionGracePeriod
+}
+
+// reactivateAccount memulihkan akun yang dinonaktifkan ketika user berhasil
+// login dalam masa tenggang.
+func reactivateAccount(c *gin.Context, user *models.User) error {
+	if user.DeactivatedAt == nil {
+		return nil
+	}
+	if accountPurgePending(*user) {
+		return errAccountDeleted
+	}
+	if err := config.DB.Model(user).Update("deactivated_at", nil).Error; err != nil {
+		return err
+	}
+	user.DeactivatedAt = nil
+	emitSecurityEvent(c, eventAccountReactivated, user.ID, nil)
+	return nil
+}
+
+// deactivateAccount menyembunyikan akun dan seluruh kontennya, serta
+// mencabut semua sesi dan API token milik user.
+func deactivateAccount(c *gin.Context, user models.User) error {
+	now := time.Now()
+	if err := config.DB.Model(&user).Update("deactivated_at", now).Error; err != nil {
+		return err
+	}
+	if _, err := revokeUserSessions(user.ID, 0); err != nil {
+		return err
+	}
+	if err := revokeUserAPITokens(user.ID); err != nil {
+		return err
+	}
+	emitSecurityEvent(c, eventAccountDeactivated, user.ID, map[string]interface{}{
+		"purge_after": now.Add(AccountDeletionGracePeriod),
+	})
+	return nil
+}
+
+// StartAccountPurger menjalankan PurgeDeactivatedAccounts secara berkala di
+// background.
+func StartAccountPurger(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if purged, err := PurgeDeactivatedAccounts(time.Now()); err != nil {
+				log.Println("Gagal menghapus akun yang dinonaktifkan:", err)
+			} else if purged > 0 {
+				log.Printf("%d akun yang dinonaktifkan telah dihapus permanen", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PurgeDeactivatedAccounts menghapus permanen akun yang masa tenggangnya
+// sudah habis beserta seluruh datanya dan file yang pernah diupload.
+func PurgeDeactivatedAccounts(now time.Time) (int, error) {
+	var users []models.User
+	if err := config.DB.Where("deactivated_at IS NOT NULL AND deactivated_at < ?", now.Add(-AccountDeletionGracePeriod)).
+		Find(&users).Error; err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, user := range users {
+		files, err := purgeUser(user)
+		if err != nil {
+			return purged, err
+		}
+		removeUploadedFiles(files)
+		emitSecurityEvent(nil, eventAccountPurged, user.ID, nil)
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeUser menghapus user dan semua data yang terkait dalam satu transaksi,
+// lalu mengembalikan daftar file upload yang perlu dihapus.
+func purgeUser(user models.User) ([]string, error) {
+	files := append(imageFiles(user.PhotoProfile, user.PhotoVariants), imageFiles(user.Banner, user.BannerVariants)...)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		feeds := tx.Unscoped().Model(&models.Feed{}).Select("id").Where("user_id = ?", user.ID)
+
+		var feedFiles, commentFiles, messageFiles []string
+		if err := tx.Unscoped().Model(&models.Feed{}).Where("user_id = ?", user.ID).
+			Pluck("file", &feedFiles).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Comment{}).Where("user_id = ? OR feed_id IN (?)", user.ID, feeds).
+			Pluck("file", &commentFiles).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Message{}).Where("user_id = ?", user.ID).
+			Pluck("file", &messageFiles).Error; err != nil {
+			return err
+		}
+		for _, list := range [][]string{feedFiles, commentFiles, messageFiles} {
+			for _, joined := range list {
+				files = append(files, strings.Split(joined, ",")...)
+			}
+		}
+
+		// Konten: komentar dan reaksi di feed milik user ikut dihapus.
+		if err := tx.Unscoped().Where("user_id = ? OR feed_id IN (?)", user.ID, feeds).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? OR feed_id IN (?)", user.ID, feeds).Delete(&models.Reaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Feed{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Message{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("follower_id = ? OR following_id = ?", user.ID, user.ID).Delete(&models.Follow{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("blocker_id = ? OR blocked_id = ?", user.ID, user.ID).Delete(&models.Block{}).Error; err != nil {
+			return err
+		}
+
+		// Chat: keluarkan user dari semua chatroom. Group chat yang dimiliki
+		// user diserahkan ke peserta lain, atau dihapus jika tidak ada.
+		if err := tx.Exec("DELETE FROM chatroom_users WHERE user_id = ?", user.ID).Error; err != nil {
+			return err
+		}
+		var ownedGroups []models.Chatroom
+		if err := tx.Where("owner_id = ? AND is_group = ?", user.ID, true).Find(&ownedGroups).Error; err != nil {
+			return err
+		}
+		for _, chatroom := range ownedGroups {
+			var members []uint
+			if err := tx.Raw("SELECT user_id FROM chatroom_users WHERE chatroom_id = ? ORDER BY user_id LIMIT 1", chatroom.ID).
+				Scan(&members).Error; err != nil {
+				return err
+			}
+			if len(members) > 0 {
+				if err := tx.Model(&chatroom).Update("owner_id", members[0]).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Unscoped().Where("chatroom_id = ?", chatroom.ID).Delete(&models.Message{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&chatroom).Error; err != nil {
+				return err
+			}
+		}
+
+		// Kredensial dan data autentikasi.
+		sessions := tx.Model(&models.Session{}).Select("id").Where("user_id = ?", user.ID)
+		if err := tx.Where("session_id IN (?)", sessions).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.Session{}, &models.UserToken{}, &models.RecoveryCode{},
+			&models.Identity{}, &models.APIToken{},
+		} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("attempt_key = ?", accountGuardKey(&user, "")).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		// Audit event tetap disimpan sebagai jejak keamanan, tetapi data
+		// pribadi di dalamnya (IP, user agent, detail) dikosongkan. Ditulis
+		// dengan raw SQL karena model AuditEvent menolak update.
+		if err := tx.Exec("UPDATE audit_events SET ip_address = '', user_agent = '', detail = '' WHERE user_id = ?", user.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&user).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// removeUploadedFiles menghapus file hasil upload user. Hanya file di dalam
+// UploadDir yang dihapus sehingga file default (foto profil bawaan) aman.
+func removeUploadedFiles(paths []string) {
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if !isUploadedFile(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Println("Gagal menghapus file:", path, err)
+		}
+	}
+}
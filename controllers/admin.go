@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"social-media-backend/config"
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/views"
+)
+
+const eventRoleChanged = "role_changed"
+
+var errLastAdmin = errors.New("tidak dapat menurunkan role admin terakhir")
+
+// RequirePermission menolak request dari user yang tidak memiliki permission
+// p. Harus dipasang setelah AuthMiddleware.
+func RequirePermission(p policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUserInterface, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+			c.Abort()
+			return
+		}
+		if !policy.Can(currentUserInterface.(models.User), p) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak akses untuk endpoint ini"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminGetUsers mengembalikan daftar user beserta role-nya, bisa difilter
+// dengan query ?role=.
+func AdminGetUsers(c *gin.Context) {
+	query := config.DB.Model(&models.User{})
+	if role := c.Query("role"); role != "" {
+		if role == policy.RoleUser {
+			query = query.Where("role = ? OR role = '' OR role IS NULL", role)
+		} else {
+			query = query.Where("role = ?", role)
+		}
+	}
+	var users []models.User
+	if err := query.Order("id asc").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": views.AdminUsers(users)})
+}
+
+// UpdateUserRole mengubah role user. Admin tidak dapat menurunkan role admin
+// terakhir agar aplikasi tidak kehilangan pengelola.
+func UpdateUserRole(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
+		return
+	}
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !policy.ValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid, gunakan salah satu dari: " + strings.Join(policy.Roles(), ", ")})
+		return
+	}
+
+	var target models.User
+	var previousRole string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, uint(userID)).Error; err != nil {
+			return err
+		}
+		previousRole = policy.RoleOf(target)
+		if previousRole == policy.RoleAdmin && input.Role != policy.RoleAdmin {
+			var admins int64
+			if err := tx.Model(&models.User{}).Where("role = ?", policy.RoleAdmin).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return errLastAdmin
+			}
+		}
+		return tx.Model(&target).Update("role", input.Role).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	case errors.Is(err, errLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	emitSecurityEvent(c, eventRoleChanged, target.ID, map[string]interface{}{
+		"previous_role": previousRole,
+		"role":          input.Role,
+		"changed_by":    currentUser.ID,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Role user berhasil diubah", "id": target.ID, "role": input.Role})
+}
+
+// BootstrapAdmins menjadikan user dengan email yang diberikan sebagai admin.
+// Dipanggil main saat startup (ADMIN_EMAILS) agar admin pertama bisa dibuat
+// tanpa akses langsung ke database.
+func BootstrapAdmins(emails []string) {
+	for _, email := range emails {
+		email = normalizeEmail(email)
+		if email == "" {
+			continue
+		}
+		var user models.User
+		if err := config.DB.Where("email = ?", email).First(&user).Error; err != nil {
+			log.Println("Admin tidak ditemukan:", email)
+			continue
+		}
+		previousRole := policy.RoleOf(user)
+		if previousRole == policy.RoleAdmin {
+			continue
+		}
+		if err := config.DB.Model(&user).Update("role", policy.RoleAdmin).Error; err != nil {
+			log.Println("Gagal menjadikan admin:", email, err)
+			continue
+		}
+		emitSecurityEvent(nil, eventRoleChanged, user.ID, map[string]interface{}{
+			"previous_role": previousRole,
+			"role":          policy.RoleAdmin,
+			"source":        "ADMIN_EMAILS",
+		})
+	}
+}
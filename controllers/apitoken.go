@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository"
+)
+
+const (
+	// apiTokenPrefix membedakan personal access token dari JWT pada header
+	// Authorization dan memudahkan pemindai kebocoran secret mengenalinya.
+	apiTokenPrefix        = "smb_"
+	apiTokenDisplayLength = 12
+	apiTokenMaxPerUser    = 50
+	apiTokenMaxTTLDays    = 365
+)
+
+// authenticateAPIToken memvalidasi personal access token dan mengembalikan
+// token beserta pemiliknya.
+func authenticateAPIToken(raw string) (models.APIToken, models.User, bool) {
+	var apiToken models.APIToken
+	if err := config.DB.Where("token_hash = ?", hashToken(raw)).First(&apiToken).Error; err != nil {
+		return apiToken, models.User{}, false
+	}
+	if apiToken.RevokedAt != nil || (apiToken.ExpiresAt != nil && time.Now().After(*apiToken.ExpiresAt)) {
+		return apiToken, models.User{}, false
+	}
+	var user models.User
+	if err := config.DB.Scopes(repository.ActiveUsers).First(&user, apiToken.UserID).Error; err != nil {
+		return apiToken, models.User{}, false
+	}
+	return apiToken, user, true
+}
+
+// touchAPIToken mencatat waktu terakhir token dipakai, paling sering sekali
+// per sessionTouchInterval.
+func touchAPIToken(apiToken models.APIToken) {
+	if apiToken.LastUsedAt != nil && time.Since(*apiToken.LastUsedAt) < sessionTouchInterval {
+		return
+	}
+	config.DB.Model(&models.APIToken{}).Where("id = ?", apiToken.ID).Update("last_used_at", time.Now())
+}
+
+// hasScope memeriksa apakah daftar scope (dipisahkan koma) memuat scope.
+func hasScope(scopes string, scope policy.Scope) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if policy.Scope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope membatasi akses personal access token ke route yang sesuai
+// scope-nya. Request yang memakai access token dari login tidak dibatasi.
+// Harus dipasang setelah AuthMiddleware.
+func RequireScope(scope policy.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiTokenInterface, isAPIToken := c.Get("api_token")
+		if !isAPIToken {
+			c.Next()
+			return
+		}
+		if !hasScope(apiTokenInterface.(models.APIToken).Scopes, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token tidak memiliki scope " + string(scope)})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSessionAuth menolak personal access token. Dipasang pada endpoint
+// sensitif (password, 2FA, sesi, pengelolaan token) yang hanya boleh diakses
+// dari sesi login.
+func RequireSessionAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIToken := c.Get("api_token"); isAPIToken {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Endpoint ini tidak dapat diakses dengan API token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func apiTokenResponse(apiToken models.APIToken) gin.H {
+	scopes := []string{}
+	if apiToken.Scopes != "" {
+		scopes = strings.Split(apiToken.Scopes, ",")
+	}
+	return gin.H{
+		"id":           apiToken.ID,
+		"name":         apiToken.Name,
+		"prefix":       apiToken.Prefix,
+		"scopes":       scopes,
+		"expires_at":   apiToken.ExpiresAt,
+		"last_used_at": apiToken.LastUsedAt,
+		"created_at":   apiToken.CreatedAt,
+	}
+}
+
+// GetAPITokens mengembalikan daftar API token aktif milik user. Nilai token
+// tidak pernah ditampilkan lagi setelah dibuat.
+func GetAPITokens(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+
+	var apiTokens []models.APIToken
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL", currentUser.ID).
+		Order("created_at desc").
+		Find(&apiTokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar token"})
+		return
+	}
+	result := make([]gin.H, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		result = append(result, apiTokenResponse(apiToken))
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": result})
+}
+
+// CreateAPIToken membuat personal access token baru dengan scope tertentu.
+func CreateAPIToken(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	var input struct {
+		Name   string   `json:"name" binding:"required,max=100"`
+		Scopes []string `json:"scopes" binding:"required,min=1"`
+		// Masa berlaku dalam hari; 0 berarti tidak kedaluwarsa.
+		ExpiresInDays int `json:"expires_in_days" binding:"min=0"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.ExpiresInDays > apiTokenMaxTTLDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Masa berlaku token maksimal " + strconv.Itoa(apiTokenMaxTTLDays) + " hari"})
+		return
+	}
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !policy.ValidScope(policy.Scope(scope)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scope tidak dikenal: " + scope})
+			return
+		}
+		if !hasScope(strings.Join(scopes, ","), policy.Scope(scope)) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var active int64
+	if err := config.DB.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", currentUser.ID).
+		Count(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if active >= apiTokenMaxPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah token sudah mencapai batas, hapus token yang tidak dipakai"})
+		return
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+		return
+	}
+	raw := apiTokenPrefix + secret
+	apiToken := models.APIToken{
+		UserID:    currentUser.ID,
+		Name:      input.Name,
+		Prefix:    raw[:apiTokenDisplayLength],
+		TokenHash: hashToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+	if err := config.DB.Create(&apiToken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+		return
+	}
+
+	emitSecurityEvent(c, eventAPITokenCreated, currentUser.ID, map[string]interface{}{
+		"token_id": apiToken.ID,
+		"name":     apiToken.Name,
+		"scopes":   scopes,
+	})
+	response := apiTokenResponse(apiToken)
+	response["token"] = raw
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Token berhasil dibuat, simpan sekarang karena tidak akan ditampilkan lagi",
+		"token":   response,
+	})
+}
+
+// RevokeAPIToken mencabut API token milik user.
+func RevokeAPIToken(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID token tidak valid"})
+		return
+	}
+	result := config.DB.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", uint(tokenID), currentUser.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token tidak ditemukan"})
+		return
+	}
+	emitSecurityEvent(c, eventAPITokenRevoked, currentUser.ID, map[string]interface{}{"token_id": uint(tokenID)})
+	c.JSON(http.StatusOK, gin.H{"message": "Token berhasil dicabut"})
+}
+
+// revokeUserAPITokens mencabut semua API token milik user.
+func revokeUserAPITokens(userID uint) error {
+	return config.DB.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
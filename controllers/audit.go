@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"social-media-backend/config"
+	"social-media-backend/models"
+)
+
+const (
+	auditEventsDefaultLimit = 50
+	auditEventsMaxLimit     = 200
+)
+
+// saveAuditEvent menyimpan security event ke tabel audit_events.
+func saveAuditEvent(event SecurityEvent) error {
+	if config.DB == nil {
+		return nil
+	}
+	detail := ""
+	if len(event.Detail) > 0 {
+		data, err := json.Marshal(event.Detail)
+		if err != nil {
+			return err
+		}
+		detail = string(data)
+	}
+	return config.DB.Create(&models.AuditEvent{
+		UserID:    event.UserID,
+		Type:      event.Type,
+		IPAddress: event.IPAddress,
+		UserAgent: truncate(event.UserAgent, 255),
+		Detail:    detail,
+		CreatedAt: event.At,
+	}).Error
+}
+
+func auditEventResponse(event models.AuditEvent) gin.H {
+	var detail interface{}
+	if event.Detail != "" {
+		json.Unmarshal([]byte(event.Detail), &detail)
+	}
+	return gin.H{
+		"id":         event.ID,
+		"type":       event.Type,
+		"user_id":    event.UserID,
+		"ip_address": event.IPAddress,
+		"user_agent": event.UserAgent,
+		"detail":     detail,
+		"created_at": event.CreatedAt,
+	}
+}
+
+// listAuditEvents menjalankan query audit event dengan paginasi berbasis
+// cursor: ?limit= dan ?before= (ID event terakhir dari halaman sebelumnya).
+func listAuditEvents(c *gin.Context, query *gorm.DB) {
+	limit := auditEventsDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid"})
+			return
+		}
+		limit = min(n, auditEventsMaxLimit)
+	}
+	if value := c.Query("before"); value != "" {
+		before, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter before tidak valid"})
+			return
+		}
+		query = query.Where("id < ?", before)
+	}
+	if eventType := c.Query("type"); eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+
+	var events []models.AuditEvent
+	if err := query.Order("id desc").Limit(limit).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil audit log"})
+		return
+	}
+	result := make([]gin.H, 0, len(events))
+	for _, event := range events {
+		result = append(result, auditEventResponse(event))
+	}
+	response := gin.H{"events": result, "next_before": nil}
+	if len(events) == limit {
+		response["next_before"] = events[len(events)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSecurityEvents mengembalikan riwayat kejadian keamanan akun user yang
+// sedang login, terbaru lebih dulu. Filter opsional: ?type=.
+func GetSecurityEvents(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	listAuditEvents(c, config.DB.Model(&models.AuditEvent{}).Where("user_id = ?", currentUser.ID))
+}
+
+// AdminGetSecurityEvents mengembalikan audit log seluruh user. Filter
+// opsional: ?user_id=, ?type=, ?ip=, ?from= dan ?to= (RFC 3339).
+func AdminGetSecurityEvents(c *gin.Context) {
+	query := config.DB.Model(&models.AuditEvent{})
+	if value := c.Query("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter user_id tidak valid"})
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	for _, item := range []struct {
+		param string
+		cond  string
+	}{{"from", "created_at >= ?"}, {"to", "created_at < ?"}} {
+		value := c.Query(item.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter " + item.param + " harus berformat RFC 3339"})
+			return
+		}
+		query = query.Where(item.cond, t)
+	}
+	listAuditEvents(c, query)
+}
@@ -1,25 +1,47 @@
 package controllers
 
 import (
-	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 
 	"social-media-backend/config"
+	"social-media-backend/jwtkeys"
 	"social-media-backend/models"
+	"social-media-backend/password"
+	"social-media-backend/repository"
 )
 
-var secretKey = []byte("RahasiaGaSih") // Ganti dengan secret key yang aman.
+// SigningKeys berisi kunci untuk menandatangani dan memverifikasi JWT. main
+// mengisinya dari jwtkeys.Load.
+var SigningKeys = jwtkeys.Ephemeral()
+
+// Passwords meng-hash dan memverifikasi password user. main mengisinya dari
+// password.NewManager.
+var Passwords = password.Default()
+
+// Masa berlaku access token dan refresh token. main mengisinya dari
+// konfigurasi JWT.
+var (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+const (
+	tokenTypeAccess = "access"
+
+	sessionTouchInterval = time.Minute
+)
 
 type RegisterInput struct {
 	Fullname     string `json:"fullname" binding:"required"`
 	Username     string `json:"username" binding:"required"`
 	Email        string `json:"email" binding:"required,email"`
-	Password     string `json:"password" binding:"required,min=6"`
+	Password     string `json:"password" binding:"required"` // divalidasi oleh PasswordPolicy
 	JenisKelamin string `json:"jenis_kelamin" binding:"required"`
 	TanggalLahir string `json:"tanggal_lahir" binding:"required"` // format YYYY-MM-DD
 }
@@ -30,13 +52,72 @@ type LoginInput struct {
 }
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+	return Passwords.Hash(password)
 }
 
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	ok, _ := Passwords.Verify(password, hash)
+	return ok
+}
+
+// rehashPassword menyimpan ulang hash password user dengan algoritma dan
+// parameter saat ini. Kegagalan hanya dicatat karena login tetap berhasil.
+func rehashPassword(user models.User, password string) {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		log.Println("Gagal meng-hash ulang password:", err)
+		return
+	}
+	// Hanya ganti jika password belum diubah oleh request lain.
+	if err := config.DB.Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashedPassword).Error; err != nil {
+		log.Println("Gagal menyimpan hash password baru:", err)
+	}
+}
+
+// signToken menandatangani klaim menjadi JWT dengan kunci aktif.
+func signToken(claims jwt.MapClaims) (string, error) {
+	return SigningKeys.Sign(claims)
+}
+
+// parseToken memverifikasi tanda tangan serta masa berlaku JWT dan
+// mengembalikan klaimnya.
+func parseToken(tokenString string) (jwt.MapClaims, error) {
+	return SigningKeys.Parse(tokenString)
+}
+
+// JWKS mempublikasikan kunci publik verifikasi JWT agar layanan lain dapat
+// memverifikasi token yang diterbitkan backend ini.
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, SigningKeys.JWKS())
+}
+
+// normalizeEmail menyimpan dan mencari email dalam huruf kecil. MySQL
+// membandingkan string tanpa membedakan huruf besar/kecil, sedangkan
+// PostgreSQL dan SQLite membedakannya; dengan normalisasi hasilnya sama di
+// semua driver dan index email tetap terpakai.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// findByLogin mencari user berdasarkan email atau username. Username juga
+// dibandingkan dengan LOWER() agar hasilnya sama di semua driver, seperti
+// pemeriksaan username di repository.
+func findByLogin(login string) (models.User, error) {
+	var user models.User
+	login = normalizeEmail(login)
+	err := config.DB.Where("email = ? OR LOWER(username) = ?", login, login).First(&user).Error
+	return user, err
+}
+
+// accountTaken memeriksa apakah username atau email sudah dipakai akun lain
+// (termasuk akun yang sudah dihapus). Username dibandingkan tanpa membedakan
+// huruf besar/kecil agar "Alice" dan "alice" tidak bisa terdaftar bersamaan
+// di driver mana pun. Nilai kosong diabaikan.
+func accountTaken(username, email string, exceptID uint) (bool, error) {
+	return repository.NewUserRepository(config.DB).Taken(username, email, exceptID)
 }
 
 func Register(c *gin.Context) {
@@ -45,6 +126,19 @@ func Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	input.Email = normalizeEmail(input.Email)
+	taken, err := accountTaken(input.Username, input.Email, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username atau email sudah terdaftar"})
+		return
+	}
+	if !validatePassword(c, input.Password, input.Username, input.Email) {
+		return
+	}
 
 	hashedPassword, err := HashPassword(input.Password)
 	if err != nil {
@@ -74,7 +168,13 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Registrasi berhasil"})
+	// Kegagalan mengirim email tidak menggagalkan registrasi; user masih bisa
+	// meminta email verifikasi ulang setelah login.
+	if err := sendVerificationEmail(user); err != nil {
+		log.Println("Gagal mengirim email verifikasi:", err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Registrasi berhasil, silakan cek email untuk verifikasi"})
 }
 
 func Login(c *gin.Context) {
@@ -83,27 +183,37 @@ func Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var user models.User
-	if err := config.DB.
-		Where("email = ? OR username = ?", input.Login, input.Login).
-		First(&user).Error; err != nil {
+	user, err := findByLogin(input.Login)
+	if err != nil {
+		accountKey := accountGuardKey(nil, input.Login)
+		if !checkLoginGuard(c, accountKey) {
+			return
+		}
+		recordLoginFailure(c, accountKey, 0)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login atau password salah"})
 		return
 	}
-	if !CheckPasswordHash(input.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login atau password salah"})
+	// Batas percobaan diperiksa sebelum verifikasi password agar percobaan berulang tidak
+	// bisa dipakai untuk menghabiskan CPU.
+	accountKey := accountGuardKey(&user, input.Login)
+	if !checkLoginGuard(c, accountKey) {
 		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	})
-	tokenString, err := token.SignedString(secretKey)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+	ok, needsRehash := Passwords.Verify(input.Password, user.Password)
+	if !ok {
+		recordLoginFailure(c, accountKey, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login atau password salah"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	// Hash lama (misalnya bcrypt) dimigrasikan ke hasher saat ini tanpa
+	// memaksa user mereset password.
+	if needsRehash {
+		rehashPassword(user, input.Password)
+	}
+	if !user.TOTPEnabled {
+		LoginGuard.Succeed(accountKey)
+	}
+	completeLogin(c, user)
 }
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -114,31 +224,51 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		tokenString := authHeader[len("Bearer "):]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("signing method tidak valid: %v", token.Header["alg"])
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		// Personal access token untuk script dan integrasi.
+		if strings.HasPrefix(tokenString, apiTokenPrefix) {
+			apiToken, user, ok := authenticateAPIToken(tokenString)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid"})
+				c.Abort()
+				return
 			}
-			return secretKey, nil
-		})
-		if err != nil || !token.Valid {
+			touchAPIToken(apiToken)
+			c.Set("user", user)
+			c.Set("api_token", apiToken)
+			c.Next()
+			return
+		}
+		claims, err := parseToken(tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid"})
 			c.Abort()
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		sessionID, ok := claims["sid"].(float64)
+		if !ok || claims["typ"] != tokenTypeAccess {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Klaim token tidak valid"})
 			c.Abort()
 			return
 		}
 		var user models.User
-		if err := config.DB.First(&user, claims["user_id"]).Error; err != nil {
+		if err := config.DB.Scopes(repository.ActiveUsers).First(&user, claims["user_id"]).Error; err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak ditemukan"})
 			c.Abort()
 			return
 		}
+		// Access token hanya berlaku selama sesinya belum dicabut (logout atau
+		// terdeteksi pemakaian ulang refresh token).
+		var session models.Session
+		if err := config.DB.First(&session, uint(sessionID)).Error; err != nil ||
+			session.UserID != user.ID || session.RevokedAt != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesi sudah berakhir, silakan login kembali"})
+			c.Abort()
+			return
+		}
+		touchSession(c, session)
 		c.Set("user", user)
+		c.Set("session_id", session.ID)
 		c.Next()
 	}
 };
\ No newline at end of file
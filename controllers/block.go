@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/models"
+	"social-media-backend/services"
+)
+
+// BlockHandler menangani endpoint blokir user.
+type BlockHandler struct {
+	blocks *services.BlockService
+}
+
+// NewBlockHandler membuat BlockHandler.
+func NewBlockHandler(blocks *services.BlockService) *BlockHandler {
+	return &BlockHandler{blocks: blocks}
+}
+
+// BlockUser memblokir user. User yang saling memblokir tidak muncul di
+// direktori user satu sama lain dan tidak dapat saling mengikuti.
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
+		return
+	}
+	if err := h.blocks.Block(currentUser, uint(targetID)); err != nil {
+		respondServiceError(c, err, "Gagal memblokir user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User berhasil diblokir"})
+}
+
+// UnblockUser membuka blokir user.
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
+		return
+	}
+	if err := h.blocks.Unblock(currentUser, uint(targetID)); err != nil {
+		respondServiceError(c, err, "Gagal membuka blokir user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Blokir user berhasil dibuka"})
+}
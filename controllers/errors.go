@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/services"
+)
+
+// serviceErrorStatus memetakan services.Code ke status HTTP.
+var serviceErrorStatus = map[services.Code]int{
+	services.CodeInvalid:   http.StatusBadRequest,
+	services.CodeNotFound:  http.StatusNotFound,
+	services.CodeForbidden: http.StatusForbidden,
+	services.CodeConflict:  http.StatusConflict,
+}
+
+// respondServiceError menulis respons untuk error dari package services.
+// Kesalahan bisnis ditampilkan apa adanya; kesalahan lain dicatat di log dan
+// client hanya menerima pesan fallback.
+func respondServiceError(c *gin.Context, err error, fallback string) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		if status, ok := serviceErrorStatus[serviceErr.Code]; ok {
+			c.JSON(status, gin.H{"error": serviceErr.Message})
+			return
+		}
+	}
+	log.Printf("%s: %v", fallback, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
@@ -3,28 +3,37 @@ package controllers
 import (
 	"net/http"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
-	"social-media-backend/config"
 	"social-media-backend/models"
+	"social-media-backend/services"
+	"social-media-backend/views"
 )
 
+// FeedHandler menangani endpoint feed, comment, dan reaksi.
+type FeedHandler struct {
+	feeds *services.FeedService
+}
+
+// NewFeedHandler membuat FeedHandler.
+func NewFeedHandler(feeds *services.FeedService) *FeedHandler {
+	return &FeedHandler{feeds: feeds}
+}
+
 // GetFeeds mengembalikan daftar feeds, beserta data user dan komentar.
-func GetFeeds(c *gin.Context) {
-	var feeds []models.Feed
-	// Preload User, Comments, dan Reactions agar data terkait ikut ter-fetch.
-	if err := config.DB.Preload("User").
-		Preload("Comments.User").
-		Preload("Reactions").
-		Order("created_at desc").
-		Find(&feeds).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (h *FeedHandler) GetFeeds(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	feeds, err := h.feeds.List(currentUser)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil feeds")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"feeds": feeds})
+	c.JSON(http.StatusOK, gin.H{"feeds": views.Feeds(feeds)})
 }
 
 // FeedInput digunakan untuk validasi data pembuatan dan update feed.
@@ -34,7 +43,7 @@ type FeedInput struct {
 }
 
 // CreateFeed memungkinkan user membuat feed baru.
-func CreateFeed(c *gin.Context) {
+func (h *FeedHandler) CreateFeed(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -56,39 +65,25 @@ func CreateFeed(c *gin.Context) {
 	// Ambil semua file dengan key "file"
 	form, err := c.MultipartForm()
 	if err == nil && form != nil {
-		files := form.File["file"]
-		for _, file := range files {
-			// Buat nama file unik
-			filename := time.Now().Format("20060102150405_") + file.Filename
-			savePath := "public/uploads/" + filename
-			if err := c.SaveUploadedFile(file, savePath); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
-				return
-			}
-			filePaths = append(filePaths, savePath)
+		filePaths, err = saveUploads(c, form.File["file"])
+		if err != nil {
+			respondUploadError(c, err)
+			return
 		}
 	}
 
-	// Gabungkan path file yang diupload menjadi string yang dipisahkan koma
-	filesString := strings.Join(filePaths, ",")
-
-	feed := models.Feed{
-		Feed:      input.Feed,
-		File:      filesString,
-		UserID:    currentUser.ID,
-		CreatedAt: time.Now(),
-	}
-
-	if err := config.DB.Create(&feed).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	feed, err := h.feeds.Create(currentUser, input.Feed, filePaths)
+	if err != nil {
+		removeUploadedFiles(filePaths)
+		respondServiceError(c, err, "Gagal membuat feed")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"feed": feed})
+	c.JSON(http.StatusCreated, gin.H{"feed": views.NewFeed(feed)})
 }
 
 // UpdateFeed memungkinkan pemilik feed untuk mengedit feed-nya, termasuk mengganti file/foto (multiple file)
-func UpdateFeed(c *gin.Context) {
+func (h *FeedHandler) UpdateFeed(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -103,15 +98,10 @@ func UpdateFeed(c *gin.Context) {
 		return
 	}
 
-	var feed models.Feed
-	if err := config.DB.First(&feed, uint(feedID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed tidak ditemukan"})
-		return
-	}
-
-	// Pastikan feed dimiliki oleh user yang sedang login.
-	if feed.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk mengubah feed ini"})
+	// Pastikan feed dimiliki oleh user yang sedang login sebelum file
+	// disimpan.
+	if _, err := h.feeds.Editable(currentUser, uint(feedID)); err != nil {
+		respondServiceError(c, err, "Gagal mengambil feed")
 		return
 	}
 
@@ -128,36 +118,27 @@ func UpdateFeed(c *gin.Context) {
 	var filePaths []string
 	form, err := c.MultipartForm()
 	if err == nil && form != nil {
-		files := form.File["file"]
-		for _, file := range files {
-			filename := time.Now().Format("20060102150405_") + file.Filename
-			savePath := "public/uploads/" + filename
-			if err := c.SaveUploadedFile(file, savePath); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
-				return
-			}
-			filePaths = append(filePaths, savePath)
+		filePaths, err = saveUploads(c, form.File["file"])
+		if err != nil {
+			respondUploadError(c, err)
+			return
 		}
 	}
 
-	// Jika ada file baru diupload, update field File, jika tidak, biarkan nilai lama
-	if len(filePaths) > 0 {
-		feed.File = strings.Join(filePaths, ",")
-	}
-
-	feed.Feed = input.Feed
-	feed.UpdatedAt = time.Now()
-
-	if err := config.DB.Save(&feed).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Jika ada file baru diupload, field File diganti, jika tidak, nilai lama
+	// dipertahankan.
+	feed, err := h.feeds.Update(currentUser, uint(feedID), input.Feed, filePaths)
+	if err != nil {
+		removeUploadedFiles(filePaths)
+		respondServiceError(c, err, "Gagal mengubah feed")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"feed": feed})
+	c.JSON(http.StatusOK, gin.H{"feed": views.NewFeed(feed)})
 }
 
-// DeleteFeed memungkinkan pemilik feed untuk menghapus feed-nya (soft delete)
-func DeleteFeed(c *gin.Context) {
+// DeleteFeed memungkinkan pemilik feed atau moderator untuk menghapus feed (soft delete)
+func (h *FeedHandler) DeleteFeed(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -172,19 +153,8 @@ func DeleteFeed(c *gin.Context) {
 		return
 	}
 
-	var feed models.Feed
-	if err := config.DB.First(&feed, uint(feedID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed tidak ditemukan"})
-		return
-	}
-
-	if feed.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk menghapus feed ini"})
-		return
-	}
-
-	if err := config.DB.Delete(&feed).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.feeds.Delete(currentUser, uint(feedID)); err != nil {
+		respondServiceError(c, err, "Gagal menghapus feed")
 		return
 	}
 
@@ -198,7 +168,7 @@ type CommentInput struct {
 }
 
 // CreateComment memungkinkan user menambahkan komentar pada feed.
-func CreateComment(c *gin.Context) {
+func (h *FeedHandler) CreateComment(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -213,42 +183,23 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
-	var feed models.Feed
-	if err := config.DB.First(&feed, uint(feedID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed tidak ditemukan"})
-		return
-	}
-
 	var input CommentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	comment := models.Comment{
-		Comment:   input.Comment,
-		File:      input.File,
-		FeedID:    feed.ID,
-		UserID:    currentUser.ID,
-		CreatedAt: time.Now(),
-	}
-
-	if err := config.DB.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Preload data User untuk memasukkan data user yang membuat komentar
-	if err := config.DB.Preload("User").First(&comment, comment.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	comment, err := h.feeds.CreateComment(currentUser, uint(feedID), input.Comment, input.File)
+	if err != nil {
+		respondServiceError(c, err, "Gagal membuat comment")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"comment": comment})
+	c.JSON(http.StatusCreated, gin.H{"comment": views.NewComment(comment)})
 }
 
 // UpdateComment memungkinkan pengirim comment untuk mengedit komentarnya.
-func UpdateComment(c *gin.Context) {
+func (h *FeedHandler) UpdateComment(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -263,18 +214,6 @@ func UpdateComment(c *gin.Context) {
 		return
 	}
 
-	var comment models.Comment
-	if err := config.DB.First(&comment, uint(commentID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment tidak ditemukan"})
-		return
-	}
-
-	// Hanya pengirim komentar yang dapat mengedit komentarnya.
-	if comment.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk mengedit comment ini"})
-		return
-	}
-
 	var input struct {
 		Comment string `json:"comment" binding:"required"`
 	}
@@ -283,19 +222,17 @@ func UpdateComment(c *gin.Context) {
 		return
 	}
 
-	comment.Comment = input.Comment
-	comment.UpdatedAt = time.Now()
-
-	if err := config.DB.Save(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	comment, err := h.feeds.UpdateComment(currentUser, uint(commentID), input.Comment)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengubah comment")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Comment berhasil diupdate", "comment": comment})
+	c.JSON(http.StatusOK, gin.H{"message": "Comment berhasil diupdate", "comment": views.NewComment(comment)})
 }
 
 // DeleteComment memungkinkan user menghapus comment yang dibuatnya.
-func DeleteComment(c *gin.Context) {
+func (h *FeedHandler) DeleteComment(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -310,88 +247,39 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	var comment models.Comment
-	if err := config.DB.First(&comment, uint(commentID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment tidak ditemukan"})
-		return
-	}
-
-	// Hanya pemilik comment yang dapat menghapusnya.
-	if comment.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki hak untuk menghapus comment ini"})
-		return
-	}
-
-	if err := config.DB.Delete(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.feeds.DeleteComment(currentUser, uint(commentID)); err != nil {
+		respondServiceError(c, err, "Gagal menghapus comment")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Comment berhasil dihapus"})
 }
 
-// LikeFeed memungkinkan user memberikan reaksi "like" pada feed.
-func LikeFeed(c *gin.Context) {
-	currentUserInterface, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
-		return
-	}
-	currentUser := currentUserInterface.(models.User)
-
-	feedIDStr := c.Param("feed_id")
-	feedID, err := strconv.ParseUint(feedIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID feed tidak valid"})
-		return
-	}
-
-	var feed models.Feed
-	if err := config.DB.First(&feed, uint(feedID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed tidak ditemukan"})
-		return
-	}
-
-	var reaction models.Reaction
-	err = config.DB.Where("feed_id = ? AND user_id = ?", feed.ID, currentUser.ID).First(&reaction).Error
-	if err == nil {
-		// Jika sudah ada reaksi, periksa tipe reaksi.
-		if reaction.Reaction == "like" {
-			if err := config.DB.Delete(&reaction).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus like"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "Like dihapus"})
-			return
-		} else {
-			reaction.Reaction = "like"
-			if err := config.DB.Save(&reaction).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate reaksi"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "Diupdate menjadi like"})
-			return
-		}
-	} else if err != gorm.ErrRecordNotFound {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+// reactionMessages adalah pesan respons untuk setiap hasil React.
+var reactionMessages = map[string]map[services.ReactionResult]string{
+	services.ReactionLike: {
+		services.ReactionAdded:   "Feed dilike",
+		services.ReactionRemoved: "Like dihapus",
+		services.ReactionChanged: "Diupdate menjadi like",
+	},
+	services.ReactionDislike: {
+		services.ReactionAdded:   "Feed didislike",
+		services.ReactionRemoved: "Dislike dihapus",
+		services.ReactionChanged: "Diupdate menjadi dislike",
+	},
+}
 
-	newReaction := models.Reaction{
-		FeedID:    feed.ID,
-		UserID:    currentUser.ID,
-		Reaction:  "like",
-		CreatedAt: time.Now(),
-	}
-	if err := config.DB.Create(&newReaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"message": "Feed dilike"})
+// LikeFeed memungkinkan user memberikan reaksi "like" pada feed.
+func (h *FeedHandler) LikeFeed(c *gin.Context) {
+	h.react(c, services.ReactionLike)
 }
 
 // DislikeFeed memungkinkan user memberikan reaksi "dislike" pada feed.
-func DislikeFeed(c *gin.Context) {
+func (h *FeedHandler) DislikeFeed(c *gin.Context) {
+	h.react(c, services.ReactionDislike)
+}
+
+func (h *FeedHandler) react(c *gin.Context, kind string) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -406,45 +294,10 @@ func DislikeFeed(c *gin.Context) {
 		return
 	}
 
-	var feed models.Feed
-	if err := config.DB.First(&feed, uint(feedID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed tidak ditemukan"})
-		return
-	}
-
-	var reaction models.Reaction
-	err = config.DB.Where("feed_id = ? AND user_id = ?", feed.ID, currentUser.ID).First(&reaction).Error
-	if err == nil {
-		if reaction.Reaction == "dislike" {
-			if err := config.DB.Delete(&reaction).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus dislike"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "Dislike dihapus"})
-			return
-		} else {
-			reaction.Reaction = "dislike"
-			if err := config.DB.Save(&reaction).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate reaksi"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "Diupdate menjadi dislike"})
-			return
-		}
-	} else if err != gorm.ErrRecordNotFound {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	newReaction := models.Reaction{
-		FeedID:    feed.ID,
-		UserID:    currentUser.ID,
-		Reaction:  "dislike",
-		CreatedAt: time.Now(),
-	}
-	if err := config.DB.Create(&newReaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := h.feeds.React(currentUser, uint(feedID), kind)
+	if err != nil {
+		respondServiceError(c, err, "Gagal menyimpan reaksi")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Feed didislike"})
+	c.JSON(http.StatusOK, gin.H{"message": reactionMessages[kind][result]})
 }
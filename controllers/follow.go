@@ -5,11 +5,22 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"social-media-backend/config"
 	"social-media-backend/models"
+	"social-media-backend/services"
+	"social-media-backend/views"
 )
 
-func FollowUser(c *gin.Context) {
+// FollowHandler menangani endpoint follow.
+type FollowHandler struct {
+	follows *services.FollowService
+}
+
+// NewFollowHandler membuat FollowHandler.
+func NewFollowHandler(follows *services.FollowService) *FollowHandler {
+	return &FollowHandler{follows: follows}
+}
+
+func (h *FollowHandler) FollowUser(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -22,25 +33,14 @@ func FollowUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
 		return
 	}
-	var targetUser models.User
-	if err := config.DB.First(&targetUser, uint(targetID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
-		return
-	}
-	var followingList []models.User
-	config.DB.Model(&currentUser).Association("Following").Find(&followingList, "id = ?", targetUser.ID)
-	if len(followingList) > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Anda sudah mengikuti user ini"})
-		return
-	}
-	if err := config.DB.Model(&currentUser).Association("Following").Append(&targetUser); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengikuti user"})
+	if err := h.follows.Follow(currentUser, uint(targetID)); err != nil {
+		respondServiceError(c, err, "Gagal mengikuti user")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Berhasil mengikuti user"})
 }
 
-func UnfollowUser(c *gin.Context) {
+func (h *FollowHandler) UnfollowUser(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -53,44 +53,39 @@ func UnfollowUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
 		return
 	}
-	var targetUser models.User
-	if err := config.DB.First(&targetUser, uint(targetID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
-		return
-	}
-	if err := config.DB.Model(&currentUser).Association("Following").Delete(&targetUser); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal berhenti mengikuti user"})
+	if err := h.follows.Unfollow(currentUser, uint(targetID)); err != nil {
+		respondServiceError(c, err, "Gagal berhenti mengikuti user")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Berhasil berhenti mengikuti user"})
 }
 
-func GetFollowers(c *gin.Context) {
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
 		return
 	}
 	currentUser := currentUserInterface.(models.User)
-	var followers []models.User
-	if err := config.DB.Model(&currentUser).Association("Followers").Find(&followers); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar followers"})
+	followers, err := h.follows.Followers(currentUser)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil daftar followers")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"followers": followers})
+	c.JSON(http.StatusOK, gin.H{"followers": views.UserSummaries(followers)})
 }
 
-func GetFollowing(c *gin.Context) {
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
 		return
 	}
 	currentUser := currentUserInterface.(models.User)
-	var following []models.User
-	if err := config.DB.Model(&currentUser).Association("Following").Find(&following); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar following"})
+	following, err := h.follows.Following(currentUser)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil daftar following")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"following": following})
+	c.JSON(http.StatusOK, gin.H{"following": views.UserSummaries(following)})
 };
\ No newline at end of file
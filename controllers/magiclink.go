@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/mailer"
+	"social-media-backend/models"
+)
+
+const (
+	purposeMagicLogin = "magic_login"
+
+	magicLoginTTL = 15 * time.Minute
+	// Jarak minimum antar email magic link untuk akun yang sama.
+	magicLoginResendInterval = time.Minute
+)
+
+// magicLinkMessage selalu dikembalikan oleh RequestMagicLink agar respons
+// tidak membocorkan apakah sebuah email terdaftar.
+const magicLinkMessage = "Jika email terdaftar, tautan login telah dikirim ke email"
+
+// RequestMagicLink mengirim tautan login sekali pakai ke email user.
+func RequestMagicLink(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Seperti ForgotPassword, seluruh pekerjaan dijalankan di background agar
+	// respons dan waktunya sama untuk email terdaftar maupun tidak.
+	runInBackground(func() { sendMagicLink(input.Email) })
+
+	c.JSON(http.StatusOK, gin.H{"message": magicLinkMessage})
+}
+
+// sendMagicLink mengirim tautan login ke akun dengan email tersebut, kecuali
+// tautan terakhir belum lewat magicLoginResendInterval.
+func sendMagicLink(email string) {
+	var user models.User
+	if err := config.DB.Where("email = ?", normalizeEmail(email)).First(&user).Error; err != nil || accountPurgePending(user) {
+		return
+	}
+
+	resendMu.Lock()
+	var last models.UserToken
+	if err := config.DB.Where("user_id = ? AND purpose = ?", user.ID, purposeMagicLogin).
+		Order("created_at desc").First(&last).Error; err == nil &&
+		time.Since(last.CreatedAt) < magicLoginResendInterval {
+		resendMu.Unlock()
+		return
+	}
+	token, err := issueUserToken(user, purposeMagicLogin, magicLoginTTL)
+	resendMu.Unlock()
+	if err != nil {
+		log.Println("Gagal membuat token magic link:", err)
+		return
+	}
+	link := FrontendURL + "/magic-login?token=" + url.QueryEscape(token)
+	if err := Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Tautan login",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik tautan berikut untuk masuk ke akun kamu tanpa password:\n%s\n\nTautan ini berlaku selama 15 menit dan hanya dapat digunakan sekali. Abaikan email ini jika kamu tidak merasa memintanya.",
+			user.Fullname, link),
+	}); err != nil {
+		log.Println("Gagal mengirim email magic link:", err)
+	}
+}
+
+// ConsumeMagicLink menukar token dari tautan login dengan token yang sama
+// seperti Login. Batas percobaan, status akun, dan 2FA diperlakukan sama
+// dengan login menggunakan password.
+func ConsumeMagicLink(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Token yang tidak dikenal tidak terkait akun mana pun, sehingga hanya
+	// dihitung untuk IP.
+	if !checkLoginGuard(c, "") {
+		return
+	}
+	pending, err := findUserToken(input.Token, purposeMagicLogin)
+	if err != nil {
+		recordLoginFailure(c, "", 0)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tautan login tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, pending.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tautan login tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	accountKey := accountGuardKey(&user, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	// Token hanya berlaku untuk alamat email saat token dibuat.
+	userToken, err := consumeUserToken(input.Token, purposeMagicLogin)
+	if err != nil || user.Email != userToken.Email {
+		recordLoginFailure(c, accountKey, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tautan login tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	// Membuka tautan dari email sekaligus membuktikan kepemilikan email.
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := config.DB.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		user.EmailVerifiedAt = &now
+	}
+	if !user.TOTPEnabled {
+		LoginGuard.Succeed(accountKey)
+	}
+	completeLogin(c, user)
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/models"
+	"social-media-backend/totp"
+)
+
+const (
+	tokenTypeMFA = "mfa"
+
+	mfaChallengeTTL   = 5 * time.Minute
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+// TOTPIssuer adalah nama aplikasi yang ditampilkan di aplikasi authenticator.
+var TOTPIssuer = "FeedsApp"
+
+// loginResponse membuat respons login untuk user yang sudah lolos pemeriksaan
+// faktor pertama. Jika 2FA aktif, yang dikembalikan adalah challenge token
+// berumur pendek yang harus ditukar melalui LoginMFA, bukan access token.
+func loginResponse(c *gin.Context, user models.User) (gin.H, error) {
+	if accountPurgePending(user) {
+		return nil, errAccountDeleted
+	}
+	if user.TOTPEnabled {
+		mfaToken, err := signToken(jwt.MapClaims{
+			"user_id": user.ID,
+			"typ":     tokenTypeMFA,
+			"exp":     time.Now().Add(mfaChallengeTTL).Unix(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(mfaChallengeTTL.Seconds()),
+		}, nil
+	}
+	return createSession(c, user)
+}
+
+// completeLogin menulis respons loginResponse sebagai JSON.
+func completeLogin(c *gin.Context, user models.User) {
+	response, err := loginResponse(c, user)
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// loginError menulis respons untuk error dari loginResponse atau createSession.
+func loginError(c *gin.Context, err error) {
+	if errors.Is(err, errAccountDeleted) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Akun sudah dihapus"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+}
+
+// verifyTOTP memvalidasi kode TOTP user dan menolak pemakaian ulang kode yang
+// sudah pernah berhasil dipakai.
+func verifyTOTP(user models.User, code string) bool {
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return false
+	}
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// useRecoveryCode menandai recovery code sebagai terpakai jika cocok.
+func useRecoveryCode(user models.User, code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// verifySecondFactor menerima kode TOTP atau recovery code.
+func verifySecondFactor(user models.User, code, recoveryCode string) bool {
+	if code != "" {
+		return verifyTOTP(user, code)
+	}
+	if recoveryCode != "" {
+		return useRecoveryCode(user, recoveryCode)
+	}
+	return false
+}
+
+// generateRecoveryCodes mengganti semua recovery code user dengan kode baru
+// dan mengembalikan kode dalam bentuk teks (hanya ditampilkan sekali).
+func generateRecoveryCodes(user models.User) ([]string, error) {
+	if err := config.DB.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))
+		code := raw[:5] + "-" + raw[5:10]
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{UserID: user.ID, CodeHash: hashToken(code)})
+	}
+	if err := config.DB.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// LoginMFA menyelesaikan login dua langkah: menukar challenge token dari Login
+// dan kode TOTP (atau recovery code) dengan access token dan refresh token.
+func LoginMFA(c *gin.Context) {
+	var input struct {
+		MFAToken     string `json:"mfa_token" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	claims, err := parseToken(input.MFAToken)
+	if err != nil || claims["typ"] != tokenTypeMFA {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token MFA tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, claims["user_id"]).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token MFA tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	accountKey := accountGuardKey(&user, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	if !verifySecondFactor(user, input.Code, input.RecoveryCode) {
+		recordLoginFailure(c, accountKey, user.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode autentikasi salah"})
+		return
+	}
+	LoginGuard.Succeed(accountKey)
+	tokens, err := createSession(c, user)
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// EnrollTOTP membuat secret TOTP baru untuk user setelah password dikonfirmasi.
+// Secret yang belum dikonfirmasi ikut diganti. 2FA belum aktif sampai
+// dikonfirmasi dengan ConfirmTOTP.
+func EnrollTOTP(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if currentUser.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return
+	}
+	accountKey := accountGuardKey(&currentUser, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	if !CheckPasswordHash(input.Password, currentUser.Password) {
+		recordLoginFailure(c, accountKey, currentUser.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password salah"})
+		return
+	}
+	LoginGuard.Succeed(accountKey)
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat secret 2FA"})
+		return
+	}
+	if err := config.DB.Model(&currentUser).Update("totp_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": totp.URI(TOTPIssuer, currentUser.Email, secret),
+	})
+}
+
+// ConfirmTOTP mengaktifkan 2FA setelah user membuktikan bahwa authenticator
+// sudah tersetel, lalu mengembalikan recovery code.
+func ConfirmTOTP(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if currentUser.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return
+	}
+	if currentUser.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Lakukan enrollment 2FA terlebih dahulu"})
+		return
+	}
+	accountKey := accountGuardKey(&currentUser, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	if !verifyTOTP(currentUser, input.Code) {
+		recordLoginFailure(c, accountKey, currentUser.ID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kode autentikasi salah"})
+		return
+	}
+	LoginGuard.Succeed(accountKey)
+	if err := config.DB.Model(&currentUser).Update("totp_enabled", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := generateRecoveryCodes(currentUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat recovery code"})
+		return
+	}
+	emitSecurityEvent(c, eventTOTPEnabled, currentUser.ID, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "2FA berhasil diaktifkan", "recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes membuat recovery code baru dan membatalkan yang lama.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !currentUser.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA belum aktif"})
+		return
+	}
+	accountKey := accountGuardKey(&currentUser, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	if !verifyTOTP(currentUser, input.Code) {
+		recordLoginFailure(c, accountKey, currentUser.ID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kode autentikasi salah"})
+		return
+	}
+	LoginGuard.Succeed(accountKey)
+	codes, err := generateRecoveryCodes(currentUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat recovery code"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableTOTP menonaktifkan 2FA. User harus memasukkan password serta kode
+// TOTP atau recovery code.
+func DisableTOTP(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	var input struct {
+		Password     string `json:"password" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !currentUser.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA belum aktif"})
+		return
+	}
+	accountKey := accountGuardKey(&currentUser, "")
+	if !checkLoginGuard(c, accountKey) {
+		return
+	}
+	if !CheckPasswordHash(input.Password, currentUser.Password) {
+		recordLoginFailure(c, accountKey, currentUser.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password salah"})
+		return
+	}
+	if !verifySecondFactor(currentUser, input.Code, input.RecoveryCode) {
+		recordLoginFailure(c, accountKey, currentUser.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode autentikasi salah"})
+		return
+	}
+	LoginGuard.Succeed(accountKey)
+	if err := config.DB.Model(&currentUser).Updates(map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := config.DB.Where("user_id = ?", currentUser.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	emitSecurityEvent(c, eventTOTPDisabled, currentUser.ID, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "2FA berhasil dinonaktifkan"})
+}
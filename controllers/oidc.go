@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"social-media-backend/config"
+	"social-media-backend/models"
+	"social-media-backend/oidc"
+)
+
+const (
+	oauthStateTTL     = 10 * time.Minute
+	maxUsernameLength = 30
+)
+
+// OIDCProviders berisi provider login eksternal yang aktif, diisi oleh main
+// dari oidc.ProvidersFromEnv.
+var OIDCProviders = map[string]*oidc.Provider{}
+
+// OIDCRedirectURL adalah halaman frontend tujuan setelah login eksternal
+// selesai. Hasil login dikirim di fragment URL (#token=...). Jika kosong,
+// callback mengembalikan JSON seperti Login.
+var OIDCRedirectURL = ""
+
+var (
+	errOIDCEmailRequired = errors.New("provider tidak memberikan alamat email")
+	errOIDCEmailConflict = errors.New("email sudah terdaftar dan tidak dapat ditautkan otomatis")
+
+	usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.]+`)
+)
+
+// OIDCLogin mengarahkan user ke halaman login provider eksternal.
+func OIDCLogin(c *gin.Context) {
+	provider, ok := OIDCProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider login tidak ditemukan"})
+		return
+	}
+	if err := provider.Discover(c.Request.Context()); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Provider login sedang tidak dapat dihubungi"})
+		return
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login"})
+		return
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login"})
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login"})
+		return
+	}
+
+	oauthState := models.OAuthState{
+		State:        state,
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+	if err := config.DB.Create(&oauthState).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login"})
+		return
+	}
+	// Bersihkan state kedaluwarsa yang tidak pernah diselesaikan.
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, nonce, challenge))
+}
+
+// OIDCCallback menerima redirect dari provider, menukar authorization code,
+// memverifikasi identitas, lalu login (atau mendaftarkan) user terkait dan
+// menerbitkan token yang sama seperti Login.
+func OIDCCallback(c *gin.Context) {
+	provider, ok := OIDCProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Provider login tidak ditemukan"})
+		return
+	}
+	if errParam := c.Query("error"); errParam != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login dibatalkan oleh provider: " + errParam})
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter code dan state diperlukan"})
+		return
+	}
+
+	// State hanya dapat dipakai sekali: hapus dan pastikan memang ada yang terhapus.
+	var oauthState models.OAuthState
+	if err := config.DB.Where("state = ? AND provider = ?", state, provider.Name).First(&oauthState).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "State login tidak valid"})
+		return
+	}
+	if result := config.DB.Delete(&oauthState); result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "State login tidak valid"})
+		return
+	}
+	if time.Now().After(oauthState.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sesi login sudah kedaluwarsa, silakan ulangi"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := provider.Discover(ctx); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Provider login sedang tidak dapat dihubungi"})
+		return
+	}
+	tokenResponse, err := provider.Exchange(ctx, code, oauthState.CodeVerifier)
+	if err != nil {
+		log.Println("OIDC exchange gagal:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Gagal menukar authorization code"})
+		return
+	}
+
+	var claims *oidc.Claims
+	if tokenResponse.IDToken != "" {
+		claims, err = provider.VerifyIDToken(ctx, tokenResponse.IDToken, oauthState.Nonce)
+	} else {
+		claims, err = provider.UserInfo(ctx, tokenResponse.AccessToken)
+	}
+	if err != nil {
+		log.Println("OIDC verifikasi identitas gagal:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identitas dari provider tidak valid"})
+		return
+	}
+	// Sebagian provider tidak menyertakan email di ID token.
+	if claims.Email == "" && provider.UserInfoURL != "" {
+		if info, err := provider.UserInfo(ctx, tokenResponse.AccessToken); err == nil && info.Subject == claims.Subject {
+			claims.Email, claims.EmailVerified = info.Email, info.EmailVerified
+		}
+	}
+
+	user, err := findOrCreateOIDCUser(provider.Name, claims)
+	if errors.Is(err, errOIDCEmailRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Akun provider tidak memiliki email, izinkan akses email lalu coba lagi"})
+		return
+	}
+	if errors.Is(err, errOIDCEmailConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email sudah terdaftar pada akun lain, silakan login ke akun tersebut"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := loginResponse(c, user)
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	if OIDCRedirectURL == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	fragment := url.Values{}
+	for key, value := range response {
+		fragment.Set(key, fmt.Sprint(value))
+	}
+	c.Redirect(http.StatusFound, OIDCRedirectURL+"#"+fragment.Encode())
+}
+
+// findOrCreateOIDCUser mencari user yang tertaut dengan identitas eksternal.
+// Jika belum ada, identitas ditautkan ke akun dengan email yang sama atau akun
+// baru dibuat. Penautan otomatis hanya dilakukan bila email diverifikasi oleh
+// provider maupun oleh kita dan akun tersebut belum memiliki password;
+// selain itu pemilik akun harus login dengan password.
+func findOrCreateOIDCUser(provider string, claims *oidc.Claims) (models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.Identity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.First(&user, identity.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if claims.Email == "" {
+			return errOIDCEmailRequired
+		}
+		err = tx.Where("email = ?", normalizeEmail(claims.Email)).First(&user).Error
+		switch {
+		case err == nil:
+			if !claims.EmailVerified || user.EmailVerifiedAt == nil || user.Password != "" {
+				return errOIDCEmailConflict
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			username, err := uniqueUsername(tx, usernameCandidate(claims))
+			if err != nil {
+				return err
+			}
+			fullname := claims.Name
+			if fullname == "" {
+				fullname = username
+			}
+			user = models.User{
+				Fullname:     fullname,
+				Username:     username,
+				Email:        normalizeEmail(claims.Email),
+				PhotoProfile: "public/default/images/user.png",
+			}
+			if claims.EmailVerified {
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.Identity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}).Error
+	})
+	return user, err
+}
+
+// usernameCandidate memilih bahan username dari klaim provider.
+func usernameCandidate(claims *oidc.Claims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" {
+		candidate = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	candidate = usernameInvalidChars.ReplaceAllString(strings.ToLower(candidate), "")
+	if len(candidate) > maxUsernameLength-4 {
+		candidate = candidate[:maxUsernameLength-4]
+	}
+	if candidate == "" {
+		candidate = "user"
+	}
+	return candidate
+}
+
+// uniqueUsername menambahkan angka di belakang base sampai didapat username
+// yang belum dipakai, termasuk oleh akun yang sudah dihapus.
+func uniqueUsername(tx *gorm.DB, base string) (string, error) {
+	for i := 0; i < 100; i++ {
+		candidate := base
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", base, i+1)
+		}
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("LOWER(username) = ?", strings.ToLower(candidate)).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+	suffix, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + usernameInvalidChars.ReplaceAllString(strings.ToLower(suffix[:6]), ""), nil
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/services"
+)
+
+// pageParams membaca ?cursor= dan ?limit= untuk daftar yang memakai
+// services.PageParams. Jika limit tidak valid, respons 400 sudah ditulis dan
+// ok bernilai false.
+func pageParams(c *gin.Context) (params services.PageParams, ok bool) {
+	params.Cursor = c.Query("cursor")
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid"})
+			return params, false
+		}
+		params.Limit = n
+	}
+	return params, true
+}
+
+// nextCursor mengubah cursor kosong menjadi null di respons JSON.
+func nextCursor(cursor string) interface{} {
+	if cursor == "" {
+		return nil
+	}
+	return cursor
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/mailer"
+	"social-media-backend/models"
+	"social-media-backend/password"
+)
+
+const (
+	purposeResetPassword = "reset_password"
+
+	resetPasswordTTL = time.Hour
+	// Jarak minimum antar email reset password untuk akun yang sama.
+	resetPasswordResendInterval = time.Minute
+)
+
+// FrontendURL adalah URL aplikasi frontend, dipakai untuk tautan di email yang
+// harus dibuka di frontend (misalnya halaman reset password).
+var FrontendURL = "https://feedsapp.vercel.app"
+
+// PasswordPolicy dipakai untuk memvalidasi password baru saat registrasi,
+// ganti password, dan reset password. main mengisinya dari
+// password.NewPolicy.
+var PasswordPolicy = password.DefaultPolicy()
+
+// forgotPasswordMessage selalu dikembalikan oleh ForgotPassword agar respons
+// tidak membocorkan apakah sebuah akun terdaftar.
+const forgotPasswordMessage = "Jika akun terdaftar, tautan reset password telah dikirim ke email"
+
+// ForgotPassword mengirim tautan reset password ke email akun yang cocok
+// dengan email atau username yang diberikan.
+func ForgotPassword(c *gin.Context) {
+	var input struct {
+		Login string `json:"login" binding:"required"` // bisa email atau username
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Pencarian akun, pembuatan token, dan pengiriman email dijalankan di
+	// background sehingga respons dan waktunya sama untuk akun yang terdaftar
+	// maupun tidak.
+	runInBackground(func() { sendPasswordReset(input.Login) })
+
+	c.JSON(http.StatusOK, gin.H{"message": forgotPasswordMessage})
+}
+
+// sendPasswordReset mengirim email reset password ke akun yang cocok dengan
+// login, kecuali email reset terakhir belum lewat resetPasswordResendInterval.
+func sendPasswordReset(login string) {
+	user, err := findByLogin(login)
+	if err != nil {
+		return
+	}
+
+	resendMu.Lock()
+	var last models.UserToken
+	if err := config.DB.Where("user_id = ? AND purpose = ?", user.ID, purposeResetPassword).
+		Order("created_at desc").First(&last).Error; err == nil &&
+		time.Since(last.CreatedAt) < resetPasswordResendInterval {
+		resendMu.Unlock()
+		return
+	}
+	token, err := issueUserToken(user, purposeResetPassword, resetPasswordTTL)
+	resendMu.Unlock()
+	if err != nil {
+		log.Println("Gagal membuat token reset password:", err)
+		return
+	}
+	link := FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	if err := Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mereset password akun kamu. Klik tautan berikut untuk membuat password baru:\n%s\n\nTautan ini berlaku selama 1 jam dan hanya dapat digunakan sekali. Abaikan email ini jika kamu tidak merasa memintanya.",
+			user.Fullname, link),
+	}); err != nil {
+		log.Println("Gagal mengirim email reset password:", err)
+	}
+}
+
+// ResetPassword mengganti password menggunakan token dari email reset
+// password, lalu mengakhiri semua sesi dan mencabut semua API token user
+// tersebut.
+func ResetPassword(c *gin.Context) {
+	var input struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Password baru divalidasi sebelum token dipakai, sehingga user dapat
+	// mencoba lagi dengan tautan yang sama jika password ditolak.
+	pending, err := findUserToken(input.Token, purposeResetPassword)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset password tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, pending.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset password tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if !validatePassword(c, input.NewPassword, user.Username, user.Email) {
+		return
+	}
+	if _, err := consumeUserToken(input.Token, purposeResetPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset password tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+
+	hashedPassword, err := HashPassword(input.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal meng-hash password baru"})
+		return
+	}
+	if err := config.DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	emitSecurityEvent(c, eventPasswordChanged, user.ID, map[string]interface{}{"method": "reset"})
+	if _, err := revokeUserSessions(user.ID, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password berhasil direset, tetapi gagal mengakhiri sesi"})
+		return
+	}
+	if err := revokeUserAPITokens(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password berhasil direset, tetapi gagal mencabut API token"})
+		return
+	}
+	// Reset password membuktikan kepemilikan email, sehingga kunci akun akibat
+	// percobaan login gagal ikut dibuka.
+	if err := LoginGuard.Unlock(accountGuardKey(&user, "")); err != nil {
+		log.Println("LoginGuard error:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil direset, silakan login dengan password baru"})
+}
+
+// validatePassword memeriksa password baru terhadap PasswordPolicy. Jika
+// ditolak, respons 400 berisi daftar pelanggaran dalam bahasa dari header
+// Accept-Language sudah ditulis dan hasilnya false.
+func validatePassword(c *gin.Context, newPassword string, accountValues ...string) bool {
+	violations := PasswordPolicy.Validate(newPassword, accountValues...)
+	if len(violations) == 0 {
+		return true
+	}
+	lang := requestLanguage(c)
+	details := make([]gin.H, 0, len(violations))
+	for _, violation := range violations {
+		detail := gin.H{
+			"field":   "password",
+			"code":    violation.Code,
+			"message": violation.Message(lang),
+		}
+		if violation.Params != nil {
+			detail["params"] = violation.Params
+		}
+		details = append(details, detail)
+	}
+	message := "Password tidak memenuhi kebijakan keamanan"
+	if lang == "en" {
+		message = "Password does not meet the security policy"
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": message, "errors": details})
+	return false
+}
+
+// requestLanguage memilih bahasa respons ("id" atau "en") dari header
+// Accept-Language. Default-nya bahasa Indonesia.
+func requestLanguage(c *gin.Context) string {
+	bestLang, bestQ := "id", -1.0
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.SplitN(fields[0], "-", 2)[0])
+		if tag != "id" && tag != "en" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > bestQ {
+			bestLang, bestQ = tag, q
+		}
+	}
+	return bestLang
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/models"
+	"social-media-backend/services"
+	"social-media-backend/views"
+)
+
+// ProfileHandler menangani halaman profil publik user.
+type ProfileHandler struct {
+	profiles *services.ProfileService
+}
+
+// NewProfileHandler membuat ProfileHandler.
+func NewProfileHandler(profiles *services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{profiles: profiles}
+}
+
+// GetUserProfile mengembalikan profil publik user berdasarkan username,
+// beserta jumlah followers, following, dan feed, serta relasi follow dengan
+// user yang sedang login.
+func (h *ProfileHandler) GetUserProfile(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	profile, err := h.profiles.Get(currentUser, c.Param("username"))
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil profil user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user":             views.NewPublicUser(profile.User),
+		"followers_count":  profile.FollowersCount,
+		"following_count":  profile.FollowingCount,
+		"feeds_count":      profile.FeedsCount,
+		"is_following":     profile.Following,
+		"follows_you":      profile.FollowedBy,
+		"can_view_content": profile.CanViewContent,
+	})
+}
+
+// GetUserFeeds mengembalikan feed milik user dengan pagination berbasis
+// cursor (?limit= dan ?cursor=).
+func (h *ProfileHandler) GetUserFeeds(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	params, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	page, err := h.profiles.Feeds(currentUser, c.Param("username"), params)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil feeds")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"feeds": views.Feeds(page.Feeds), "next_cursor": nextCursor(page.NextCursor)})
+}
+
+// GetUserFollowers mengembalikan followers user dengan pagination berbasis
+// cursor.
+func (h *ProfileHandler) GetUserFollowers(c *gin.Context) {
+	h.userPage(c, "followers", h.profiles.Followers, "Gagal mengambil daftar followers")
+}
+
+// GetUserFollowing mengembalikan user yang diikuti user dengan pagination
+// berbasis cursor.
+func (h *ProfileHandler) GetUserFollowing(c *gin.Context) {
+	h.userPage(c, "following", h.profiles.Following, "Gagal mengambil daftar following")
+}
+
+func (h *ProfileHandler) userPage(c *gin.Context, key string, list func(models.User, string, services.PageParams) (services.UserPage, error), fallback string) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	params, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	page, err := list(currentUser, c.Param("username"), params)
+	if err != nil {
+		respondServiceError(c, err, fallback)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{key: views.UserSummaries(page.Users), "next_cursor": nextCursor(page.NextCursor)})
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/loginguard"
+	"social-media-backend/models"
+)
+
+// Jenis security event.
+const (
+	eventAccountLocked   = "account_locked"
+	eventLoginSucceeded  = "login_succeeded"
+	eventLoginFailed     = "login_failed"
+	eventPasswordChanged = "password_changed"
+	eventEmailChanged    = "email_changed"
+	eventSessionRevoked  = "session_revoked"
+	eventLogout          = "logout"
+	eventTOTPEnabled     = "totp_enabled"
+	eventTOTPDisabled    = "totp_disabled"
+	eventAPITokenCreated = "api_token_created"
+	eventAPITokenRevoked = "api_token_revoked"
+)
+
+// SecurityEvent adalah kejadian penting terkait keamanan akun.
+type SecurityEvent struct {
+	Type      string
+	UserID    uint
+	IPAddress string
+	UserAgent string
+	Detail    map[string]interface{}
+	At        time.Time
+}
+
+// SecurityEventHandler menerima setiap security event. Default-nya menulis
+// event ke log sebagai JSON dan menyimpannya di audit log.
+var SecurityEventHandler = func(event SecurityEvent) {
+	data, _ := json.Marshal(event)
+	log.Printf("[security] %s", data)
+	if err := saveAuditEvent(event); err != nil {
+		log.Println("Gagal menyimpan audit event:", err)
+	}
+}
+
+// emitSecurityEvent membuat SecurityEvent dari request c dan meneruskannya ke
+// SecurityEventHandler. c boleh nil untuk event dari proses background.
+func emitSecurityEvent(c *gin.Context, eventType string, userID uint, detail map[string]interface{}) {
+	event := SecurityEvent{Type: eventType, UserID: userID, Detail: detail, At: time.Now()}
+	if c != nil {
+		event.IPAddress = c.ClientIP()
+		event.UserAgent = c.Request.UserAgent()
+	}
+	SecurityEventHandler(event)
+}
+
+// LoginGuard membatasi percobaan login per akun dan per IP. main dapat
+// menggantinya dengan penyimpanan bersama (loginguard.DBStore).
+var LoginGuard = loginguard.New(loginguard.NewMemoryStore())
+
+// accountGuardKey mengembalikan kunci LoginGuard untuk akun. Untuk login yang
+// tidak cocok dengan akun mana pun dipakai teks login itu sendiri, sehingga
+// percobaan ke akun yang tidak ada tetap dibatasi.
+func accountGuardKey(user *models.User, login string) string {
+	if user != nil {
+		return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+	}
+	return "login:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+func ipGuardKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// checkLoginGuard menolak request dengan 429 jika akun atau IP masih harus
+// menunggu. Mengembalikan false jika request sudah dijawab.
+func checkLoginGuard(c *gin.Context, accountKey string) bool {
+	wait, locked, err := LoginGuard.Check(accountKey, ipGuardKey(c), time.Now())
+	if err != nil {
+		log.Println("LoginGuard error:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses login"})
+		return false
+	}
+	if wait <= 0 {
+		return true
+	}
+	c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	if locked {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Akun dikunci sementara karena terlalu banyak percobaan login gagal, coba lagi nanti atau reset password"})
+		return false
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak percobaan login, tunggu sebentar lalu coba lagi"})
+	return false
+}
+
+// recordLoginFailure mencatat kegagalan login untuk akun dan IP pada c dan
+// menerbitkan security event jika akun atau IP menjadi terkunci.
+func recordLoginFailure(c *gin.Context, accountKey string, userID uint) {
+	// Token magic link yang tidak dikenal tidak memiliki kunci akun.
+	if accountKey != "" {
+		emitSecurityEvent(c, eventLoginFailed, userID, map[string]interface{}{
+			"key":   accountKey,
+			"route": c.FullPath(),
+		})
+	}
+	lockouts, err := LoginGuard.Fail(accountKey, ipGuardKey(c), time.Now())
+	if err != nil {
+		log.Println("LoginGuard error:", err)
+	}
+	for _, lockout := range lockouts {
+		lockedUserID := userID
+		if lockout.Key != accountKey {
+			lockedUserID = 0
+		}
+		emitSecurityEvent(c, eventAccountLocked, lockedUserID, map[string]interface{}{
+			"key":          lockout.Key,
+			"locked_until": lockout.Until,
+		})
+	}
+}
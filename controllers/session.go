@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/models"
+)
+
+// GetSessions mengembalikan daftar sesi (perangkat) yang masih aktif milik
+// user yang sedang login, ditandai mana sesi yang sedang dipakai.
+func GetSessions(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	currentSessionID := c.GetUint("session_id")
+
+	// Sesi dianggap aktif selama belum dicabut dan masih memiliki refresh
+	// token yang belum terpakai dan belum kedaluwarsa.
+	var sessions []models.Session
+	if err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL", currentUser.ID).
+		Where("EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.session_id = sessions.id AND refresh_tokens.used_at IS NULL AND refresh_tokens.expires_at > ?)", time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar sesi"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, gin.H{
+			"id":           session.ID,
+			"user_agent":   session.UserAgent,
+			"ip_address":   session.IPAddress,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"current":      session.ID == currentSessionID,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": result})
+}
+
+// RevokeSession mengakhiri satu sesi milik user yang sedang login.
+func RevokeSession(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+
+	sessionIDStr := c.Param("id")
+	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID sesi tidak valid"})
+		return
+	}
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionID), currentUser.ID).
+		First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi tidak ditemukan"})
+		return
+	}
+
+	if err := revokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengakhiri sesi"})
+		return
+	}
+	emitSecurityEvent(c, eventSessionRevoked, currentUser.ID, map[string]interface{}{
+		"session_id": session.ID,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Sesi berhasil diakhiri"})
+}
+
+// RevokeOtherSessions mengakhiri semua sesi milik user selain sesi yang
+// sedang dipakai ("logout dari semua perangkat lain").
+func RevokeOtherSessions(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	currentSessionID := c.GetUint("session_id")
+
+	revoked, err := revokeUserSessions(currentUser.ID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengakhiri sesi lain"})
+		return
+	}
+	emitSecurityEvent(c, eventSessionRevoked, currentUser.ID, map[string]interface{}{
+		"except_session_id": currentSessionID,
+		"revoked":           revoked,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Semua sesi lain berhasil diakhiri", "revoked": revoked})
+}
@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/models"
+)
+
+// generateToken menghasilkan token acak 256-bit yang aman dipakai di URL.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken mengembalikan hash SHA-256 (hex) dari token. Token acak sudah
+// memiliki entropi tinggi sehingga tidak perlu hash lambat seperti Argon2id atau bcrypt.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// createSession membuat sesi login baru untuk user, mencatat perangkat yang
+// digunakan, dan menerbitkan pasangan access token dan refresh token pertamanya.
+//
+// Akun yang sedang dinonaktifkan otomatis dipulihkan karena login berhasil
+// dalam masa tenggang.
+func createSession(c *gin.Context, user models.User) (gin.H, error) {
+	if err := reactivateAccount(c, &user); err != nil {
+		return nil, err
+	}
+	session := models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(c.Request.UserAgent(), 255),
+		IPAddress:  c.ClientIP(),
+		LastSeenAt: time.Now(),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	emitSecurityEvent(c, eventLoginSucceeded, user.ID, map[string]interface{}{
+		"session_id": session.ID,
+		"route":      c.FullPath(),
+	})
+	return issueTokenPair(user.ID, session.ID)
+}
+
+// issueTokenPair menerbitkan access token berumur pendek dan refresh token baru
+// untuk sesi yang diberikan.
+func issueTokenPair(userID, sessionID uint) (gin.H, error) {
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stored := models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	}
+	if err := config.DB.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+	accessToken, err := signToken(jwt.MapClaims{
+		"user_id": userID,
+		"sid":     sessionID,
+		"typ":     tokenTypeAccess,
+		"iat":     now.Unix(),
+		"exp":     now.Add(AccessTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// revokeSession mencabut sebuah sesi beserta seluruh refresh token di dalamnya.
+func revokeSession(sessionID uint) error {
+	return config.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeUserSessions mencabut semua sesi aktif milik user kecuali sesi
+// exceptSessionID (isi 0 untuk mencabut semuanya) dan mengembalikan jumlah
+// sesi yang dicabut.
+func revokeUserSessions(userID, exceptSessionID uint) (int64, error) {
+	result := config.DB.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptSessionID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// touchSession memperbarui waktu terakhir sesi terlihat aktif. Pembaruan
+// dibatasi paling sering sekali per sessionTouchInterval agar setiap request
+// tidak selalu menulis ke database.
+func touchSession(c *gin.Context, session models.Session) {
+	if time.Since(session.LastSeenAt) < sessionTouchInterval {
+		return
+	}
+	config.DB.Model(&models.Session{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   c.ClientIP(),
+	})
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// RefreshToken menukar refresh token yang masih berlaku dengan pasangan token
+// baru (rotasi). Jika refresh token yang sudah pernah dipakai dikirim lagi,
+// seluruh sesi dicabut karena token tersebut kemungkinan besar telah bocor.
+func RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var stored models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", hashToken(input.RefreshToken)).First(&stored).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token tidak valid"})
+		return
+	}
+
+	var session models.Session
+	if err := config.DB.First(&session, stored.SessionID).Error; err != nil || session.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesi sudah berakhir, silakan login kembali"})
+		return
+	}
+
+	if stored.UsedAt != nil {
+		revokeReusedSession(c, session)
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token sudah kedaluwarsa"})
+		return
+	}
+
+	// Tandai token lama sebagai terpakai secara atomik, sehingga dari dua
+	// request paralel dengan token yang sama hanya satu yang berhasil.
+	result := config.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		revokeReusedSession(c, session)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	tokens, err := issueTokenPair(user.ID, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+		return
+	}
+	touchSession(c, session)
+	c.JSON(http.StatusOK, tokens)
+}
+
+// revokeReusedSession mencabut sesi karena refresh token yang sudah terpakai
+// digunakan lagi, yang berarti token kemungkinan bocor.
+func revokeReusedSession(c *gin.Context, session models.Session) {
+	if err := revokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut sesi"})
+		return
+	}
+	emitSecurityEvent(c, eventSessionRevoked, session.UserID, map[string]interface{}{
+		"session_id": session.ID,
+		"reason":     "refresh_token_reuse",
+	})
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token sudah pernah digunakan, sesi dicabut"})
+}
+
+// Logout mencabut sesi yang sedang dipakai, sehingga access token dan seluruh
+// refresh token dari sesi tersebut tidak dapat digunakan lagi.
+func Logout(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	if err := revokeSession(sessionID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout"})
+		return
+	}
+	emitSecurityEvent(c, eventLogout, c.MustGet("user").(models.User).ID, map[string]interface{}{
+		"session_id": sessionID,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Logout berhasil"})
+}
+
+// backgroundJobs melacak pekerjaan dari runInBackground.
+var backgroundJobs sync.WaitGroup
+
+// runInBackground menjalankan job di goroutine terpisah. Dipakai untuk
+// pekerjaan yang tidak boleh memengaruhi waktu respons, misalnya pengiriman
+// email yang keberadaannya tidak boleh bocor ke pemanggil.
+func runInBackground(job func()) {
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		job()
+	}()
+}
+
+// resendMu menyerialkan pemeriksaan jarak kirim ulang dan issueUserToken pada
+// pekerjaan background, sehingga dua permintaan yang berdekatan tidak sama-sama
+// lolos pemeriksaan.
+var resendMu sync.Mutex
+
+// WaitBackground menunggu semua pekerjaan dari runInBackground selesai.
+func WaitBackground() {
+	backgroundJobs.Wait()
+}
+
+var errInvalidUserToken = errors.New("token tidak valid atau sudah kedaluwarsa")
+
+// issueUserToken membuat token sekali pakai untuk keperluan purpose. Token
+// sebelumnya dengan purpose yang sama yang belum dipakai ikut dibatalkan,
+// sehingga hanya tautan terbaru yang berlaku.
+func issueUserToken(user models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if err := config.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+		Update("used_at", now).Error; err != nil {
+		return "", err
+	}
+	userToken := models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := config.DB.Create(&userToken).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken memvalidasi token lalu menandainya sebagai terpakai. Token
+// yang salah purpose, sudah dipakai, atau kedaluwarsa menghasilkan
+// errInvalidUserToken.
+func consumeUserToken(token, purpose string) (models.UserToken, error) {
+	userToken, err := findUserToken(token, purpose)
+	if err != nil {
+		return userToken, err
+	}
+	result := config.DB.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return userToken, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return userToken, errInvalidUserToken
+	}
+	return userToken, nil
+}
+
+// findUserToken mencari token yang masih berlaku tanpa menandainya terpakai.
+func findUserToken(token, purpose string) (models.UserToken, error) {
+	var userToken models.UserToken
+	if err := config.DB.Where("token_hash = ? AND purpose = ?", hashToken(token), purpose).
+		First(&userToken).Error; err != nil {
+		return userToken, errInvalidUserToken
+	}
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return userToken, errInvalidUserToken
+	}
+	return userToken, nil
+}
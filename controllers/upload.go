@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/imaging"
+)
+
+// UploadDir adalah direktori (relatif terhadap working directory) tempat file
+// upload disimpan. Path yang disimpan di database diawali UploadDir sehingga
+// dapat diakses client lewat route static dengan nama yang sama.
+var UploadDir = "public/uploads"
+
+// MaxUploadSize adalah ukuran maksimum satu file upload dalam byte dan
+// MaxUploadFiles jumlah maksimum file dalam satu request. main mengisinya
+// dari konfigurasi.
+var (
+	MaxUploadSize  int64 = 10 << 20
+	MaxUploadFiles       = 10
+)
+
+// uploadError adalah kesalahan validasi upload yang ditampilkan ke client.
+type uploadError struct {
+	status  int
+	message string
+}
+
+func (e *uploadError) Error() string { return e.message }
+
+// saveUpload memvalidasi ukuran file lalu menyimpannya di UploadDir dengan
+// nama unik. Path yang dikembalikan disimpan apa adanya di database.
+func saveUpload(c *gin.Context, file *multipart.FileHeader) (string, error) {
+	if file.Size > MaxUploadSize {
+		return "", &uploadError{http.StatusRequestEntityTooLarge, "Ukuran file maksimal " + formatBytes(MaxUploadSize)}
+	}
+	filename := time.Now().Format("20060102150405_") + filepath.Base(file.Filename)
+	savePath := path.Join(UploadDir, filename)
+	if err := c.SaveUploadedFile(file, savePath); err != nil {
+		return "", err
+	}
+	return savePath, nil
+}
+
+// saveUploads menyimpan beberapa file sekaligus dengan batas MaxUploadFiles.
+func saveUploads(c *gin.Context, files []*multipart.FileHeader) ([]string, error) {
+	if len(files) > MaxUploadFiles {
+		return nil, &uploadError{http.StatusBadRequest, fmt.Sprintf("Maksimal %d file per upload", MaxUploadFiles)}
+	}
+	var paths []string
+	for _, file := range files {
+		savePath, err := saveUpload(c, file)
+		if err != nil {
+			removeUploadedFiles(paths)
+			return nil, err
+		}
+		paths = append(paths, savePath)
+	}
+	return paths, nil
+}
+
+// saveImageUpload memproses gambar upload menjadi sizes (lihat package
+// imaging) dan menyimpan setiap ukuran sebagai JPEG di UploadDir. Hasilnya
+// adalah path setiap ukuran dengan nama ukuran sebagai key.
+func saveImageUpload(file *multipart.FileHeader, sizes []imaging.Size) (map[string]string, error) {
+	if file.Size > MaxUploadSize {
+		return nil, &uploadError{http.StatusRequestEntityTooLarge, "Ukuran file maksimal " + formatBytes(MaxUploadSize)}
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, MaxUploadSize))
+	if err != nil {
+		return nil, err
+	}
+	images, err := imaging.Process(data, sizes)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupported):
+		return nil, &uploadError{http.StatusUnsupportedMediaType, "File harus berupa gambar JPEG, PNG, GIF, atau WebP"}
+	case errors.Is(err, imaging.ErrInvalid):
+		return nil, &uploadError{http.StatusBadRequest, "Gambar tidak dapat dibaca"}
+	case errors.Is(err, imaging.ErrTooLarge):
+		return nil, &uploadError{http.StatusBadRequest, fmt.Sprintf("Dimensi gambar maksimal %d megapiksel", imaging.MaxPixels/1_000_000)}
+	case err != nil:
+		return nil, err
+	}
+
+	// Nama file tidak memakai nama dari client; bagian acak mencegah
+	// bentrok antar upload pada detik yang sama.
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	prefix := time.Now().Format("20060102150405_") + hex.EncodeToString(random)
+	if err := os.MkdirAll(UploadDir, 0750); err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string, len(images))
+	for name, image := range images {
+		savePath := path.Join(UploadDir, prefix+"_"+name+".jpg")
+		if err := os.WriteFile(savePath, image, 0644); err != nil {
+			removeUploadedFiles(imageFiles("", paths))
+			return nil, err
+		}
+		paths[name] = savePath
+	}
+	return paths, nil
+}
+
+// imageFiles mengembalikan file gambar utama beserta semua ukurannya, tanpa
+// duplikat.
+func imageFiles(main string, variants map[string]string) []string {
+	files := []string{}
+	if main != "" {
+		files = append(files, main)
+	}
+	for _, variant := range variants {
+		if variant != main {
+			files = append(files, variant)
+		}
+	}
+	return files
+}
+
+// respondUploadError menulis respons untuk kegagalan saveUpload dan
+// saveImageUpload.
+func respondUploadError(c *gin.Context, err error) {
+	if uploadErr, ok := err.(*uploadError); ok {
+		c.JSON(uploadErr.status, gin.H{"error": uploadErr.message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d byte", n)
+	}
+}
+
+// isUploadedFile memeriksa apakah path berada di dalam UploadDir.
+func isUploadedFile(p string) bool {
+	return strings.HasPrefix(p, strings.TrimSuffix(UploadDir, "/")+"/") && !strings.Contains(p, "..")
+}
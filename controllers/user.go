@@ -1,25 +1,37 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	"social-media-backend/config"
+	"social-media-backend/imaging"
 	"social-media-backend/models"
+	"social-media-backend/services"
+	"social-media-backend/views"
 )
 
+// UserHandler menangani endpoint data user yang memakai UserService.
+type UserHandler struct {
+	users *services.UserService
+}
+
+// NewUserHandler membuat UserHandler.
+func NewUserHandler(users *services.UserService) *UserHandler {
+	return &UserHandler{users: users}
+}
+
 func GetProfile(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user dari context"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": views.NewProfile(user.(models.User))})
 }
 
-func UpdateProfile(c *gin.Context) {
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -27,54 +39,97 @@ func UpdateProfile(c *gin.Context) {
 	}
 	currentUser := currentUserInterface.(models.User)
 	var input struct {
-		Fullname     string `form:"fullname" json:"fullname"`
-		Username     string `form:"username" json:"username"`
-		Email        string `form:"email" json:"email"`
-		JenisKelamin string `form:"jenis_kelamin" json:"jenis_kelamin"`
-		TanggalLahir string `form:"tanggal_lahir" json:"tanggal_lahir"` // format YYYY-MM-DD
+		Fullname     string   `form:"fullname" json:"fullname"`
+		Username     string   `form:"username" json:"username"`
+		Email        string   `form:"email" json:"email" binding:"omitempty,email"`
+		JenisKelamin string   `form:"jenis_kelamin" json:"jenis_kelamin"`
+		TanggalLahir string   `form:"tanggal_lahir" json:"tanggal_lahir"` // format YYYY-MM-DD
+		IsPrivate    *bool    `form:"is_private" json:"is_private"`
+		Bio          *string  `form:"bio" json:"bio"`
+		Location     *string  `form:"location" json:"location"`
+		Pronouns     *string  `form:"pronouns" json:"pronouns"`
+		Links        []string `form:"links" json:"links"` // di form-data: satu field links per tautan
+		// Visibilitas per field, misalnya {"tanggal_lahir": "month_day"}; di
+		// form-data dikirim sebagai JSON.
+		Visibility map[string]string `form:"visibility" json:"visibility"`
 	}
 	if err := c.ShouldBind(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var parsedTanggal time.Time
+	update := services.ProfileUpdate{
+		Fullname:     input.Fullname,
+		Username:     input.Username,
+		Email:        normalizeEmail(input.Email),
+		JenisKelamin: input.JenisKelamin,
+		IsPrivate:    input.IsPrivate,
+		Bio:          input.Bio,
+		Location:     input.Location,
+		Pronouns:     input.Pronouns,
+		Links:        input.Links,
+		Visibility:   input.Visibility,
+	}
 	if input.TanggalLahir != "" {
 		t, err := time.Parse("2006-01-02", input.TanggalLahir)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal lahir tidak valid, gunakan YYYY-MM-DD"})
 			return
 		}
-		parsedTanggal = t
-	}
-	photoPath := currentUser.PhotoProfile
-	file, err := c.FormFile("photo_profile")
-	if err == nil {
-		filename := time.Now().Format("20060102150405_") + file.Filename
-		savePath := "public/uploads/" + filename
-		if err := c.SaveUploadedFile(file, savePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
+		update.TanggalLahir = &t
+	}
+	images := []struct {
+		field    string
+		sizes    []imaging.Size
+		path     *string
+		variants *map[string]string
+	}{
+		{"photo_profile", imaging.AvatarSizes, &update.PhotoProfile, &update.PhotoVariants},
+		{"banner", imaging.BannerSizes, &update.Banner, &update.BannerVariants},
+	}
+	var uploaded []string
+	for _, image := range images {
+		file, err := c.FormFile(image.field)
+		if err != nil {
+			continue
+		}
+		variants, err := saveImageUpload(file, image.sizes)
+		if err != nil {
+			removeUploadedFiles(uploaded)
+			respondUploadError(c, err)
 			return
 		}
-		photoPath = savePath
-	}
-	updatedData := models.User{
-		Fullname:     input.Fullname,
-		Username:     input.Username,
-		Email:        input.Email,
-		JenisKelamin: input.JenisKelamin,
-		PhotoProfile: photoPath,
+		*image.variants = variants
+		*image.path = variants[image.sizes[len(image.sizes)-1].Name]
+		uploaded = append(uploaded, imageFiles("", variants)...)
 	}
-	if !parsedTanggal.IsZero() {
-		updatedData.TanggalLahir = &parsedTanggal
-	}
-	if err := config.DB.Model(&currentUser).Updates(updatedData).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	previous := currentUser
+	emailChanged, err := h.users.UpdateProfile(&currentUser, update)
+	if err != nil {
+		removeUploadedFiles(uploaded)
+		respondServiceError(c, err, "Gagal mengubah profil")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": currentUser})
+	// Gambar lama dihapus setelah gambar baru tersimpan di profil.
+	if update.PhotoProfile != "" {
+		removeUploadedFiles(imageFiles(previous.PhotoProfile, previous.PhotoVariants))
+	}
+	if update.Banner != "" {
+		removeUploadedFiles(imageFiles(previous.Banner, previous.BannerVariants))
+	}
+	// Alamat email baru harus diverifikasi ulang.
+	if emailChanged {
+		emitSecurityEvent(c, eventEmailChanged, currentUser.ID, map[string]interface{}{
+			"previous_email": previous.Email,
+			"email":          currentUser.Email,
+		})
+		if err := sendVerificationEmail(currentUser); err != nil {
+			log.Println("Gagal mengirim email verifikasi:", err)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"user": views.NewProfile(currentUser)})
 }
 
-func ChangePassword(c *gin.Context) {
+func (h *UserHandler) ChangePassword(c *gin.Context) {
 	currentUserInterface, exists := c.Get("user")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
@@ -83,25 +138,45 @@ func ChangePassword(c *gin.Context) {
 	currentUser := currentUserInterface.(models.User)
 	var input struct {
 		OldPassword string `json:"old_password" binding:"required"`
-		NewPassword string `json:"new_password" binding:"required,min=6"`
+		NewPassword string `json:"new_password" binding:"required"`
+		// Jika true, semua sesi lain selain sesi saat ini diakhiri dan
+		// semua API token dicabut.
+		LogoutOtherSessions bool `json:"logout_other_sessions"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(currentUser.Password), []byte(input.OldPassword)); err != nil {
+	if !CheckPasswordHash(input.OldPassword, currentUser.Password) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password lama salah"})
 		return
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), 14)
+	if !validatePassword(c, input.NewPassword, currentUser.Username, currentUser.Email) {
+		return
+	}
+	hashedPassword, err := HashPassword(input.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal meng-hash password baru"})
 		return
 	}
-	if err := config.DB.Model(&currentUser).Update("password", string(hashedPassword)).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.users.SetPassword(&currentUser, hashedPassword); err != nil {
+		respondServiceError(c, err, "Gagal mengubah password")
 		return
 	}
+	emitSecurityEvent(c, eventPasswordChanged, currentUser.ID, map[string]interface{}{
+		"method":                "change",
+		"logout_other_sessions": input.LogoutOtherSessions,
+	})
+	if input.LogoutOtherSessions {
+		if _, err := revokeUserSessions(currentUser.ID, c.GetUint("session_id")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password berhasil diubah, tetapi gagal mengakhiri sesi lain"})
+			return
+		}
+		if err := revokeUserAPITokens(currentUser.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password berhasil diubah, tetapi gagal mencabut API token"})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diubah"})
 }
 
@@ -112,18 +187,45 @@ func DeactivateAccount(c *gin.Context) {
 		return
 	}
 	currentUser := currentUserInterface.(models.User)
-	if err := config.DB.Delete(&currentUser).Error; err != nil {
+	if err := deactivateAccount(c, currentUser); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Akun berhasil dinonaktifkan"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Akun berhasil dinonaktifkan, login kembali sebelum batas waktu untuk memulihkan akun",
+		"purge_after": time.Now().Add(AccountDeletionGracePeriod),
+	})
 }
 
-func GetAllUsers(c *gin.Context) {
-	var users []models.User
-	if err := config.DB.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// GetAllUsers mengembalikan direktori user dengan pagination berbasis cursor.
+// Query: ?q= (cari username/fullname), ?filter= (following, followers,
+// mutuals), ?sort= (username, newest, relevance), ?limit=, dan ?cursor= dari
+// next_cursor halaman sebelumnya.
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	params := services.DirectoryParams{
+		Search: c.Query("q"),
+		Filter: c.Query("filter"),
+		Sort:   c.Query("sort"),
+		Cursor: page.Cursor,
+		Limit:  page.Limit,
+	}
+	directory, err := h.users.Directory(currentUser, params)
+	if err != nil {
+		respondServiceError(c, err, "Gagal mengambil daftar user")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	c.JSON(http.StatusOK, gin.H{
+		"users":       views.UserSummaries(directory.Users),
+		"next_cursor": nextCursor(directory.NextCursor),
+	})
 };
\ No newline at end of file
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"social-media-backend/config"
+	"social-media-backend/mailer"
+	"social-media-backend/models"
+)
+
+const (
+	purposeVerifyEmail = "verify_email"
+
+	verifyEmailTTL = 24 * time.Hour
+	// Batas pengiriman ulang email verifikasi per user.
+	verifyEmailResendInterval = time.Minute
+	verifyEmailResendPerHour  = 5
+)
+
+// Mailer dipakai untuk mengirim semua email aplikasi. Default-nya LogMailer;
+// main mengganti dengan mailer.New.
+var Mailer mailer.Mailer = mailer.NewLogMailer()
+
+// AppBaseURL adalah URL publik backend, dipakai untuk membuat tautan di email.
+var AppBaseURL = "http://localhost:8080"
+
+// RequireEmailVerification mengaktifkan pemeriksaan RequireVerifiedEmail.
+var RequireEmailVerification = false
+
+// sendVerificationEmail menerbitkan token verifikasi dan mengirimkannya ke
+// alamat email user.
+func sendVerificationEmail(user models.User) error {
+	token, err := issueUserToken(user, purposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+	link := AppBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi alamat email kamu",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik tautan berikut untuk memverifikasi alamat email kamu:\n%s\n\nTautan ini berlaku selama 24 jam.",
+			user.Fullname, link),
+	})
+}
+
+// VerifyEmail menandai email user sebagai terverifikasi menggunakan token dari
+// tautan yang dikirim lewat email.
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token verifikasi diperlukan"})
+		return
+	}
+	userToken, err := consumeUserToken(token, purposeVerifyEmail)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token verifikasi tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, userToken.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	// Token hanya berlaku untuk alamat email saat token dibuat.
+	if user.Email != userToken.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token verifikasi tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if err := config.DB.Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email berhasil diverifikasi"})
+}
+
+// ResendVerificationEmail mengirim ulang email verifikasi untuk user yang
+// sedang login, dibatasi agar tidak bisa dipakai untuk spam.
+func ResendVerificationEmail(c *gin.Context) {
+	currentUserInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+		return
+	}
+	currentUser := currentUserInterface.(models.User)
+	if currentUser.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email sudah terverifikasi"})
+		return
+	}
+
+	var last models.UserToken
+	err := config.DB.Where("user_id = ? AND purpose = ?", currentUser.ID, purposeVerifyEmail).
+		Order("created_at desc").First(&last).Error
+	if err == nil {
+		if wait := verifyEmailResendInterval - time.Since(last.CreatedAt); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Tunggu sebentar sebelum meminta email verifikasi lagi"})
+			return
+		}
+	}
+	var sentLastHour int64
+	if err := config.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", currentUser.ID, purposeVerifyEmail, time.Now().Add(-time.Hour)).
+		Count(&sentLastHour).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sentLastHour >= verifyEmailResendPerHour {
+		c.Header("Retry-After", strconv.Itoa(int(time.Hour.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak permintaan email verifikasi, coba lagi nanti"})
+		return
+	}
+
+	if err := sendVerificationEmail(currentUser); err != nil {
+		log.Println("Gagal mengirim email verifikasi:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email verifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email verifikasi telah dikirim"})
+}
+
+// RequireVerifiedEmail menolak request dari user yang belum memverifikasi
+// emailnya. Harus dipasang setelah AuthMiddleware dan hanya aktif jika
+// RequireEmailVerification bernilai true.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !RequireEmailVerification {
+			c.Next()
+			return
+		}
+		currentUserInterface, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
+			c.Abort()
+			return
+		}
+		if currentUserInterface.(models.User).EmailVerifiedAt == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Verifikasi email kamu terlebih dahulu"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,1220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"social-media-backend/config"
+	"social-media-backend/controllers"
+	"social-media-backend/loginguard"
+	"social-media-backend/models"
+	"social-media-backend/oidc"
+	"social-media-backend/oidc/oidctest"
+	"social-media-backend/policy"
+	"social-media-backend/totp"
+)
+
+// publicRoutes adalah route yang dapat diakses tanpa login.
+var publicRoutes = map[string]bool{
+	"GET /.well-known/jwks.json":     true,
+	"POST /register":                 true,
+	"POST /login":                    true,
+	"POST /login/mfa":                true,
+	"POST /login/magic-link":         true,
+	"POST /login/magic-link/consume": true,
+	"GET /auth/:provider/login":      true,
+	"GET /auth/:provider/callback":   true,
+	"POST /token/refresh":            true,
+	"GET /verify-email":              true,
+	"POST /password/forgot":          true,
+	"POST /password/reset":           true,
+	"GET /public/*filepath":          true,
+	"HEAD /public/*filepath":         true,
+}
+
+// sessionOnlyRoutes adalah route yang menolak API token.
+var sessionOnlyRoutes = []string{
+	"POST /logout",
+	"GET /sessions",
+	"DELETE /sessions/1",
+	"DELETE /sessions",
+	"GET /tokens",
+	"POST /tokens",
+	"DELETE /tokens/1",
+	"PUT /profile",
+	"PUT /profile/password",
+	"DELETE /profile",
+	"POST /verify-email/resend",
+	"GET /profile/security-events",
+	"POST /profile/2fa/enroll",
+	"POST /profile/2fa/confirm",
+	"POST /profile/2fa/recovery-codes",
+	"DELETE /profile/2fa",
+	"DELETE /admin/feeds/1",
+	"DELETE /admin/comments/1",
+	"DELETE /admin/messages/1",
+	"GET /admin/security-events",
+	"GET /admin/users",
+	"PUT /admin/users/1/role",
+}
+
+// samplePath mengisi parameter route dengan nilai contoh.
+func samplePath(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			parts[i] = "1"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func TestProtectedRoutesRequireAuth(t *testing.T) {
+	app := newTestApp(t)
+	for _, route := range app.router.Routes() {
+		key := route.Method + " " + route.Path
+		if publicRoutes[key] {
+			continue
+		}
+		path := samplePath(route.Path)
+		for name, token := range map[string]string{"tanpa token": "", "token tidak valid": "bukan-token"} {
+			if code := app.probe(route.Method, path, token); code != http.StatusUnauthorized {
+				t.Errorf("%s %s (%s): status %d, ingin %d", route.Method, path, name, code, http.StatusUnauthorized)
+			}
+		}
+	}
+}
+
+func TestSessionOnlyRoutesRejectAPIToken(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	var scopes []string
+	for _, scope := range policy.Scopes() {
+		scopes = append(scopes, string(scope))
+	}
+	token := app.apiToken(alice, scopes...)
+	for _, route := range sessionOnlyRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		if code := app.probe(method, path, token); code != http.StatusForbidden {
+			t.Errorf("%s: status %d, ingin %d", route, code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	feedsRead := app.apiToken(alice, string(policy.ScopeFeedsRead))
+	profileRead := app.apiToken(alice, string(policy.ScopeProfileRead))
+
+	body := app.mustDo(http.StatusOK, "GET", "/tokens", alice.Token, nil)
+	if tokens, _ := body["tokens"].([]interface{}); len(tokens) != 2 {
+		t.Fatalf("GET /tokens: %d token, ingin 2", len(tokens))
+	}
+	tokenID := fmt.Sprint(body["tokens"].([]interface{})[0].(map[string]interface{})["id"])
+
+	app.run(t, []routeCase{
+		{"scope sesuai", "GET", "/feeds", feedsRead, nil, http.StatusOK},
+		{"scope kurang untuk menulis", "POST", "/feeds", feedsRead, formBody{fields: map[string]string{"feed": "halo"}}, http.StatusForbidden},
+		{"scope kurang untuk profile", "GET", "/profile", feedsRead, nil, http.StatusForbidden},
+		{"scope profile", "GET", "/profile", profileRead, nil, http.StatusOK},
+		{"scope tidak dikenal", "POST", "/tokens", alice.Token, map[string]interface{}{"name": "x", "scopes": []string{"semua"}}, http.StatusBadRequest},
+		{"cabut token user lain", "DELETE", "/tokens/" + tokenID, bob.Token, nil, http.StatusNotFound},
+		{"cabut token", "DELETE", "/tokens/" + tokenID, alice.Token, nil, http.StatusOK},
+		{"cabut token dua kali", "DELETE", "/tokens/" + tokenID, alice.Token, nil, http.StatusNotFound},
+	})
+	// Token terbaru (profileRead) yang dicabut tidak dapat dipakai lagi.
+	if code := app.probe("GET", "/profile", profileRead); code != http.StatusUnauthorized {
+		t.Errorf("token yang dicabut: status %d, ingin %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthFlow(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	registration := func(username, password string) map[string]interface{} {
+		return map[string]interface{}{
+			"fullname":      "User " + username,
+			"username":      username,
+			"email":         username + "@example.com",
+			"password":      password,
+			"jenis_kelamin": "P",
+			"tanggal_lahir": "2000-01-01",
+		}
+	}
+
+	app.run(t, []routeCase{
+		{"register duplikat", "POST", "/register", "", registration("alice", testPassword), http.StatusConflict},
+		{"register password lemah", "POST", "/register", "", registration("bob", "abc"), http.StatusBadRequest},
+		{"login password salah", "POST", "/login", "", map[string]interface{}{"login": "alice", "password": "salah-sekali"}, http.StatusUnauthorized},
+		{"login username huruf besar", "POST", "/login", "", map[string]interface{}{"login": " ALICE ", "password": testPassword}, http.StatusOK},
+		{"login email huruf besar", "POST", "/login", "", map[string]interface{}{"login": "Alice@Example.com", "password": testPassword}, http.StatusOK},
+		{"login mfa tanpa challenge", "POST", "/login/mfa", "", map[string]interface{}{"mfa_token": "salah", "code": "000000"}, http.StatusUnauthorized},
+		{"jwks", "GET", "/.well-known/jwks.json", "", nil, http.StatusOK},
+		{"oidc provider tidak dikenal", "GET", "/auth/unknown/login", "", nil, http.StatusNotFound},
+		{"oidc callback provider tidak dikenal", "GET", "/auth/unknown/callback", "", nil, http.StatusNotFound},
+		{"verifikasi token salah", "GET", "/verify-email?token=salah", "", nil, http.StatusBadRequest},
+		{"kirim ulang verifikasi terlalu cepat", "POST", "/verify-email/resend", alice.Token, nil, http.StatusTooManyRequests},
+	})
+
+	t.Run("verifikasi email", func(t *testing.T) {
+		app := app.with(t)
+		token := app.mailToken(alice.Email, "Verifikasi alamat email kamu")
+		app.mustDo(http.StatusOK, "GET", "/verify-email?token="+url.QueryEscape(token), "", nil)
+		app.mustDo(http.StatusBadRequest, "POST", "/verify-email/resend", alice.Token, nil)
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		app := app.with(t)
+		body := app.mustDo(http.StatusOK, "POST", "/token/refresh", "", map[string]interface{}{"refresh_token": alice.RefreshToken})
+		if body["refresh_token"] == alice.RefreshToken {
+			t.Error("refresh token tidak dirotasi")
+		}
+		app.mustDo(http.StatusUnauthorized, "POST", "/token/refresh", "", map[string]interface{}{"refresh_token": alice.RefreshToken})
+	})
+
+	t.Run("reset password", func(t *testing.T) {
+		app := app.with(t)
+		pat := app.apiToken(app.login("alice", testPassword), string(policy.ScopeProfileRead))
+		app.mustDo(http.StatusOK, "GET", "/profile", pat, nil)
+
+		// Akun yang tidak terdaftar mendapat respons yang sama dan tidak ada
+		// email yang dikirim.
+		sent := len(app.mail.Messages())
+		unknown := app.mustDo(http.StatusOK, "POST", "/password/forgot", "", map[string]interface{}{"login": "tidak-ada"})
+		known := app.mustDo(http.StatusOK, "POST", "/password/forgot", "", map[string]interface{}{"login": "Alice"})
+		if fmt.Sprint(unknown) != fmt.Sprint(known) {
+			t.Errorf("respons berbeda: akun tidak ada %v, akun ada %v", unknown, known)
+		}
+		// Permintaan kedua dalam resetPasswordResendInterval tidak mengirim
+		// email baru.
+		app.mustDo(http.StatusOK, "POST", "/password/forgot", "", map[string]interface{}{"login": "alice"})
+		controllers.WaitBackground()
+		if got := len(app.mail.Messages()) - sent; got != 1 {
+			t.Fatalf("%d email reset password terkirim, ingin 1", got)
+		}
+		token := app.mailToken(alice.Email, "Reset password")
+
+		app.mustDo(http.StatusBadRequest, "POST", "/password/reset", "", map[string]interface{}{"token": "salah", "new_password": "Another-pass7"})
+		// Token yang kedaluwarsa ditolak.
+		config.DB.Model(&models.UserToken{}).Where("purpose = ?", "reset_password").Update("expires_at", time.Now().Add(-time.Minute))
+		app.mustDo(http.StatusBadRequest, "POST", "/password/reset", "", map[string]interface{}{"token": token, "new_password": "Another-pass7"})
+		config.DB.Model(&models.UserToken{}).Where("purpose = ?", "reset_password").Update("expires_at", time.Now().Add(time.Hour))
+
+		app.mustDo(http.StatusOK, "POST", "/password/reset", "", map[string]interface{}{"token": token, "new_password": "Another-pass7"})
+		// Token hanya dapat dipakai sekali.
+		app.mustDo(http.StatusBadRequest, "POST", "/password/reset", "", map[string]interface{}{"token": token, "new_password": "Third-pass-9"})
+		app.mustDo(http.StatusUnauthorized, "POST", "/login", "", map[string]interface{}{"login": "alice", "password": testPassword})
+		// Reset password mencabut API token.
+		app.mustDo(http.StatusUnauthorized, "GET", "/profile", pat, nil)
+		app.login("alice@example.com", "Another-pass7")
+	})
+
+	t.Run("brute force", func(t *testing.T) {
+		app := app.with(t)
+		carol := app.register("carol")
+		wrong := map[string]interface{}{"login": "carol", "password": "salah-sekali"}
+		for i := 0; i < loginguard.DefaultAccountPolicy.FreeAttempts+1; i++ {
+			app.mustDo(http.StatusUnauthorized, "POST", "/login", "", wrong)
+		}
+		// Kegagalan di atas FreeAttempts dikenai jeda, walaupun password benar.
+		rec := app.request("POST", "/login", "", map[string]interface{}{"login": "carol", "password": testPassword})
+		if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+			t.Fatalf("login saat backoff: status %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+		}
+
+		// Kegagalan lama yang jedanya sudah lewat tetap dihitung menuju
+		// penguncian.
+		key := fmt.Sprintf("user:%d", carol.ID)
+		controllers.LoginGuard.Store.Reset(key)
+		for i := 0; i < loginguard.DefaultAccountPolicy.LockoutThreshold-1; i++ {
+			controllers.LoginGuard.Fail(key, "", time.Now().Add(-time.Hour))
+		}
+		app.mustDo(http.StatusUnauthorized, "POST", "/login", "", wrong)
+		body := app.mustDo(http.StatusTooManyRequests, "POST", "/login", "", map[string]interface{}{"login": "carol", "password": testPassword})
+		if !strings.Contains(fmt.Sprint(body["error"]), "dikunci") {
+			t.Errorf("login saat dikunci = %v", body)
+		}
+
+		// Reset password membuka kunci akun.
+		app.mustDo(http.StatusOK, "POST", "/password/forgot", "", map[string]interface{}{"login": "carol"})
+		token := app.mailToken(carol.Email, "Reset password")
+		app.mustDo(http.StatusOK, "POST", "/password/reset", "", map[string]interface{}{"token": token, "new_password": "Another-pass7"})
+		app.login("carol", "Another-pass7")
+	})
+
+	t.Run("magic link", func(t *testing.T) {
+		app := app.with(t)
+		sent := len(app.mail.Messages())
+		app.mustDo(http.StatusOK, "POST", "/login/magic-link", "", map[string]interface{}{"email": "tidak-ada@example.com"})
+		app.mustDo(http.StatusOK, "POST", "/login/magic-link", "", map[string]interface{}{"email": alice.Email})
+		app.mustDo(http.StatusOK, "POST", "/login/magic-link", "", map[string]interface{}{"email": alice.Email})
+		controllers.WaitBackground()
+		if got := len(app.mail.Messages()) - sent; got != 1 {
+			t.Fatalf("%d email magic link terkirim, ingin 1", got)
+		}
+		token := app.mailToken(alice.Email, "Tautan login")
+		body := app.mustDo(http.StatusOK, "POST", "/login/magic-link/consume", "", map[string]interface{}{"token": token})
+		if body["token"] == nil {
+			t.Errorf("magic link tidak mengembalikan token: %v", body)
+		}
+		app.mustDo(http.StatusUnauthorized, "POST", "/login/magic-link/consume", "", map[string]interface{}{"token": token})
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		app := app.with(t)
+		session := app.login("alice", "Another-pass7")
+		app.mustDo(http.StatusOK, "POST", "/logout", session.Token, nil)
+		app.mustDo(http.StatusUnauthorized, "GET", "/profile", session.Token, nil)
+		app.mustDo(http.StatusUnauthorized, "POST", "/token/refresh", "", map[string]interface{}{"refresh_token": session.RefreshToken})
+	})
+}
+
+func TestTwoFactor(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	app.mustDo(http.StatusBadRequest, "DELETE", "/profile/2fa", alice.Token, map[string]interface{}{"password": testPassword, "code": "000000"})
+	app.mustDo(http.StatusBadRequest, "POST", "/profile/2fa/enroll", alice.Token, nil)
+	app.mustDo(http.StatusUnauthorized, "POST", "/profile/2fa/enroll", alice.Token, map[string]interface{}{"password": "salah-sekali"})
+	enroll := app.mustDo(http.StatusOK, "POST", "/profile/2fa/enroll", alice.Token, map[string]interface{}{"password": testPassword})
+	secret, _ := enroll["secret"].(string)
+	if secret == "" {
+		t.Fatalf("enroll tidak mengembalikan secret: %v", enroll)
+	}
+	// Kode TOTP tidak dapat dipakai ulang, sehingga setiap langkah memakai
+	// time step yang lebih baru dalam rentang skew.
+	code := func(offset time.Duration) string {
+		value, err := totp.Code(secret, time.Now().Add(offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	app.mustDo(http.StatusBadRequest, "POST", "/profile/2fa/confirm", alice.Token, map[string]interface{}{"code": "salah"})
+	confirm := app.mustDo(http.StatusOK, "POST", "/profile/2fa/confirm", alice.Token, map[string]interface{}{"code": code(-30 * time.Second)})
+	if codes, _ := confirm["recovery_codes"].([]interface{}); len(codes) == 0 {
+		t.Fatalf("confirm tidak mengembalikan recovery code: %v", confirm)
+	}
+
+	challenge := app.mustDo(http.StatusOK, "POST", "/login", "", map[string]interface{}{"login": "alice", "password": testPassword})
+	mfaToken, _ := challenge["mfa_token"].(string)
+	if challenge["mfa_required"] != true || mfaToken == "" {
+		t.Fatalf("login dengan 2FA tidak meminta kode: %v", challenge)
+	}
+	app.mustDo(http.StatusUnauthorized, "POST", "/login/mfa", "", map[string]interface{}{"mfa_token": mfaToken, "code": "12345"})
+	session := app.mustDo(http.StatusOK, "POST", "/login/mfa", "", map[string]interface{}{"mfa_token": mfaToken, "code": code(0)})
+	token, _ := session["token"].(string)
+
+	// TOTPLastStep menolak kode yang sama maupun kode dari langkah yang lebih
+	// lama, walaupun masih di dalam rentang skew.
+	replay := app.mustDo(http.StatusOK, "POST", "/login", "", map[string]interface{}{"login": "alice", "password": testPassword})
+	app.run(t, []routeCase{
+		{"kode dipakai ulang", "POST", "/login/mfa", "", map[string]interface{}{"mfa_token": replay["mfa_token"], "code": code(0)}, http.StatusUnauthorized},
+		{"kode langkah lama", "POST", "/login/mfa", "", map[string]interface{}{"mfa_token": replay["mfa_token"], "code": code(-30 * time.Second)}, http.StatusUnauthorized},
+	})
+
+	app.mustDo(http.StatusBadRequest, "POST", "/profile/2fa/recovery-codes", token, map[string]interface{}{"code": "12345"})
+	regenerated := app.mustDo(http.StatusOK, "POST", "/profile/2fa/recovery-codes", token, map[string]interface{}{"code": code(30 * time.Second)})
+	codes, _ := regenerated["recovery_codes"].([]interface{})
+	if len(codes) == 0 {
+		t.Fatalf("recovery code tidak dibuat ulang: %v", regenerated)
+	}
+
+	app.mustDo(http.StatusUnauthorized, "DELETE", "/profile/2fa", token, map[string]interface{}{"password": "salah-sekali", "recovery_code": codes[0]})
+	app.mustDo(http.StatusOK, "DELETE", "/profile/2fa", token, map[string]interface{}{"password": testPassword, "recovery_code": codes[0]})
+	app.login("alice", testPassword)
+}
+
+// TestTwoFactorBruteForce memastikan kode 2FA yang salah di luar login ikut
+// dihitung oleh LoginGuard, sehingga kode 6 digit tidak dapat ditebak lewat
+// sesi yang sudah login.
+func TestTwoFactorBruteForce(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	enroll := func(user testUser) string {
+		body := app.mustDo(http.StatusOK, "POST", "/profile/2fa/enroll", user.Token, map[string]interface{}{"password": testPassword})
+		secret, _ := body["secret"].(string)
+		return secret
+	}
+	code := func(secret string) string {
+		value, err := totp.Code(secret, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	backoff := func(method, path, token string, body map[string]interface{}) {
+		t.Helper()
+		rec := app.request(method, path, token, body)
+		if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s %s saat backoff: status %d, Retry-After %q", method, path, rec.Code, rec.Header().Get("Retry-After"))
+		}
+	}
+
+	// Konfirmasi enrollment.
+	aliceSecret := enroll(alice)
+	for i := 0; i < loginguard.DefaultAccountPolicy.FreeAttempts+1; i++ {
+		app.mustDo(http.StatusBadRequest, "POST", "/profile/2fa/confirm", alice.Token, map[string]interface{}{"code": "000000"})
+	}
+	backoff("POST", "/profile/2fa/confirm", alice.Token, map[string]interface{}{"code": code(aliceSecret)})
+	backoff("POST", "/profile/2fa/enroll", alice.Token, map[string]interface{}{"password": testPassword})
+
+	// Recovery code dan menonaktifkan 2FA.
+	bobSecret := enroll(bob)
+	app.mustDo(http.StatusOK, "POST", "/profile/2fa/confirm", bob.Token, map[string]interface{}{"code": code(bobSecret)})
+	for i := 0; i < loginguard.DefaultAccountPolicy.FreeAttempts; i++ {
+		app.mustDo(http.StatusBadRequest, "POST", "/profile/2fa/recovery-codes", bob.Token, map[string]interface{}{"code": "000000"})
+	}
+	app.mustDo(http.StatusUnauthorized, "DELETE", "/profile/2fa", bob.Token, map[string]interface{}{"password": testPassword, "code": "000000"})
+	backoff("POST", "/profile/2fa/recovery-codes", bob.Token, map[string]interface{}{"code": code(bobSecret)})
+	backoff("DELETE", "/profile/2fa", bob.Token, map[string]interface{}{"password": testPassword, "code": code(bobSecret)})
+}
+
+func TestSessions(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	other := app.login("alice", testPassword)
+	bob := app.register("bob")
+
+	body := app.mustDo(http.StatusOK, "GET", "/sessions", alice.Token, nil)
+	sessions, _ := body["sessions"].([]interface{})
+	if len(sessions) != 2 {
+		t.Fatalf("GET /sessions: %d sesi, ingin 2", len(sessions))
+	}
+	var otherID string
+	for _, session := range sessions {
+		if session := session.(map[string]interface{}); session["current"] != true {
+			otherID = fmt.Sprint(session["id"])
+		}
+	}
+
+	app.run(t, []routeCase{
+		{"sesi user lain", "DELETE", "/sessions/" + otherID, bob.Token, nil, http.StatusNotFound},
+		{"ID tidak valid", "DELETE", "/sessions/abc", alice.Token, nil, http.StatusBadRequest},
+		{"akhiri sesi lain", "DELETE", "/sessions/" + otherID, alice.Token, nil, http.StatusOK},
+		{"sesi yang diakhiri", "GET", "/profile", other.Token, nil, http.StatusUnauthorized},
+	})
+
+	third := app.login("alice", testPassword)
+	app.mustDo(http.StatusOK, "DELETE", "/sessions", alice.Token, nil)
+	app.mustDo(http.StatusUnauthorized, "GET", "/profile", third.Token, nil)
+	app.mustDo(http.StatusOK, "GET", "/profile", alice.Token, nil)
+
+	// Ganti password tanpa logout_other_sessions tidak mengakhiri sesi lain
+	// maupun API token; dengan logout_other_sessions keduanya berakhir.
+	fourth := app.login("alice", testPassword)
+	pat := app.apiToken(alice, string(policy.ScopeProfileRead))
+	app.mustDo(http.StatusOK, "PUT", "/profile/password", alice.Token, map[string]interface{}{"old_password": testPassword, "new_password": "Another-pass7"})
+	app.mustDo(http.StatusOK, "GET", "/profile", fourth.Token, nil)
+	app.mustDo(http.StatusOK, "GET", "/profile", pat, nil)
+	app.mustDo(http.StatusOK, "PUT", "/profile/password", alice.Token, map[string]interface{}{
+		"old_password": "Another-pass7", "new_password": "Third-pass-9", "logout_other_sessions": true,
+	})
+	app.mustDo(http.StatusUnauthorized, "GET", "/profile", fourth.Token, nil)
+	app.mustDo(http.StatusUnauthorized, "GET", "/profile", pat, nil)
+	app.mustDo(http.StatusOK, "GET", "/profile", alice.Token, nil)
+}
+
+func TestProfile(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	app.run(t, []routeCase{
+		{"lihat profile", "GET", "/profile", alice.Token, nil, http.StatusOK},
+		{"ubah nama", "PUT", "/profile", alice.Token, map[string]interface{}{"fullname": "Alice"}, http.StatusOK},
+		{"username dipakai", "PUT", "/profile", alice.Token, map[string]interface{}{"username": "bob"}, http.StatusConflict},
+		{"tanggal lahir salah", "PUT", "/profile", alice.Token, map[string]interface{}{"tanggal_lahir": "01-01-2000"}, http.StatusBadRequest},
+		{"password lama salah", "PUT", "/profile/password", alice.Token, map[string]interface{}{"old_password": "salah-sekali", "new_password": "Another-pass7"}, http.StatusUnauthorized},
+		{"ganti password", "PUT", "/profile/password", alice.Token, map[string]interface{}{"old_password": testPassword, "new_password": "Another-pass7"}, http.StatusOK},
+		{"riwayat keamanan", "GET", "/profile/security-events", alice.Token, nil, http.StatusOK},
+		{"riwayat keamanan limit salah", "GET", "/profile/security-events?limit=0", alice.Token, nil, http.StatusBadRequest},
+	})
+
+	t.Run("foto profil", func(t *testing.T) {
+		app := app.with(t)
+		photo := func(content string) formBody {
+			return formBody{files: map[string]string{"photo_profile": content}}
+		}
+		app.run(t, []routeCase{
+			{"bukan gambar", "PUT", "/profile", alice.Token, photo("foto"), http.StatusUnsupportedMediaType},
+			{"bukan gambar bernama .jpg", "PUT", "/profile", alice.Token, formBody{
+				files: map[string]string{"photo_profile": "<html><body>foto</body></html>"},
+				names: map[string]string{"photo_profile": "foto.jpg"},
+			}, http.StatusUnsupportedMediaType},
+			{"gambar rusak", "PUT", "/profile", alice.Token, photo(pngImage(300, 200)[:100]), http.StatusBadRequest},
+		})
+
+		// File dikenali dari isinya walaupun bernama .txt.
+		body := app.mustDo(http.StatusOK, "PUT", "/profile", alice.Token, photo(pngImage(300, 200)))
+		user := body["user"].(map[string]interface{})
+		variants := user["PhotoProfileVariants"].(map[string]interface{})
+		for name, size := range map[string]int{"small": 48, "medium": 128, "large": 512} {
+			path, _ := variants[name].(string)
+			if !strings.HasPrefix(path, "public/uploads/") || !strings.HasSuffix(path, ".jpg") {
+				t.Fatalf("PhotoProfileVariants[%s] = %q", name, path)
+			}
+			if format, width, height := app.imageSize(path); format != "jpeg" || width != size || height != size {
+				t.Errorf("%s: %s %dx%d, ingin jpeg %dx%d", name, format, width, height, size, size)
+			}
+		}
+		if user["PhotoProfile"] != variants["large"] {
+			t.Errorf("PhotoProfile = %v, ingin %v", user["PhotoProfile"], variants["large"])
+		}
+		if rec := app.request("HEAD", "/"+variants["large"].(string), "", nil); rec.Code != http.StatusOK {
+			t.Errorf("HEAD %s: status %d", variants["large"], rec.Code)
+		}
+		// Ringkasan user di endpoint lain ikut menampilkan semua ukuran.
+		summary := app.mustDo(http.StatusOK, "GET", "/users?q=alice", bob.Token, nil)["users"].([]interface{})[0]
+		if fmt.Sprint(summary.(map[string]interface{})["PhotoProfileVariants"]) != fmt.Sprint(variants) {
+			t.Errorf("ringkasan user = %v", summary)
+		}
+
+		// Foto baru menggantikan dan menghapus file lama.
+		app.mustDo(http.StatusOK, "PUT", "/profile", alice.Token, photo(pngImage(64, 64)))
+		for name, path := range variants {
+			if rec := app.request("GET", "/"+path.(string), "", nil); rec.Code != http.StatusNotFound {
+				t.Errorf("file lama %s masih ada: status %d", name, rec.Code)
+			}
+		}
+	})
+
+	t.Run("detail profil", func(t *testing.T) {
+		app := app.with(t)
+		body := app.mustDo(http.StatusOK, "PUT", "/profile", alice.Token, map[string]interface{}{
+			"bio":        "Halo @bob, suka #golang dan #kopi_susu. Email: alice@example.com",
+			"location":   "Bandung",
+			"pronouns":   "she/her",
+			"links":      []string{"example.com/alice", "https://blog.example.org", "HTTPS://EXAMPLE.com/alice"},
+			"visibility": map[string]string{"tanggal_lahir": "month_day", "location": "hidden"},
+		})
+		user := body["user"].(map[string]interface{})
+		if fmt.Sprint(user["Links"]) != "[https://example.com/alice https://blog.example.org]" || user["Location"] != "Bandung" {
+			t.Errorf("profil sendiri = %v", user)
+		}
+
+		public := app.mustDo(http.StatusOK, "GET", "/users/alice", bob.Token, nil)["user"].(map[string]interface{})
+		var entities []string
+		for _, entity := range public["BioEntities"].([]interface{}) {
+			entity := entity.(map[string]interface{})
+			entities = append(entities, fmt.Sprint(entity["type"], ":", entity["value"], "@", entity["start"]))
+		}
+		if got := strings.Join(entities, ","); got != "mention:bob@5,hashtag:golang@16,hashtag:kopi_susu@28" {
+			t.Errorf("BioEntities = %s", got)
+		}
+		want := map[string]interface{}{"TanggalLahir": "01-01", "Location": nil, "Pronouns": "she/her", "JenisKelamin": nil}
+		for key, value := range want {
+			if public[key] != value {
+				t.Errorf("%s = %v, ingin %v", key, public[key], value)
+			}
+		}
+
+		// Form-data: banner dan visibilitas dalam bentuk JSON.
+		body = app.mustDo(http.StatusOK, "PUT", "/profile", alice.Token, formBody{
+			fields: map[string]string{"visibility": `{"tanggal_lahir":"public","jenis_kelamin":"public"}`},
+			files:  map[string]string{"banner": pngImage(1000, 500)},
+		})
+		banners := body["user"].(map[string]interface{})["BannerVariants"].(map[string]interface{})
+		for name, size := range map[string][2]int{"medium": {600, 200}, "large": {1500, 500}} {
+			if _, width, height := app.imageSize(banners[name].(string)); width != size[0] || height != size[1] {
+				t.Errorf("banner %s: %dx%d, ingin %dx%d", name, width, height, size[0], size[1])
+			}
+		}
+		public = app.mustDo(http.StatusOK, "GET", "/users/alice", bob.Token, nil)["user"].(map[string]interface{})
+		if public["TanggalLahir"] != "2000-01-01" || public["JenisKelamin"] != "L" || public["Location"] != nil {
+			t.Errorf("profil publik setelah ubah visibilitas = %v", public)
+		}
+		// Mengosongkan field.
+		body = app.mustDo(http.StatusOK, "PUT", "/profile", alice.Token, map[string]interface{}{"bio": "", "links": []string{}})
+		if user := body["user"].(map[string]interface{}); user["Bio"] != "" || fmt.Sprint(user["Links"]) != "[]" || user["Pronouns"] != "she/her" {
+			t.Errorf("profil setelah dikosongkan = %v", user)
+		}
+
+		app.run(t, []routeCase{
+			{"bio terlalu panjang", "PUT", "/profile", alice.Token, map[string]interface{}{"bio": strings.Repeat("a", 301)}, http.StatusBadRequest},
+			{"tautan tidak valid", "PUT", "/profile", alice.Token, map[string]interface{}{"links": []string{"javascript:alert(1)"}}, http.StatusBadRequest},
+			{"tautan ftp", "PUT", "/profile", alice.Token, map[string]interface{}{"links": []string{"ftp://example.com"}}, http.StatusBadRequest},
+			{"terlalu banyak tautan", "PUT", "/profile", alice.Token, map[string]interface{}{"links": []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com"}}, http.StatusBadRequest},
+			{"visibilitas tidak valid", "PUT", "/profile", alice.Token, map[string]interface{}{"visibility": map[string]string{"location": "month_day"}}, http.StatusBadRequest},
+			{"field visibilitas tidak dikenal", "PUT", "/profile", alice.Token, map[string]interface{}{"visibility": map[string]string{"email": "public"}}, http.StatusBadRequest},
+		})
+	})
+
+	t.Run("nonaktifkan akun", func(t *testing.T) {
+		app := app.with(t)
+		app.mustDo(http.StatusOK, "DELETE", "/profile", alice.Token, nil)
+		app.mustDo(http.StatusUnauthorized, "GET", "/profile", alice.Token, nil)
+		// Login dalam masa tenggang memulihkan akun.
+		app.login("alice", "Another-pass7")
+	})
+}
+
+func TestSocial(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	aliceID, bobID := fmt.Sprint(alice.ID), fmt.Sprint(bob.ID)
+
+	app.run(t, []routeCase{
+		{"daftar user", "GET", "/users", alice.Token, nil, http.StatusOK},
+		{"follow user tidak ada", "POST", "/follow/999", alice.Token, nil, http.StatusNotFound},
+		{"follow", "POST", "/follow/" + bobID, alice.Token, nil, http.StatusOK},
+		{"follow dua kali", "POST", "/follow/" + bobID, alice.Token, nil, http.StatusBadRequest},
+		{"followers", "GET", "/followers", bob.Token, nil, http.StatusOK},
+		{"following", "GET", "/following", alice.Token, nil, http.StatusOK},
+		{"unfollow", "DELETE", "/follow/" + bobID, alice.Token, nil, http.StatusOK},
+		{"feed tanpa teks", "POST", "/feeds", alice.Token, formBody{}, http.StatusBadRequest},
+	})
+
+	feed := app.mustDo(http.StatusCreated, "POST", "/feeds", alice.Token, formBody{
+		fields: map[string]string{"feed": "halo"},
+		files:  map[string]string{"file": "lampiran"},
+	})
+	feedID := id(t, feed, "feed")
+	comment := app.mustDo(http.StatusCreated, "POST", "/feeds/"+feedID+"/comments", bob.Token, map[string]interface{}{"comment": "hai"})
+	commentID := id(t, comment, "comment")
+
+	app.run(t, []routeCase{
+		{"daftar feed", "GET", "/feeds", bob.Token, nil, http.StatusOK},
+		{"ubah feed orang lain", "PUT", "/feeds/" + feedID, bob.Token, formBody{fields: map[string]string{"feed": "bukan"}}, http.StatusForbidden},
+		{"ubah feed", "PUT", "/feeds/" + feedID, alice.Token, formBody{fields: map[string]string{"feed": "halo lagi"}}, http.StatusOK},
+		{"ubah feed ID salah", "PUT", "/feeds/abc", alice.Token, formBody{fields: map[string]string{"feed": "x"}}, http.StatusBadRequest},
+		{"komentar feed tidak ada", "POST", "/feeds/999/comments", bob.Token, map[string]interface{}{"comment": "hai"}, http.StatusNotFound},
+		{"ubah komentar orang lain", "PUT", "/comments/" + commentID, alice.Token, map[string]interface{}{"comment": "x"}, http.StatusForbidden},
+		{"ubah komentar", "PUT", "/comments/" + commentID, bob.Token, map[string]interface{}{"comment": "hai lagi"}, http.StatusOK},
+		{"like", "POST", "/feeds/" + feedID + "/like", bob.Token, nil, http.StatusOK},
+		{"dislike", "POST", "/feeds/" + feedID + "/dislike", bob.Token, nil, http.StatusOK},
+		{"like feed tidak ada", "POST", "/feeds/999/like", bob.Token, nil, http.StatusNotFound},
+		{"hapus komentar orang lain", "DELETE", "/comments/" + commentID, alice.Token, nil, http.StatusForbidden},
+		{"hapus komentar", "DELETE", "/comments/" + commentID, bob.Token, nil, http.StatusOK},
+		{"hapus feed orang lain", "DELETE", "/feeds/" + feedID, bob.Token, nil, http.StatusForbidden},
+		{"hapus feed", "DELETE", "/feeds/" + feedID, alice.Token, nil, http.StatusOK},
+	})
+
+	carol := app.register("carol")
+	chatroom := app.mustDo(http.StatusCreated, "POST", "/chatrooms", alice.Token, map[string]interface{}{"user_ids": []uint{bob.ID}})
+	chatroomID := id(t, chatroom, "chatroom")
+	message := app.mustDo(http.StatusCreated, "POST", "/chatrooms/"+chatroomID+"/messages", bob.Token, formBody{
+		fields: map[string]string{"message": "halo alice"},
+		files:  map[string]string{"file": "lampiran"},
+	})
+	messageID := id(t, message, "message")
+
+	app.run(t, []routeCase{
+		{"direct chat tanpa peserta", "POST", "/chatrooms", alice.Token, map[string]interface{}{"user_ids": []uint{}}, http.StatusBadRequest},
+		{"group tanpa nama", "POST", "/chatrooms", alice.Token, map[string]interface{}{"is_group": true, "user_ids": []uint{bob.ID}}, http.StatusBadRequest},
+		{"daftar chatroom", "GET", "/chatrooms", bob.Token, nil, http.StatusOK},
+		{"baca pesan", "GET", "/chatrooms/" + chatroomID + "/messages", alice.Token, nil, http.StatusOK},
+		{"baca pesan bukan peserta", "GET", "/chatrooms/" + chatroomID + "/messages", carol.Token, nil, http.StatusForbidden},
+		{"kirim pesan bukan peserta", "POST", "/chatrooms/" + chatroomID + "/messages", carol.Token, formBody{fields: map[string]string{"message": "x"}}, http.StatusForbidden},
+		{"kirim pesan kosong", "POST", "/chatrooms/" + chatroomID + "/messages", alice.Token, formBody{}, http.StatusBadRequest},
+		{"chatroom tidak ada", "GET", "/chatrooms/999/messages", alice.Token, nil, http.StatusNotFound},
+		{"hapus pesan orang lain", "DELETE", "/messages/" + messageID, alice.Token, nil, http.StatusForbidden},
+		{"hapus pesan", "DELETE", "/messages/" + messageID, bob.Token, nil, http.StatusOK},
+		{"hapus chatroom bukan peserta", "DELETE", "/chatrooms/" + chatroomID, carol.Token, nil, http.StatusForbidden},
+		{"hapus chatroom", "DELETE", "/chatrooms/" + chatroomID, bob.Token, nil, http.StatusOK},
+	})
+
+	// Konten user yang dinonaktifkan disembunyikan dari user lain.
+	app.mustDo(http.StatusOK, "POST", "/follow/"+aliceID, bob.Token, nil)
+	app.mustDo(http.StatusOK, "DELETE", "/profile", alice.Token, nil)
+	body := app.mustDo(http.StatusOK, "GET", "/following", bob.Token, nil)
+	if following, _ := body["following"].([]interface{}); len(following) != 0 {
+		t.Errorf("following berisi user yang dinonaktifkan: %v", following)
+	}
+}
+
+func TestModeration(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	mod := app.register("mod")
+	admin := app.register("admin")
+	app.setRole(mod, policy.RoleModerator)
+	app.setRole(admin, policy.RoleAdmin)
+
+	feedID := id(t, app.mustDo(http.StatusCreated, "POST", "/feeds", alice.Token, formBody{fields: map[string]string{"feed": "halo"}}), "feed")
+	commentID := id(t, app.mustDo(http.StatusCreated, "POST", "/feeds/"+feedID+"/comments", alice.Token, map[string]interface{}{"comment": "hai"}), "comment")
+	chatroomID := id(t, app.mustDo(http.StatusCreated, "POST", "/chatrooms", alice.Token, map[string]interface{}{"user_ids": []uint{bob.ID}}), "chatroom")
+	messageID := id(t, app.mustDo(http.StatusCreated, "POST", "/chatrooms/"+chatroomID+"/messages", alice.Token, formBody{fields: map[string]string{"message": "hai"}}), "message")
+
+	app.run(t, []routeCase{
+		{"user hapus feed", "DELETE", "/admin/feeds/" + feedID, bob.Token, nil, http.StatusForbidden},
+		{"user hapus komentar", "DELETE", "/admin/comments/" + commentID, bob.Token, nil, http.StatusForbidden},
+		{"user hapus pesan", "DELETE", "/admin/messages/" + messageID, bob.Token, nil, http.StatusForbidden},
+		{"user audit log", "GET", "/admin/security-events", bob.Token, nil, http.StatusForbidden},
+		{"moderator audit log", "GET", "/admin/security-events", mod.Token, nil, http.StatusForbidden},
+		{"moderator daftar user", "GET", "/admin/users", mod.Token, nil, http.StatusForbidden},
+		{"moderator ubah role", "PUT", "/admin/users/" + fmt.Sprint(bob.ID) + "/role", mod.Token, map[string]interface{}{"role": policy.RoleAdmin}, http.StatusForbidden},
+		{"moderator hapus komentar", "DELETE", "/admin/comments/" + commentID, mod.Token, nil, http.StatusOK},
+		{"moderator hapus pesan", "DELETE", "/admin/messages/" + messageID, mod.Token, nil, http.StatusOK},
+		{"moderator hapus feed", "DELETE", "/admin/feeds/" + feedID, mod.Token, nil, http.StatusOK},
+		{"moderator hapus feed tidak ada", "DELETE", "/admin/feeds/999", mod.Token, nil, http.StatusNotFound},
+		{"admin audit log", "GET", "/admin/security-events", admin.Token, nil, http.StatusOK},
+		{"admin daftar user", "GET", "/admin/users", admin.Token, nil, http.StatusOK},
+		{"role tidak valid", "PUT", "/admin/users/" + fmt.Sprint(bob.ID) + "/role", admin.Token, map[string]interface{}{"role": "raja"}, http.StatusBadRequest},
+		{"user tidak ada", "PUT", "/admin/users/999/role", admin.Token, map[string]interface{}{"role": policy.RoleModerator}, http.StatusNotFound},
+		{"admin terakhir", "PUT", "/admin/users/" + fmt.Sprint(admin.ID) + "/role", admin.Token, map[string]interface{}{"role": policy.RoleUser}, http.StatusConflict},
+		{"jadikan moderator", "PUT", "/admin/users/" + fmt.Sprint(bob.ID) + "/role", admin.Token, map[string]interface{}{"role": policy.RoleModerator}, http.StatusOK},
+	})
+}
+
+func TestResponsesHidePrivateFields(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	admin := app.register("admin")
+	app.setRole(admin, policy.RoleAdmin)
+
+	app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(alice.ID), bob.Token, nil)
+	app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(bob.ID), alice.Token, nil)
+	feedID := id(t, app.mustDo(http.StatusCreated, "POST", "/feeds", bob.Token, formBody{fields: map[string]string{"feed": "halo"}}), "feed")
+	app.mustDo(http.StatusCreated, "POST", "/feeds/"+feedID+"/comments", bob.Token, map[string]interface{}{"comment": "hai"})
+	app.mustDo(http.StatusOK, "POST", "/feeds/"+feedID+"/like", bob.Token, nil)
+
+	// Respons pembuatan chatroom berisi peserta lain.
+	rec := app.request("POST", "/chatrooms", alice.Token, map[string]interface{}{"is_group": true, "name": "grup", "user_ids": []uint{bob.ID}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /chatrooms: status %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), bob.Email) {
+		t.Errorf("POST /chatrooms: respons berisi email user lain: %s", rec.Body.String())
+	}
+	var created struct{ Chatroom struct{ ID uint } }
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	chatroomID := fmt.Sprint(created.Chatroom.ID)
+	app.mustDo(http.StatusCreated, "POST", "/chatrooms/"+chatroomID+"/messages", bob.Token, formBody{fields: map[string]string{"message": "halo"}})
+
+	// withBob menandai respons yang seharusnya memuat data publik bob.
+	for path, withBob := range map[string]bool{
+		"/users":                                 true,
+		"/followers":                             true,
+		"/following":                             true,
+		"/feeds":                                 true,
+		"/chatrooms":                             false,
+		"/chatrooms/" + chatroomID + "/messages": true,
+	} {
+		rec := app.request("GET", path, alice.Token, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d", path, rec.Code)
+			continue
+		}
+		if withBob && !strings.Contains(rec.Body.String(), `"bob"`) {
+			t.Errorf("GET %s: respons tidak berisi user bob: %s", path, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), bob.Email) {
+			t.Errorf("GET %s: respons berisi email user lain: %s", path, rec.Body.String())
+		}
+	}
+
+	if rec := app.request("GET", "/profile", alice.Token, nil); !strings.Contains(rec.Body.String(), alice.Email) {
+		t.Errorf("GET /profile: respons tidak berisi email sendiri: %s", rec.Body.String())
+	}
+	if rec := app.request("GET", "/admin/users", admin.Token, nil); !strings.Contains(rec.Body.String(), bob.Email) {
+		t.Errorf("GET /admin/users: respons tidak berisi email user: %s", rec.Body.String())
+	}
+}
+
+func TestUserDirectory(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	users := map[string]testUser{}
+	for _, name := range []string{"bob", "bobby", "carol", "dave", "erin", "robert"} {
+		users[name] = app.register(name)
+	}
+
+	// usernames mengambil username dari satu halaman direktori.
+	usernames := func(t *testing.T, query string) ([]string, string) {
+		t.Helper()
+		body := app.with(t).mustDo(http.StatusOK, "GET", "/users"+query, alice.Token, nil)
+		var names []string
+		for _, user := range body["users"].([]interface{}) {
+			names = append(names, user.(map[string]interface{})["Username"].(string))
+		}
+		cursor, _ := body["next_cursor"].(string)
+		return names, cursor
+	}
+	expect := func(t *testing.T, got []string, want ...string) {
+		t.Helper()
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("users = %v, ingin %v", got, want)
+		}
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		var all []string
+		cursor := ""
+		for page := 0; page < 10; page++ {
+			names, next := usernames(t, "?limit=3&cursor="+cursor)
+			all = append(all, names...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		expect(t, all, "alice", "bob", "bobby", "carol", "dave", "erin", "robert")
+	})
+
+	t.Run("urutan terbaru", func(t *testing.T) {
+		names, cursor := usernames(t, "?sort=newest&limit=2")
+		expect(t, names, "robert", "erin")
+		names, _ = usernames(t, "?sort=newest&limit=2&cursor="+cursor)
+		expect(t, names, "dave", "carol")
+	})
+
+	t.Run("pencarian", func(t *testing.T) {
+		// Awalan lebih dulu, lalu yang mengandung kata kunci, lalu fuzzy.
+		names, _ := usernames(t, "?q=BOB")
+		expect(t, names, "bob", "bobby")
+		names, _ = usernames(t, "?q=ob")
+		expect(t, names, "bob", "bobby", "robert")
+		names, _ = usernames(t, "?q=rbt")
+		expect(t, names, "robert")
+		names, _ = usernames(t, "?q=user%20car")
+		expect(t, names, "carol")
+		names, _ = usernames(t, "?q=%25")
+		expect(t, names)
+		names, cursor := usernames(t, "?q=b&limit=2")
+		expect(t, names, "bob", "bobby")
+		names, _ = usernames(t, "?q=b&limit=2&cursor="+cursor)
+		expect(t, names, "robert")
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		app := app.with(t)
+		app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(users["bob"].ID), alice.Token, nil)
+		app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(users["carol"].ID), alice.Token, nil)
+		app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(alice.ID), users["carol"].Token, nil)
+		app.mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(alice.ID), users["dave"].Token, nil)
+		names, _ := usernames(t, "?filter=following")
+		expect(t, names, "bob", "carol")
+		names, _ = usernames(t, "?filter=followers")
+		expect(t, names, "carol", "dave")
+		names, _ = usernames(t, "?filter=mutuals")
+		expect(t, names, "carol")
+	})
+
+	t.Run("blokir dan akun nonaktif", func(t *testing.T) {
+		app := app.with(t)
+		bob, erin := users["bob"], users["erin"]
+		app.run(t, []routeCase{
+			{"blokir diri sendiri", "POST", "/block/" + fmt.Sprint(alice.ID), alice.Token, nil, http.StatusBadRequest},
+			{"blokir user tidak ada", "POST", "/block/999", alice.Token, nil, http.StatusNotFound},
+			{"blokir", "POST", "/block/" + fmt.Sprint(bob.ID), alice.Token, nil, http.StatusOK},
+			{"blokir dua kali", "POST", "/block/" + fmt.Sprint(bob.ID), alice.Token, nil, http.StatusBadRequest},
+			{"follow user yang memblokir", "POST", "/follow/" + fmt.Sprint(alice.ID), bob.Token, nil, http.StatusForbidden},
+			{"diblokir erin", "POST", "/block/" + fmt.Sprint(alice.ID), erin.Token, nil, http.StatusOK},
+			{"nonaktifkan dave", "DELETE", "/profile", users["dave"].Token, nil, http.StatusOK},
+		})
+		names, _ := usernames(t, "")
+		expect(t, names, "alice", "bobby", "carol", "robert")
+		// Blokir berlaku dua arah.
+		body := app.mustDo(http.StatusOK, "GET", "/users?q=alice", bob.Token, nil)
+		if list := body["users"].([]interface{}); len(list) != 0 {
+			t.Errorf("user yang diblokir masih melihat alice: %v", list)
+		}
+		// Blokir menghapus follow.
+		names, _ = usernames(t, "?filter=following")
+		expect(t, names, "carol")
+
+		app.mustDo(http.StatusOK, "DELETE", "/block/"+fmt.Sprint(bob.ID), alice.Token, nil)
+		app.mustDo(http.StatusNotFound, "DELETE", "/block/999", alice.Token, nil)
+		names, _ = usernames(t, "?q=bob")
+		expect(t, names, "bob", "bobby")
+	})
+
+	app.run(t, []routeCase{
+		{"filter tidak valid", "GET", "/users?filter=semua", alice.Token, nil, http.StatusBadRequest},
+		{"urutan tidak valid", "GET", "/users?sort=acak", alice.Token, nil, http.StatusBadRequest},
+		{"relevance tanpa kata kunci", "GET", "/users?sort=relevance", alice.Token, nil, http.StatusBadRequest},
+		{"limit tidak valid", "GET", "/users?limit=0", alice.Token, nil, http.StatusBadRequest},
+		{"cursor tidak valid", "GET", "/users?cursor=bukan-cursor", alice.Token, nil, http.StatusBadRequest},
+		{"limit di atas batas", "GET", "/users?limit=1000", alice.Token, nil, http.StatusOK},
+	})
+
+	t.Run("cursor urutan lain", func(t *testing.T) {
+		_, cursor := usernames(t, "?limit=1")
+		app.with(t).mustDo(http.StatusBadRequest, "GET", "/users?sort=newest&cursor="+cursor, alice.Token, nil)
+	})
+}
+
+func TestPublicProfile(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	carol := app.register("carol")
+	dave := app.register("dave")
+	follow := func(t *testing.T, user testUser, target testUser) {
+		t.Helper()
+		app.with(t).mustDo(http.StatusOK, "POST", "/follow/"+fmt.Sprint(target.ID), user.Token, nil)
+	}
+	follow(t, carol, bob)
+	follow(t, dave, bob)
+	follow(t, bob, carol)
+	var feedIDs []string
+	for _, text := range []string{"satu", "dua", "tiga"} {
+		body := app.mustDo(http.StatusCreated, "POST", "/feeds", bob.Token, formBody{fields: map[string]string{"feed": text}})
+		feedIDs = append(feedIDs, id(t, body, "feed"))
+	}
+
+	// list mengambil semua halaman dari endpoint berpagination dan
+	// mengembalikan ID (feed) atau Username (user) dari setiap item.
+	list := func(t *testing.T, path, key, field string, user testUser) []string {
+		t.Helper()
+		app := app.with(t)
+		var values []string
+		cursor := ""
+		for page := 0; page < 10; page++ {
+			body := app.mustDo(http.StatusOK, "GET", path+"?limit=2&cursor="+cursor, user.Token, nil)
+			for _, item := range body[key].([]interface{}) {
+				values = append(values, fmt.Sprint(item.(map[string]interface{})[field]))
+			}
+			next, _ := body["next_cursor"].(string)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		return values
+	}
+	expect := func(t *testing.T, got []string, want ...string) {
+		t.Helper()
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("hasil = %v, ingin %v", got, want)
+		}
+	}
+	profile := func(t *testing.T, username string, user testUser) map[string]interface{} {
+		t.Helper()
+		return app.with(t).mustDo(http.StatusOK, "GET", "/users/"+username, user.Token, nil)
+	}
+
+	t.Run("profil", func(t *testing.T) {
+		body := profile(t, "BOB", alice)
+		user := body["user"].(map[string]interface{})
+		if user["Username"] != "bob" || user["IsPrivate"] != false {
+			t.Errorf("user = %v", user)
+		}
+		if _, ok := user["Email"]; ok {
+			t.Errorf("email user lain ikut terkirim: %v", user)
+		}
+		want := map[string]interface{}{
+			"followers_count": 2.0, "following_count": 1.0, "feeds_count": 3.0,
+			"is_following": false, "follows_you": false, "can_view_content": true,
+		}
+		for key, value := range want {
+			if body[key] != value {
+				t.Errorf("%s = %v, ingin %v", key, body[key], value)
+			}
+		}
+		follow(t, alice, bob)
+		if body := profile(t, "bob", alice); body["is_following"] != true || body["followers_count"] != 3.0 {
+			t.Errorf("setelah follow: %v", body)
+		}
+		if body := profile(t, "carol", bob); body["is_following"] != true || body["follows_you"] != true {
+			t.Errorf("saling follow: %v", body)
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		expect(t, list(t, "/users/bob/feeds", "feeds", "ID", alice), feedIDs[2], feedIDs[1], feedIDs[0])
+		// Followers diurutkan dari follow terbaru.
+		expect(t, list(t, "/users/bob/followers", "followers", "Username", alice), "alice", "dave", "carol")
+		expect(t, list(t, "/users/bob/following", "following", "Username", alice), "carol")
+		expect(t, list(t, "/users/alice/feeds", "feeds", "ID", bob))
+	})
+
+	t.Run("akun privat", func(t *testing.T) {
+		app := app.with(t)
+		body := app.mustDo(http.StatusOK, "PUT", "/profile", bob.Token, map[string]interface{}{"is_private": true})
+		if body["user"].(map[string]interface{})["IsPrivate"] != true {
+			t.Fatalf("PUT /profile: %v", body)
+		}
+		// alice mengikuti bob, tetapi tidak diikuti balik.
+		if body := profile(t, "bob", alice); body["can_view_content"] != false || body["feeds_count"] != 3.0 {
+			t.Errorf("profil privat: %v", body)
+		}
+		app.run(t, []routeCase{
+			{"feed akun privat", "GET", "/users/bob/feeds", alice.Token, nil, http.StatusForbidden},
+			{"followers akun privat", "GET", "/users/bob/followers", alice.Token, nil, http.StatusForbidden},
+			{"following akun privat", "GET", "/users/bob/following", alice.Token, nil, http.StatusForbidden},
+			{"feed akun privat oleh pemiliknya", "GET", "/users/bob/feeds", bob.Token, nil, http.StatusOK},
+		})
+		// carol diikuti bob sehingga boleh melihat kontennya.
+		expect(t, list(t, "/users/bob/feeds", "feeds", "ID", carol), feedIDs[2], feedIDs[1], feedIDs[0])
+		timeline := func(user testUser) []string {
+			var ids []string
+			for _, feed := range app.mustDo(http.StatusOK, "GET", "/feeds", user.Token, nil)["feeds"].([]interface{}) {
+				ids = append(ids, fmt.Sprint(feed.(map[string]interface{})["ID"]))
+			}
+			return ids
+		}
+		expect(t, timeline(alice))
+		expect(t, timeline(carol), feedIDs[2], feedIDs[1], feedIDs[0])
+
+		app.mustDo(http.StatusOK, "PUT", "/profile", bob.Token, map[string]interface{}{"is_private": false})
+		expect(t, timeline(alice), feedIDs[2], feedIDs[1], feedIDs[0])
+	})
+
+	t.Run("blokir dan akun nonaktif", func(t *testing.T) {
+		app := app.with(t)
+		daveFeed := id(t, app.mustDo(http.StatusCreated, "POST", "/feeds", dave.Token, formBody{fields: map[string]string{"feed": "dari dave"}}), "feed")
+		app.mustDo(http.StatusOK, "POST", "/block/"+fmt.Sprint(alice.ID), dave.Token, nil)
+		app.mustDo(http.StatusOK, "DELETE", "/profile", carol.Token, nil)
+		inTimeline := func(user testUser, feedID string) bool {
+			for _, feed := range app.mustDo(http.StatusOK, "GET", "/feeds", user.Token, nil)["feeds"].([]interface{}) {
+				if fmt.Sprint(feed.(map[string]interface{})["ID"]) == feedID {
+					return true
+				}
+			}
+			return false
+		}
+		if inTimeline(alice, daveFeed) || !inTimeline(bob, daveFeed) {
+			t.Errorf("feed dave di timeline: alice = %v, bob = %v", inTimeline(alice, daveFeed), inTimeline(bob, daveFeed))
+		}
+		// Comment dan reaksi dave pada feed bob disembunyikan dari alice, baik
+		// di timeline maupun di halaman feed bob.
+		app.mustDo(http.StatusCreated, "POST", "/feeds/"+feedIDs[0]+"/comments", dave.Token, map[string]interface{}{"comment": "dari dave"})
+		app.mustDo(http.StatusOK, "POST", "/feeds/"+feedIDs[0]+"/like", dave.Token, nil)
+		// activity mengembalikan UserID pengirim comment dan reaksi pada feed
+		// bob yang terlihat oleh user.
+		activity := func(user testUser, path string) (commenters, reactors []string) {
+			for _, item := range app.mustDo(http.StatusOK, "GET", path, user.Token, nil)["feeds"].([]interface{}) {
+				feed := item.(map[string]interface{})
+				if fmt.Sprint(feed["ID"]) != feedIDs[0] {
+					continue
+				}
+				for _, comment := range feed["Comments"].([]interface{}) {
+					commenters = append(commenters, fmt.Sprint(comment.(map[string]interface{})["UserID"]))
+				}
+				for _, reaction := range feed["Reactions"].([]interface{}) {
+					reactors = append(reactors, fmt.Sprint(reaction.(map[string]interface{})["UserID"]))
+				}
+			}
+			return commenters, reactors
+		}
+		for _, path := range []string{"/feeds", "/users/bob/feeds"} {
+			commenters, reactors := activity(alice, path)
+			expect(t, append(commenters, reactors...))
+			commenters, reactors = activity(bob, path)
+			expect(t, append(commenters, reactors...), fmt.Sprint(dave.ID), fmt.Sprint(dave.ID))
+		}
+		app.run(t, []routeCase{
+			{"profil user yang memblokir", "GET", "/users/dave", alice.Token, nil, http.StatusNotFound},
+			{"profil user yang diblokir", "GET", "/users/alice", dave.Token, nil, http.StatusNotFound},
+			{"feed user yang memblokir", "GET", "/users/dave/feeds", alice.Token, nil, http.StatusNotFound},
+			{"comment feed user yang memblokir", "POST", "/feeds/" + daveFeed + "/comments", alice.Token, map[string]interface{}{"comment": "hai"}, http.StatusNotFound},
+			{"like feed user yang memblokir", "POST", "/feeds/" + daveFeed + "/like", alice.Token, nil, http.StatusNotFound},
+			{"profil akun nonaktif", "GET", "/users/carol", alice.Token, nil, http.StatusNotFound},
+			{"user tidak ada", "GET", "/users/zoe", alice.Token, nil, http.StatusNotFound},
+		})
+		expect(t, list(t, "/users/bob/followers", "followers", "Username", alice), "alice")
+		expect(t, list(t, "/users/bob/followers", "followers", "Username", bob), "alice", "dave")
+		if body := profile(t, "bob", alice); body["followers_count"] != 2.0 || body["following_count"] != 0.0 {
+			t.Errorf("jumlah setelah carol nonaktif: %v", body)
+		}
+	})
+
+	app.run(t, []routeCase{
+		{"limit tidak valid", "GET", "/users/bob/feeds?limit=0", alice.Token, nil, http.StatusBadRequest},
+		{"cursor tidak valid", "GET", "/users/bob/followers?cursor=bukan-cursor", alice.Token, nil, http.StatusBadRequest},
+		{"cursor feed tidak valid", "GET", "/users/bob/feeds?cursor=bukan-cursor", alice.Token, nil, http.StatusBadRequest},
+	})
+}
+
+func TestAccountPurge(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	app.mustDo(http.StatusUnauthorized, "POST", "/login", "", map[string]interface{}{"login": "alice", "password": "salah-sekali"})
+	app.mustDo(http.StatusCreated, "POST", "/feeds", alice.Token, formBody{fields: map[string]string{"feed": "halo"}})
+	app.mustDo(http.StatusOK, "DELETE", "/profile", alice.Token, nil)
+
+	auditEvents := func(user testUser) []models.AuditEvent {
+		var events []models.AuditEvent
+		if err := config.DB.Where("user_id = ?", user.ID).Order("id").Find(&events).Error; err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+	personal := func(events []models.AuditEvent) int {
+		n := 0
+		for _, event := range events {
+			if event.IPAddress != "" || event.UserAgent != "" || event.Detail != "" {
+				n++
+			}
+		}
+		return n
+	}
+	before := auditEvents(alice)
+	if personal(before) == 0 {
+		t.Fatalf("audit event alice tidak berisi data pribadi: %+v", before)
+	}
+	bobEvents := personal(auditEvents(bob))
+
+	// Masih dalam masa tenggang: tidak ada yang dihapus.
+	if purged, err := controllers.PurgeDeactivatedAccounts(time.Now()); err != nil || purged != 0 {
+		t.Fatalf("purged = %d, err = %v", purged, err)
+	}
+	purged, err := controllers.PurgeDeactivatedAccounts(time.Now().Add(controllers.AccountDeletionGracePeriod + time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("purged = %d, err = %v", purged, err)
+	}
+
+	var count int64
+	config.DB.Unscoped().Model(&models.User{}).Where("id = ?", alice.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("user alice masih ada setelah purge")
+	}
+	config.DB.Unscoped().Model(&models.Feed{}).Where("user_id = ?", alice.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("feed alice masih ada setelah purge: %d", count)
+	}
+	after := auditEvents(alice)
+	if len(after) <= len(before) {
+		t.Errorf("audit event alice = %d, sebelum purge %d", len(after), len(before))
+	}
+	if n := personal(after); n != 0 {
+		t.Errorf("%d audit event alice masih berisi IP, user agent, atau detail: %+v", n, after)
+	}
+	if n := personal(auditEvents(bob)); n != bobEvents || n == 0 {
+		t.Errorf("audit event bob ikut dibersihkan: %d, sebelumnya %d", n, bobEvents)
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	providers := map[string]*oidctest.Server{}
+	for _, name := range []string{"alpha", "beta"} {
+		srv := oidctest.NewServer(t)
+		providers[name] = srv
+		controllers.OIDCProviders[name] = &oidc.Provider{
+			Name:         name,
+			ClientID:     srv.ClientID,
+			ClientSecret: srv.ClientSecret,
+			RedirectURL:  "http://localhost/auth/" + name + "/callback",
+			Scopes:       []string{"openid", "email", "profile"},
+			Issuer:       srv.Issuer(),
+		}
+	}
+	t.Cleanup(func() { controllers.OIDCProviders = map[string]*oidc.Provider{} })
+
+	// callbackURL menjalankan /auth/<provider>/login lalu login di provider,
+	// dan mengembalikan URL callback berisi code dan state.
+	callbackURL := func(t *testing.T, provider string, claims jwt.MapClaims) string {
+		t.Helper()
+		rec := app.with(t).request("GET", "/auth/"+provider+"/login", "", nil)
+		if rec.Code != http.StatusFound {
+			t.Fatalf("GET /auth/%s/login: status %d: %s", provider, rec.Code, rec.Body.String())
+		}
+		redirect, err := providers[provider].Authorize(rec.Header().Get("Location"), claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(redirect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.RequestURI()
+	}
+	login := func(t *testing.T, want int, provider string, claims jwt.MapClaims) map[string]interface{} {
+		t.Helper()
+		return app.with(t).mustDo(want, "GET", callbackURL(t, provider, claims), "", nil)
+	}
+	identityOwner := func(t *testing.T, provider, subject string) uint {
+		t.Helper()
+		var identity models.Identity
+		if err := config.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+			t.Fatalf("identitas %s/%s: %v", provider, subject, err)
+		}
+		return identity.UserID
+	}
+	verified := func(sub, email string) jwt.MapClaims {
+		return jwt.MapClaims{"sub": sub, "email": email, "email_verified": true, "preferred_username": "Carol.Oidc!"}
+	}
+
+	t.Run("login pertama membuat akun", func(t *testing.T) {
+		body := login(t, http.StatusOK, "alpha", verified("a-1", "Carol@Example.com"))
+		if token, _ := body["token"].(string); token == "" {
+			t.Fatalf("callback tidak mengembalikan token: %v", body)
+		}
+		var user models.User
+		if err := config.DB.First(&user, identityOwner(t, "alpha", "a-1")).Error; err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "carol.oidc" || user.Email != "carol@example.com" || user.EmailVerifiedAt == nil || user.Password != "" {
+			t.Errorf("user baru = %+v", user)
+		}
+		// Login berikutnya memakai akun yang sama.
+		login(t, http.StatusOK, "alpha", verified("a-1", "carol-baru@example.com"))
+		var count int64
+		config.DB.Model(&models.User{}).Count(&count)
+		if count != 2 {
+			t.Errorf("jumlah user = %d, ingin 2", count)
+		}
+	})
+
+	t.Run("tautkan akun tanpa password", func(t *testing.T) {
+		login(t, http.StatusOK, "beta", verified("b-1", "carol@example.com"))
+		if got, want := identityOwner(t, "beta", "b-1"), identityOwner(t, "alpha", "a-1"); got != want {
+			t.Errorf("identitas beta ditautkan ke user %d, ingin %d", got, want)
+		}
+	})
+
+	t.Run("email bentrok", func(t *testing.T) {
+		dave := models.User{Fullname: "Dave", Username: "dave", Email: "dave@example.com", PhotoProfile: "public/default/images/user.png"}
+		if err := config.DB.Create(&dave).Error; err != nil {
+			t.Fatal(err)
+		}
+		unverified := verified("b-4", "carol@example.com")
+		unverified["email_verified"] = false
+		for name, claims := range map[string]jwt.MapClaims{
+			"akun dengan password":          verified("b-2", alice.Email),
+			"email lokal belum verified":    verified("b-3", dave.Email),
+			"email provider belum verified": unverified,
+		} {
+			login(t, http.StatusConflict, "beta", claims)
+			var count int64
+			config.DB.Model(&models.Identity{}).Where("provider = ? AND subject = ?", "beta", claims["sub"]).Count(&count)
+			if count != 0 {
+				t.Errorf("%s: identitas tetap ditautkan", name)
+			}
+		}
+		login(t, http.StatusBadRequest, "beta", jwt.MapClaims{"sub": "b-5"})
+	})
+
+	t.Run("ID token tidak valid", func(t *testing.T) {
+		for name, claims := range map[string]jwt.MapClaims{
+			"nonce": {"sub": "a-2", "nonce": "nonce-lain"},
+			"aud":   {"sub": "a-2", "aud": "client-lain"},
+			"iss":   {"sub": "a-2", "iss": "https://issuer-lain.example"},
+			"exp":   {"sub": "a-2", "exp": time.Now().Add(-time.Minute).Unix()},
+		} {
+			t.Run(name, func(t *testing.T) {
+				login(t, http.StatusUnauthorized, "alpha", claims)
+			})
+		}
+	})
+
+	t.Run("state", func(t *testing.T) {
+		callback := callbackURL(t, "alpha", verified("a-1", "carol@example.com"))
+		app.mustDo(http.StatusOK, "GET", callback, "", nil)
+		app.run(t, []routeCase{
+			{"state dipakai ulang", "GET", callback, "", nil, http.StatusBadRequest},
+			{"state provider lain", "GET", strings.Replace(callbackURL(t, "alpha", nil), "/alpha/", "/beta/", 1), "", nil, http.StatusBadRequest},
+			{"tanpa code", "GET", "/auth/alpha/callback?state=x", "", nil, http.StatusBadRequest},
+			{"dibatalkan provider", "GET", "/auth/alpha/callback?error=access_denied", "", nil, http.StatusBadRequest},
+		})
+	})
+}
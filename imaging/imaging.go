@@ -0,0 +1,119 @@
+// Package imaging memproses gambar yang di-upload user untuk foto profil dan
+// banner: jenis file diperiksa dari isinya (bukan dari nama atau header
+// request), gambar di-decode, diputar sesuai orientasi EXIF, dipotong di
+// bagian tengah sesuai rasio, diperkecil ke beberapa ukuran, lalu di-encode
+// ulang sebagai JPEG. Encode ulang membuang seluruh metadata, termasuk EXIF
+// yang dapat berisi lokasi GPS.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // decoder GIF (hanya frame pertama)
+	"image/jpeg"
+	_ "image/png" // decoder PNG
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decoder WebP
+)
+
+// Size adalah satu ukuran hasil pemrosesan.
+type Size struct {
+	Name          string
+	Width, Height int
+}
+
+// AvatarSizes dan BannerSizes adalah ukuran foto profil (persegi) dan banner
+// (3:1), dari yang terkecil.
+var (
+	AvatarSizes = []Size{{"small", 48, 48}, {"medium", 128, 128}, {"large", 512, 512}}
+	BannerSizes = []Size{{"medium", 600, 200}, {"large", 1500, 500}}
+)
+
+// Types adalah jenis MIME yang diterima.
+var Types = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// MaxPixels adalah jumlah piksel maksimum gambar sumber. Dimensi diperiksa
+// sebelum decode agar gambar kecil dengan dimensi raksasa tidak menghabiskan
+// memori.
+var MaxPixels = 40_000_000
+
+// Quality adalah kualitas JPEG hasil encode.
+const Quality = 85
+
+var (
+	ErrUnsupported = errors.New("jenis file tidak didukung")
+	ErrInvalid     = errors.New("gambar tidak dapat dibaca")
+	ErrTooLarge    = errors.New("dimensi gambar terlalu besar")
+)
+
+// Process memproses data menjadi satu JPEG untuk setiap ukuran di sizes,
+// dengan nama ukuran sebagai key.
+func Process(data []byte, sizes []Size) (map[string][]byte, error) {
+	if !sniff(data) {
+		return nil, ErrUnsupported
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalid
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	orientation := exifOrientation(data)
+
+	result := make(map[string][]byte, len(sizes))
+	for _, size := range sizes {
+		// Orientasi 5-8 menukar lebar dan tinggi, sehingga pemotongan dan
+		// perubahan ukuran dilakukan pada sumber dengan ukuran tertukar,
+		// lalu hasil kecilnya diputar. Potongan tengah tidak berubah oleh
+		// rotasi maupun pencerminan.
+		width, height := size.Width, size.Height
+		if orientation >= 5 {
+			width, height = height, width
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		// JPEG tidak mendukung transparansi; area transparan menjadi putih.
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, centerCrop(src.Bounds(), width, height), draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(dst, orientation), &jpeg.Options{Quality: Quality}); err != nil {
+			return nil, err
+		}
+		result[size.Name] = buf.Bytes()
+	}
+	return result, nil
+}
+
+// sniff memeriksa jenis file dari isinya.
+func sniff(data []byte) bool {
+	detected := mimetype.Detect(data)
+	for _, t := range Types {
+		if detected.Is(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// centerCrop mengembalikan bagian tengah terbesar dari bounds dengan rasio
+// width:height.
+func centerCrop(bounds image.Rectangle, width, height int) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*height > h*width {
+		w = max(1, h*width/height)
+	} else {
+		h = max(1, w*height/width)
+	}
+	x := bounds.Min.X + (bounds.Dx()-w)/2
+	y := bounds.Min.Y + (bounds.Dy()-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
@@ -0,0 +1,292 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+)
+
+// halves membuat gambar width x height dengan setengah atas top dan setengah
+// bawah bottom.
+func halves(width, height int, top, bottom color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := top
+			if y >= height/2 {
+				c = bottom
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// exifSegment membuat segmen APP1 Exif dengan IFD0 berisi Orientation dan
+// pointer ke GPS IFD (GPSLatitudeRef "S", GPSLongitudeRef "E").
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	put16 := func(v uint16) { binary.Write(&tiff, order, v) }
+	put32 := func(v uint32) { binary.Write(&tiff, order, v) }
+	entry := func(tag, typ uint16, count, value uint32, short bool) {
+		put16(tag)
+		put16(typ)
+		put32(count)
+		if short {
+			put16(uint16(value))
+			put16(0)
+		} else {
+			put32(value)
+		}
+	}
+	put16(42)
+	put32(8)
+	// IFD0: 2 entry, mulai di offset 8, panjang 2 + 2*12 + 4 = 30.
+	put16(2)
+	entry(0x0112, 3, 1, uint32(orientation), true) // Orientation
+	entry(0x8825, 4, 1, 38, false)                 // GPS IFD
+	put32(0)
+	// GPS IFD di offset 38.
+	put16(2)
+	entry(0x0001, 2, 2, 'S', true) // GPSLatitudeRef
+	entry(0x0003, 2, 2, 'E', true) // GPSLongitudeRef
+	put32(0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegment menyisipkan segment tepat setelah SOI file JPEG.
+func withSegment(data, segment []byte) []byte {
+	result := append([]byte{}, data[:2]...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+// hasSegment melaporkan apakah JPEG berisi segmen dengan marker tersebut
+// sebelum data gambar.
+func hasSegment(data []byte, marker byte) bool {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		if data[i+1] == marker {
+			return true
+		}
+		if data[i+1] == 0xDA {
+			return false
+		}
+		i += 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+	}
+	return false
+}
+
+func near(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	diff := func(a uint32, b uint8) bool { return int(a>>8)-int(b) < 40 && int(b)-int(a>>8) < 40 }
+	return diff(r, want.R) && diff(g, want.G) && diff(b, want.B)
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("hasil bukan JPEG: %s, %v", format, err)
+	}
+	return img
+}
+
+func TestProcessSizes(t *testing.T) {
+	// 300x100: atas merah, bawah biru. Potongan tengah persegi tetap memuat
+	// kedua warna.
+	data := encodePNG(t, halves(300, 100, red, blue))
+	result, err := Process(data, AvatarSizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range AvatarSizes {
+		img := decode(t, result[size.Name])
+		if img.Bounds().Dx() != size.Width || img.Bounds().Dy() != size.Height {
+			t.Errorf("%s: %v", size.Name, img.Bounds())
+		}
+		if top, bottom := img.At(size.Width/2, 2), img.At(size.Width/2, size.Height-3); !near(top, red) || !near(bottom, blue) {
+			t.Errorf("%s: atas %v, bawah %v", size.Name, top, bottom)
+		}
+	}
+}
+
+func TestProcessTransparent(t *testing.T) {
+	result, err := Process(encodePNG(t, image.NewRGBA(image.Rect(0, 0, 10, 10))), []Size{{"x", 4, 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := decode(t, result["x"]).At(2, 2); !near(c, color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("area transparan = %v, ingin putih", c)
+	}
+}
+
+func TestProcessOrientationAndStripping(t *testing.T) {
+	// Disimpan 100x300 dengan atas merah dan bawah biru. Orientation 6
+	// berarti gambar harus diputar 90° searah jarum jam untuk ditampilkan,
+	// sehingga tampilannya 300x100 dengan kiri biru dan kanan merah.
+	stored := encodeJPEG(t, halves(100, 300, red, blue))
+	for name, order := range map[string]binary.ByteOrder{"big endian": binary.BigEndian, "little endian": binary.LittleEndian} {
+		t.Run(name, func(t *testing.T) {
+			data := withSegment(stored, exifSegment(order, 6))
+			if got := exifOrientation(data); got != 6 {
+				t.Fatalf("exifOrientation = %d, ingin 6", got)
+			}
+			result, err := Process(data, BannerSizes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, size := range BannerSizes {
+				out := result[size.Name]
+				img := decode(t, out)
+				if img.Bounds().Dx() != size.Width || img.Bounds().Dy() != size.Height {
+					t.Fatalf("%s: %v", size.Name, img.Bounds())
+				}
+				y := size.Height / 2
+				if left, right := img.At(2, y), img.At(size.Width-3, y); !near(left, blue) || !near(right, red) {
+					t.Errorf("%s: kiri %v, kanan %v", size.Name, left, right)
+				}
+				if hasSegment(out, 0xE1) || bytes.Contains(out, []byte("Exif")) {
+					t.Errorf("%s: hasil masih berisi EXIF", size.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := encodeJPEG(t, halves(8, 8, red, blue))
+	segment := exifSegment(binary.BigEndian, 3)
+	for name, tt := range map[string]struct {
+		data []byte
+		want int
+	}{
+		"tanpa exif":           {plain, 1},
+		"orientation 3":        {withSegment(plain, segment), 3},
+		"orientation 8 (II)":   {withSegment(plain, exifSegment(binary.LittleEndian, 8)), 8},
+		"nilai di luar 1-8":    {withSegment(plain, exifSegment(binary.BigEndian, 9)), 1},
+		"png":                  {encodePNG(t, halves(8, 8, red, blue)), 1},
+		"terpotong":            {withSegment(plain, segment)[:20], 1},
+		"panjang segmen rusak": {withSegment(plain, []byte{0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x'}), 1},
+		"bukan exif":           {withSegment(plain, []byte{0xFF, 0xE1, 0, 8, 'X', 'M', 'P', 0, 0, 0}), 1},
+		"kosong":               {nil, 1},
+	} {
+		if got := exifOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: exifOrientation = %d, ingin %d", name, got, tt.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Gambar tersimpan 3x2: piksel kiri atas merah, kanan atas hijau.
+	// Tabel mengikuti arti nilai Orientation di spesifikasi Exif: posisi
+	// tampilan piksel tersebut setelah orient.
+	stored := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	stored.Set(0, 0, red)
+	stored.Set(2, 0, green)
+	type corner int
+	const (
+		tl corner = iota
+		tr
+		bl
+		br
+	)
+	tests := []struct {
+		orientation   int
+		red, green    corner
+		width, height int
+	}{
+		{1, tl, tr, 3, 2},
+		{2, tr, tl, 3, 2},
+		{3, br, bl, 3, 2},
+		{4, bl, br, 3, 2},
+		{5, tl, bl, 2, 3},
+		{6, tr, br, 2, 3},
+		{7, br, tr, 2, 3},
+		{8, bl, tl, 2, 3},
+	}
+	for _, tt := range tests {
+		img := orient(stored, tt.orientation)
+		w, h := img.Bounds().Dx(), img.Bounds().Dy()
+		if w != tt.width || h != tt.height {
+			t.Errorf("orientation %d: %dx%d, ingin %dx%d", tt.orientation, w, h, tt.width, tt.height)
+			continue
+		}
+		at := func(c corner) color.Color {
+			x, y := 0, 0
+			if c == tr || c == br {
+				x = w - 1
+			}
+			if c == bl || c == br {
+				y = h - 1
+			}
+			return img.At(x, y)
+		}
+		if at(tt.red) != color.Color(red) || at(tt.green) != color.Color(green) {
+			t.Errorf("orientation %d: merah dan hijau tidak di posisi yang benar", tt.orientation)
+		}
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	valid := encodePNG(t, halves(20, 20, red, blue))
+	for name, tt := range map[string]struct {
+		data []byte
+		want error
+	}{
+		"teks":          {[]byte("bukan gambar, hanya teks"), ErrUnsupported},
+		"html":          {[]byte("<html><body>foto.jpg</body></html>"), ErrUnsupported},
+		"pdf":           {[]byte("%PDF-1.4\n%âãÏÓ\n1 0 obj\n"), ErrUnsupported},
+		"kosong":        {nil, ErrUnsupported},
+		"jpeg palsu":    {[]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 0, 0, 1, 0, 1, 0, 0}, ErrInvalid},
+		"png terpotong": {valid[:len(valid)/2], ErrInvalid},
+	} {
+		if _, err := Process(tt.data, AvatarSizes); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, ingin %v", name, err, tt.want)
+		}
+	}
+
+	previous := MaxPixels
+	MaxPixels = 399
+	defer func() { MaxPixels = previous }()
+	if _, err := Process(valid, AvatarSizes); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("gambar besar: err = %v, ingin %v", err, ErrTooLarge)
+	}
+}
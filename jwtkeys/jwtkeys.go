@@ -0,0 +1,296 @@
+// Package jwtkeys mengelola kunci penandatangan dan kunci verifikasi JWT.
+// Satu kunci aktif dipakai untuk menandatangani token baru, sedangkan token
+// diverifikasi dengan kunci mana pun yang masih terdaftar berdasarkan header
+// "kid", sehingga kunci dapat dirotasi tanpa downtime. Kunci publik
+// (RS256/EdDSA) dapat dipublikasikan sebagai JWKS.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Key adalah satu kunci JWT. Untuk HS256 SignKey dan VerifyKey berisi secret
+// yang sama; untuk kunci asimetris SignKey bisa nil jika kunci hanya dipakai
+// untuk verifikasi.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+// KeySet berisi satu kunci penandatangan aktif dan semua kunci verifikasi.
+type KeySet struct {
+	signing      *Key
+	verification map[string]*Key
+}
+
+// NewKeySet membuat KeySet dengan signing sebagai kunci aktif. Kunci aktif
+// otomatis ikut menjadi kunci verifikasi.
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || signing.SignKey == nil {
+		return nil, errors.New("kunci penandatangan wajib diisi")
+	}
+	set := &KeySet{signing: signing, verification: map[string]*Key{}}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, exists := set.verification[key.ID]; exists && key != signing {
+			return nil, fmt.Errorf("kid %q terdaftar lebih dari sekali", key.ID)
+		}
+		set.verification[key.ID] = key
+	}
+	return set, nil
+}
+
+// Sign menandatangani klaim dengan kunci aktif dan menyertakan header kid.
+func (s *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.SignKey)
+}
+
+// Parse memverifikasi token dengan kunci sesuai kid. Algoritma token harus
+// sama dengan algoritma kunci untuk mencegah serangan algorithm confusion.
+func (s *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.verification[kid]
+		if !ok {
+			return nil, fmt.Errorf("kid tidak dikenal: %q", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("signing method tidak valid: %v", token.Header["alg"])
+		}
+		return key.VerifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("klaim token tidak valid")
+	}
+	return claims, nil
+}
+
+// JWKS mengembalikan JSON Web Key Set berisi kunci publik verifikasi. Kunci
+// HMAC tidak pernah dipublikasikan.
+func (s *KeySet) JWKS() map[string]interface{} {
+	keys := []map[string]string{}
+	for _, key := range s.verification {
+		jwk := publicJWK(key.VerifyKey)
+		if jwk == nil {
+			continue
+		}
+		jwk["kid"] = key.ID
+		jwk["alg"] = key.Method.Alg()
+		jwk["use"] = "sig"
+		keys = append(keys, jwk)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i]["kid"] < keys[j]["kid"] })
+	return map[string]interface{}{"keys": keys}
+}
+
+func publicJWK(key interface{}) map[string]string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(k),
+		}
+	}
+	return nil
+}
+
+// Thumbprint menghitung JWK thumbprint (RFC 7638) kunci publik, dipakai
+// sebagai kid default.
+func Thumbprint(publicKey interface{}) string {
+	var canonical string
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk := publicJWK(k)
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk["e"], jwk["n"])
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, base64.RawURLEncoding.EncodeToString(k))
+	default:
+		return ""
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewHMACKey membuat kunci HS256 dari secret.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{ID: id, Method: jwt.SigningMethodHS256, SignKey: secret, VerifyKey: secret}
+}
+
+// ParsePEM membaca kunci privat (PKCS#1/PKCS#8) atau kunci publik (PKIX)
+// RSA maupun Ed25519. Jika id kosong, kid diisi thumbprint kunci publik.
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("data PEM tidak valid")
+	}
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipe PEM tidak didukung: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.SignKey, key.VerifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.VerifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.SignKey, key.VerifyKey = SigningMethodEd25519, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.VerifyKey = SigningMethodEd25519, k
+	default:
+		return nil, fmt.Errorf("tipe kunci %T tidak didukung", parsed)
+	}
+	if key.ID == "" {
+		key.ID = Thumbprint(key.VerifyKey)
+	}
+	return key, nil
+}
+
+// Ephemeral membuat KeySet dengan kunci Ed25519 acak. Token yang
+// ditandatangani tidak berlaku lagi setelah proses restart, sehingga hanya
+// cocok untuk development dan pengujian.
+func Ephemeral() *KeySet {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	publicKey := privateKey.Public()
+	key := &Key{ID: Thumbprint(publicKey), Method: SigningMethodEd25519, SignKey: privateKey, VerifyKey: publicKey}
+	set, _ := NewKeySet(key)
+	return set
+}
+
+// Options adalah konfigurasi kunci JWT:
+//
+//	SigningKeyFile       file PEM kunci privat aktif (RS256 atau EdDSA)
+//	SigningKeyID         kid kunci aktif (default: thumbprint RFC 7638)
+//	Secret               secret HS256, dipakai jika tidak ada file kunci
+//	SecretID             kid untuk Secret (default "hs256")
+//	VerificationKeyFiles file PEM kunci lama, format "[kid=]path"
+//	PreviousSecrets      secret HS256 lama, format "kid=secret"
+type Options struct {
+	SigningKeyFile       string
+	SigningKeyID         string
+	Secret               string
+	SecretID             string
+	VerificationKeyFiles []string
+	PreviousSecrets      []string
+}
+
+// Configured melaporkan apakah kunci penandatangan tetap sudah diatur.
+func (o Options) Configured() bool {
+	return o.SigningKeyFile != "" || o.Secret != ""
+}
+
+// Load membuat KeySet dari Options. Jika tidak ada kunci yang
+// dikonfigurasi, dipakai kunci Ephemeral.
+func Load(opts Options) (*KeySet, error) {
+	var signing *Key
+	if opts.SigningKeyFile != "" {
+		data, err := os.ReadFile(opts.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signing, err = ParsePEM(opts.SigningKeyID, data)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err)
+		}
+		if signing.SignKey == nil {
+			return nil, errors.New("JWT_SIGNING_KEY_FILE harus berisi kunci privat")
+		}
+	} else if opts.Secret != "" {
+		id := opts.SecretID
+		if id == "" {
+			id = "hs256"
+		}
+		signing = NewHMACKey(id, []byte(opts.Secret))
+	}
+
+	var verification []*Key
+	for _, entry := range trimList(opts.VerificationKeyFiles) {
+		id, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			id, path = entry[:i], entry[i+1:]
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePEM(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFICATION_KEY_FILES %s: %w", path, err)
+		}
+		key.SignKey = nil
+		verification = append(verification, key)
+	}
+	for _, entry := range trimList(opts.PreviousSecrets) {
+		i := strings.Index(entry, "=")
+		if i <= 0 {
+			return nil, errors.New("JWT_PREVIOUS_SECRETS harus berformat kid=secret")
+		}
+		verification = append(verification, NewHMACKey(entry[:i], []byte(entry[i+1:])))
+	}
+
+	if signing == nil {
+		log.Println("PERINGATAN: JWT_SIGNING_KEY_FILE/JWT_SECRET tidak diisi, memakai kunci sementara. Semua token tidak berlaku setelah restart.")
+		set := Ephemeral()
+		for _, key := range verification {
+			set.verification[key.ID] = key
+		}
+		return set, nil
+	}
+	return NewKeySet(signing, verification...)
+}
+
+func trimList(values []string) []string {
+	var items []string
+	for _, item := range values {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,243 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func rsaKey(t *testing.T, id string) *Key {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodRS256, SignKey: privateKey, VerifyKey: &privateKey.PublicKey}
+}
+
+func edKey(t *testing.T, id string) *Key {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Key{ID: id, Method: SigningMethodEd25519, SignKey: privateKey, VerifyKey: publicKey}
+}
+
+// verifyOnly mengembalikan salinan key tanpa kunci privat.
+func verifyOnly(key *Key) *Key {
+	copied := *key
+	copied.SignKey = nil
+	return &copied
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "1", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestSignParse(t *testing.T) {
+	for _, key := range []*Key{NewHMACKey("hs", []byte("rahasia")), rsaKey(t, "rsa"), edKey(t, "ed")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			set, err := NewKeySet(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := set.Sign(claims())
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+			if err != nil || parsed.Header["kid"] != key.ID || parsed.Header["alg"] != key.Method.Alg() {
+				t.Fatalf("header = %v, %v", parsed.Header, err)
+			}
+			got, err := set.Parse(token)
+			if err != nil || got["sub"] != "1" {
+				t.Fatalf("Parse = %v, %v", got, err)
+			}
+
+			// Tanda tangan yang diubah dan token kedaluwarsa ditolak.
+			if _, err := set.Parse(token[:len(token)-2] + "AA"); err == nil {
+				t.Error("tanda tangan yang diubah diterima")
+			}
+			expired, _ := set.Sign(jwt.MapClaims{"sub": "1", "exp": time.Now().Add(-time.Minute).Unix()})
+			if _, err := set.Parse(expired); err == nil {
+				t.Error("token kedaluwarsa diterima")
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newKey := rsaKey(t, "lama"), edKey(t, "baru")
+	oldSet, _ := NewKeySet(oldKey)
+	oldToken, _ := oldSet.Sign(claims())
+
+	// Setelah rotasi, token lama tetap berlaku dan token baru memakai kid
+	// kunci aktif.
+	rotated, err := NewKeySet(newKey, verifyOnly(oldKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotated.Parse(oldToken); err != nil {
+		t.Fatalf("token kunci lama ditolak: %v", err)
+	}
+	newToken, _ := rotated.Sign(claims())
+	parsed, _, _ := new(jwt.Parser).ParseUnverified(newToken, jwt.MapClaims{})
+	if parsed.Header["kid"] != "baru" {
+		t.Fatalf("kid = %v, ingin baru", parsed.Header["kid"])
+	}
+	if _, err := oldSet.Parse(newToken); err == nil {
+		t.Error("token dengan kid yang tidak dikenal diterima")
+	}
+
+	// Token dengan kid milik kunci lain tidak dapat diverifikasi.
+	forged := jwt.NewWithClaims(SigningMethodEd25519, claims())
+	forged.Header["kid"] = "lama"
+	if token, err := forged.SignedString(newKey.SignKey); err != nil {
+		t.Fatal(err)
+	} else if _, err := rotated.Parse(token); err == nil {
+		t.Error("token EdDSA dengan kid RSA diterima")
+	}
+
+	if _, err := NewKeySet(newKey, verifyOnly(oldKey), verifyOnly(oldKey)); err == nil {
+		t.Error("kid ganda diterima")
+	}
+	if _, err := NewKeySet(verifyOnly(newKey)); err == nil {
+		t.Error("kunci aktif tanpa kunci privat diterima")
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	key := rsaKey(t, "rsa")
+	set, _ := NewKeySet(key)
+	publicDER, err := x509.MarshalPKIXPublicKey(key.VerifyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	// Penyerang menandatangani HS256 dengan kunci publik RSA sebagai secret.
+	for name, secret := range map[string][]byte{"pem": publicPEM, "der": publicDER} {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		token.Header["kid"] = "rsa"
+		signed, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := set.Parse(signed); err == nil {
+			t.Errorf("HS256 dengan kunci publik (%s) diterima", name)
+		}
+	}
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, claims())
+	none.Header["kid"] = "rsa"
+	signed, _ := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := set.Parse(signed); err == nil {
+		t.Error("alg none diterima")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaSigning, ed := rsaKey(t, "rsa"), edKey(t, "ed")
+	set, err := NewKeySet(rsaSigning, verifyOnly(ed), NewHMACKey("hs", []byte("rahasia")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := set.JWKS()["keys"].([]map[string]string)
+	if len(keys) != 2 || keys[0]["kid"] != "ed" || keys[1]["kid"] != "rsa" {
+		t.Fatalf("JWKS = %v", keys)
+	}
+	if k := keys[0]; k["kty"] != "OKP" || k["crv"] != "Ed25519" || k["alg"] != "EdDSA" || k["use"] != "sig" ||
+		k["x"] != base64.RawURLEncoding.EncodeToString(ed.VerifyKey.(ed25519.PublicKey)) {
+		t.Errorf("JWK Ed25519 = %v", k)
+	}
+	publicKey := rsaSigning.VerifyKey.(*rsa.PublicKey)
+	n, _ := base64.RawURLEncoding.DecodeString(keys[1]["n"])
+	e, _ := base64.RawURLEncoding.DecodeString(keys[1]["e"])
+	if k := keys[1]; k["kty"] != "RSA" || k["alg"] != "RS256" ||
+		new(big.Int).SetBytes(n).Cmp(publicKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(publicKey.E) {
+		t.Errorf("JWK RSA = %v", k)
+	}
+	for _, k := range keys {
+		if k["d"] != "" || k["k"] != "" {
+			t.Errorf("JWKS berisi kunci rahasia: %v", k)
+		}
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 8037 Appendix A.3.
+	x, _ := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	if got, want := Thumbprint(ed25519.PublicKey(x)), "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; got != want {
+		t.Errorf("Thumbprint = %s, ingin %s", got, want)
+	}
+	if Thumbprint([]byte("rahasia")) != "" {
+		t.Error("thumbprint untuk kunci HMAC")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ed := edKey(t, "")
+	edDER, _ := x509.MarshalPKCS8PrivateKey(ed.SignKey)
+	edFile := write("ed.pem", &pem.Block{Type: "PRIVATE KEY", Bytes: edDER})
+	old := rsaKey(t, "")
+	oldFile := write("old.pem", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(old.SignKey.(*rsa.PrivateKey))})
+
+	oldHS, _ := NewKeySet(NewHMACKey("hs-lama", []byte("secret-lama")))
+	hsToken, _ := oldHS.Sign(claims())
+	oldRSA, _ := NewKeySet(&Key{ID: "rsa-lama", Method: jwt.SigningMethodRS256, SignKey: old.SignKey, VerifyKey: old.VerifyKey})
+	rsaToken, _ := oldRSA.Sign(claims())
+
+	set, err := Load(Options{
+		SigningKeyFile:       edFile,
+		VerificationKeyFiles: []string{" rsa-lama=" + oldFile, ""},
+		PreviousSecrets:      []string{"hs-lama=secret-lama"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.signing.ID != Thumbprint(ed.VerifyKey) {
+		t.Errorf("kid default = %s, ingin thumbprint", set.signing.ID)
+	}
+	for name, token := range map[string]string{"hs256 lama": hsToken, "rsa lama": rsaToken} {
+		if _, err := set.Parse(token); err != nil {
+			t.Errorf("token %s ditolak: %v", name, err)
+		}
+	}
+	// Kunci verifikasi dari file tidak pernah dipakai untuk menandatangani.
+	if set.verification["rsa-lama"].SignKey != nil {
+		t.Error("kunci verifikasi masih menyimpan kunci privat")
+	}
+
+	for name, opts := range map[string]Options{
+		"file tidak ada":     {SigningKeyFile: filepath.Join(dir, "tidak-ada.pem")},
+		"bukan PEM":          {SigningKeyFile: write("rusak.pem", &pem.Block{Type: "CERTIFICATE", Bytes: []byte("x")})},
+		"hanya kunci publik": {SigningKeyFile: write("pub.pem", &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(old.VerifyKey.(*rsa.PublicKey))})},
+		"previous tanpa kid": {Secret: "x", PreviousSecrets: []string{"secret"}},
+	} {
+		if _, err := Load(opts); err == nil {
+			t.Errorf("%s: Load tidak mengembalikan error", name)
+		}
+	}
+	if set, err := Load(Options{Secret: "rahasia"}); err != nil || set.signing.ID != "hs256" || set.signing.Method != jwt.SigningMethodHS256 {
+		t.Errorf("Load secret = %+v, %v", set, err)
+	}
+}
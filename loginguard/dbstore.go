@@ -0,0 +1,93 @@
+package loginguard
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// DBStore menyimpan penghitung di tabel login_attempts sehingga dapat dipakai
+// bersama oleh beberapa instance aplikasi.
+type DBStore struct {
+	DB *gorm.DB
+}
+
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{DB: db}
+}
+
+func toEntry(attempt models.LoginAttempt) Entry {
+	entry := Entry{Failures: attempt.Failures, LastFailureAt: attempt.LastFailureAt}
+	if attempt.LockedUntil != nil {
+		entry.LockedUntil = *attempt.LockedUntil
+	}
+	return entry
+}
+
+func (s *DBStore) Get(key string) (Entry, error) {
+	var attempt models.LoginAttempt
+	err := s.DB.Where("attempt_key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Entry{}, nil
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	return toEntry(attempt), nil
+}
+
+// RecordFailure memakai UPDATE atomik agar kegagalan dari beberapa instance
+// yang terjadi bersamaan tidak saling menimpa.
+func (s *DBStore) RecordFailure(key string, now time.Time, window time.Duration) (Entry, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		result := s.DB.Model(&models.LoginAttempt{}).
+			Where("attempt_key = ? AND last_failure_at >= ?", key, now.Add(-window)).
+			Updates(map[string]interface{}{
+				"failures":        gorm.Expr("failures + 1"),
+				"last_failure_at": now,
+			})
+		if result.Error != nil {
+			return Entry{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Belum ada atau sudah kedaluwarsa: mulai ulang dari satu.
+			result = s.DB.Model(&models.LoginAttempt{}).
+				Where("attempt_key = ?", key).
+				Updates(map[string]interface{}{
+					"failures":        1,
+					"last_failure_at": now,
+					"locked_until":    nil,
+				})
+			if result.Error != nil {
+				return Entry{}, result.Error
+			}
+		}
+		if result.RowsAffected == 0 {
+			err := s.DB.Create(&models.LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}).Error
+			if err != nil {
+				// Kemungkinan instance lain baru saja membuat baris yang sama.
+				continue
+			}
+		}
+		return s.Get(key)
+	}
+	return Entry{}, errors.New("gagal mencatat kegagalan login")
+}
+
+// Lock membuat baris baru jika kunci belum pernah gagal, sama seperti
+// MemoryStore.
+func (s *DBStore) Lock(key string, until time.Time) error {
+	result := s.DB.Model(&models.LoginAttempt{}).
+		Where("attempt_key = ?", key).
+		Update("locked_until", until)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return s.DB.Create(&models.LoginAttempt{Key: key, LockedUntil: &until}).Error
+}
+
+func (s *DBStore) Reset(key string) error {
+	return s.DB.Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,237 @@
+// Package loginguard melindungi endpoint login dari brute-force. Kegagalan
+// login dihitung per kunci (misalnya per akun dan per IP); setelah beberapa
+// kegagalan, percobaan berikutnya harus menunggu jeda yang berlipat dua
+// (exponential backoff), dan setelah batas tertentu kunci dikunci sementara.
+// Penghitung disimpan lewat interface Store sehingga bisa berada di memori
+// atau di penyimpanan bersama ketika aplikasi berjalan di beberapa instance.
+package loginguard
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry adalah status kegagalan untuk satu kunci.
+type Entry struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store menyimpan penghitung kegagalan.
+type Store interface {
+	// Get mengembalikan status kunci; kunci yang belum pernah gagal
+	// menghasilkan Entry kosong.
+	Get(key string) (Entry, error)
+	// RecordFailure menambah jumlah kegagalan. Jika kegagalan terakhir lebih
+	// lama dari window, penghitung dimulai ulang dari satu.
+	RecordFailure(key string, now time.Time, window time.Duration) (Entry, error)
+	// Lock mengunci kunci sampai waktu until.
+	Lock(key string, until time.Time) error
+	// Reset menghapus seluruh status kunci.
+	Reset(key string) error
+}
+
+// Policy mengatur seberapa ketat sebuah jenis kunci dibatasi.
+type Policy struct {
+	// FreeAttempts adalah jumlah kegagalan yang belum dikenai jeda.
+	FreeAttempts int
+	// BaseDelay adalah jeda setelah kegagalan pertama melewati FreeAttempts;
+	// jeda berlipat dua di setiap kegagalan berikutnya hingga MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold adalah jumlah kegagalan yang memicu penguncian
+	// selama LockoutDuration.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window adalah lama waktu tanpa kegagalan sebelum penghitung dilupakan.
+	Window time.Duration
+}
+
+// retryAfter mengembalikan berapa lama lagi kunci boleh mencoba.
+func (p Policy) retryAfter(entry Entry, now time.Time) (time.Duration, bool) {
+	if now.Before(entry.LockedUntil) {
+		return entry.LockedUntil.Sub(now), true
+	}
+	if entry.Failures <= p.FreeAttempts || now.Sub(entry.LastFailureAt) > p.Window {
+		return 0, false
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < entry.Failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if wait := entry.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+		return wait, false
+	}
+	return 0, false
+}
+
+// Lockout menandakan sebuah kunci baru saja dikunci sampai Until.
+type Lockout struct {
+	Key   string
+	Until time.Time
+}
+
+// Guard menggabungkan pembatasan per akun dan per IP.
+type Guard struct {
+	Store   Store
+	Account Policy
+	IP      Policy
+}
+
+// DefaultAccountPolicy: tiga kegagalan bebas, lalu jeda 1 detik berlipat dua
+// hingga 5 menit, dan dikunci 15 menit setelah 10 kegagalan.
+var DefaultAccountPolicy = Policy{
+	FreeAttempts:     3,
+	BaseDelay:        time.Second,
+	MaxDelay:         5 * time.Minute,
+	LockoutThreshold: 10,
+	LockoutDuration:  15 * time.Minute,
+	Window:           24 * time.Hour,
+}
+
+// DefaultIPPolicy lebih longgar karena banyak user bisa berbagi satu IP (NAT).
+var DefaultIPPolicy = Policy{
+	FreeAttempts:     20,
+	BaseDelay:        time.Second,
+	MaxDelay:         time.Minute,
+	LockoutThreshold: 100,
+	LockoutDuration:  time.Hour,
+	Window:           time.Hour,
+}
+
+// New membuat Guard dengan kebijakan default.
+func New(store Store) *Guard {
+	return &Guard{Store: store, Account: DefaultAccountPolicy, IP: DefaultIPPolicy}
+}
+
+// Check mengembalikan lama waktu tunggu sebelum percobaan login boleh
+// dilakukan untuk akun dan IP tersebut (0 berarti boleh), serta apakah salah
+// satunya sedang dikunci.
+func (g *Guard) Check(accountKey, ipKey string, now time.Time) (time.Duration, bool, error) {
+	var wait time.Duration
+	var locked bool
+	for _, item := range []struct {
+		key    string
+		policy Policy
+	}{{accountKey, g.Account}, {ipKey, g.IP}} {
+		if item.key == "" {
+			continue
+		}
+		entry, err := g.Store.Get(item.key)
+		if err != nil {
+			return 0, false, err
+		}
+		w, l := item.policy.retryAfter(entry, now)
+		if w > wait {
+			wait = w
+		}
+		locked = locked || l
+	}
+	return wait, locked, nil
+}
+
+// Fail mencatat kegagalan login dan mengunci kunci yang melewati batas.
+// Kunci yang baru saja dikunci dikembalikan agar pemanggil dapat
+// menerbitkan security event.
+func (g *Guard) Fail(accountKey, ipKey string, now time.Time) ([]Lockout, error) {
+	var lockouts []Lockout
+	for _, item := range []struct {
+		key    string
+		policy Policy
+	}{{accountKey, g.Account}, {ipKey, g.IP}} {
+		if item.key == "" {
+			continue
+		}
+		entry, err := g.Store.RecordFailure(item.key, now, item.policy.Window)
+		if err != nil {
+			return lockouts, err
+		}
+		if item.policy.LockoutThreshold > 0 && entry.Failures >= item.policy.LockoutThreshold && !now.Before(entry.LockedUntil) {
+			until := now.Add(item.policy.LockoutDuration)
+			if err := g.Store.Lock(item.key, until); err != nil {
+				return lockouts, err
+			}
+			lockouts = append(lockouts, Lockout{Key: item.key, Until: until})
+		}
+	}
+	return lockouts, nil
+}
+
+// Succeed menghapus penghitung akun setelah login berhasil. Penghitung IP
+// sengaja tidak dihapus agar penyerang tidak bisa mereset batas IP dengan
+// login ke akunnya sendiri.
+func (g *Guard) Succeed(accountKey string) error {
+	return g.Store.Reset(accountKey)
+}
+
+// Unlock membuka kunci akun, misalnya setelah password berhasil direset.
+func (g *Guard) Unlock(accountKey string) error {
+	return g.Store.Reset(accountKey)
+}
+
+// MemoryStore menyimpan penghitung di memori proses.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*Entry{}}
+}
+
+func (s *MemoryStore) Get(key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok {
+		return *entry, nil
+	}
+	return Entry{}, nil
+}
+
+func (s *MemoryStore) RecordFailure(key string, now time.Time, window time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now, window)
+	entry, ok := s.entries[key]
+	if !ok || now.Sub(entry.LastFailureAt) > window {
+		entry = &Entry{}
+		s.entries[key] = entry
+	}
+	entry.Failures++
+	entry.LastFailureAt = now
+	return *entry, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok {
+		entry.LockedUntil = until
+	} else {
+		s.entries[key] = &Entry{LockedUntil: until}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// evict membuang entry lama agar memori tidak tumbuh tanpa batas.
+func (s *MemoryStore) evict(now time.Time, window time.Duration) {
+	if len(s.entries) < 10000 {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.Sub(entry.LastFailureAt) > window && now.After(entry.LockedUntil) {
+			delete(s.entries, key)
+		}
+	}
+}
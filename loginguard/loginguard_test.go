@@ -0,0 +1,178 @@
+package loginguard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"social-media-backend/models"
+)
+
+// stores mengembalikan semua implementasi Store agar setiap test memastikan
+// perilaku keduanya sama.
+func stores(t *testing.T) map[string]func() Store {
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemoryStore() },
+		"db": func() Store {
+			db, err := gorm.Open(sqlite.Open(t.TempDir()+"/guard.db"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.AutoMigrate(&models.LoginAttempt{}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+			})
+			return NewDBStore(db)
+		},
+	}
+}
+
+// t0 dibulatkan ke detik karena tidak semua database menyimpan nanodetik.
+var t0 = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+func TestStore(t *testing.T) {
+	for name, newStore := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+			window := time.Hour
+			if entry, err := store.Get("a"); err != nil || entry.Failures != 0 || !entry.LockedUntil.IsZero() {
+				t.Fatalf("Get kunci baru = %+v, %v", entry, err)
+			}
+			for i := 1; i <= 3; i++ {
+				entry, err := store.RecordFailure("a", t0.Add(time.Duration(i)*time.Minute), window)
+				if err != nil || entry.Failures != i {
+					t.Fatalf("RecordFailure ke-%d = %+v, %v", i, entry, err)
+				}
+			}
+			if entry, _ := store.Get("a"); entry.Failures != 3 || !entry.LastFailureAt.Equal(t0.Add(3*time.Minute)) {
+				t.Fatalf("Get = %+v", entry)
+			}
+			if entry, _ := store.Get("b"); entry.Failures != 0 {
+				t.Fatalf("kunci lain ikut terhitung: %+v", entry)
+			}
+
+			until := t0.Add(time.Hour)
+			if err := store.Lock("a", until); err != nil {
+				t.Fatal(err)
+			}
+			if entry, _ := store.Get("a"); !entry.LockedUntil.Equal(until) || entry.Failures != 3 {
+				t.Fatalf("setelah Lock = %+v", entry)
+			}
+			// Kegagalan setelah window lewat memulai ulang penghitung dan
+			// menghapus kunci lama.
+			later := t0.Add(3*time.Minute + window + time.Second)
+			if entry, err := store.RecordFailure("a", later, window); err != nil || entry.Failures != 1 {
+				t.Fatalf("RecordFailure setelah window = %+v, %v", entry, err)
+			}
+			if err := store.Reset("a"); err != nil {
+				t.Fatal(err)
+			}
+			if entry, _ := store.Get("a"); entry.Failures != 0 {
+				t.Fatalf("setelah Reset = %+v", entry)
+			}
+
+			// Lock juga berlaku untuk kunci yang belum pernah gagal.
+			if err := store.Lock("c", until); err != nil {
+				t.Fatal(err)
+			}
+			if entry, _ := store.Get("c"); !entry.LockedUntil.Equal(until) {
+				t.Fatalf("Lock kunci baru = %+v", entry)
+			}
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	policy := Policy{
+		FreeAttempts:     2,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 6,
+		LockoutDuration:  10 * time.Minute,
+		Window:           time.Hour,
+	}
+	// Setiap langkah gagal satu kali pada waktu at lalu memeriksa Check pada
+	// waktu yang sama.
+	steps := []struct {
+		at     time.Duration
+		wait   time.Duration
+		locked bool
+	}{
+		{0, 0, false},
+		{time.Second, 0, false},
+		{2 * time.Second, time.Second, false},
+		{4 * time.Second, 2 * time.Second, false},
+		{8 * time.Second, 4 * time.Second, false}, // dibatasi MaxDelay
+		{16 * time.Second, 10 * time.Minute, true},
+	}
+	for name, newStore := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			guard := &Guard{Store: newStore(), Account: policy, IP: DefaultIPPolicy}
+			for i, step := range steps {
+				now := t0.Add(step.at)
+				lockouts, err := guard.Fail("user:1", "ip:a", now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if wantLock := step.locked; (len(lockouts) == 1) != wantLock {
+					t.Fatalf("kegagalan ke-%d: lockouts = %+v", i+1, lockouts)
+				}
+				wait, locked, err := guard.Check("user:1", "ip:a", now)
+				if err != nil || wait != step.wait || locked != step.locked {
+					t.Fatalf("kegagalan ke-%d: Check = %v, %v, %v; ingin %v, %v", i+1, wait, locked, err, step.wait, step.locked)
+				}
+			}
+			lockedAt := t0.Add(steps[len(steps)-1].at)
+
+			// Jeda berkurang seiring waktu; akun lain dan IP lain tidak terdampak.
+			if wait, _, _ := guard.Check("user:1", "ip:a", lockedAt.Add(time.Minute)); wait != 9*time.Minute {
+				t.Errorf("wait satu menit kemudian = %v", wait)
+			}
+			if wait, _, _ := guard.Check("user:2", "ip:b", lockedAt); wait != 0 {
+				t.Errorf("akun lain ikut dikunci: wait = %v", wait)
+			}
+			// Setelah kunci berakhir, jeda backoff dari kegagalan terakhir juga
+			// sudah lewat.
+			if wait, locked, _ := guard.Check("user:1", "ip:a", lockedAt.Add(10*time.Minute)); wait != 0 || locked {
+				t.Errorf("setelah kunci berakhir: wait = %v, locked = %v", wait, locked)
+			}
+			// Kegagalan berikutnya langsung dikenai jeda maksimum, dan selama
+			// masih terkunci kunci tidak diperpanjang.
+			if lockouts, _ := guard.Fail("user:1", "ip:a", lockedAt.Add(time.Minute)); len(lockouts) != 0 {
+				t.Errorf("kunci diperpanjang: %+v", lockouts)
+			}
+
+			// Login berhasil hanya menghapus penghitung akun.
+			if err := guard.Succeed("user:1"); err != nil {
+				t.Fatal(err)
+			}
+			if wait, locked, _ := guard.Check("user:1", "", lockedAt.Add(time.Minute)); wait != 0 || locked {
+				t.Errorf("setelah Succeed: wait = %v, locked = %v", wait, locked)
+			}
+			if entry, _ := guard.Store.Get("ip:a"); entry.Failures != len(steps)+1 {
+				t.Errorf("penghitung IP = %+v", entry)
+			}
+
+			// Penghitung dilupakan setelah Window tanpa kegagalan.
+			expired := lockedAt.Add(time.Minute + policy.Window + time.Second)
+			guard.Fail("user:3", "", lockedAt)
+			guard.Fail("user:3", "", lockedAt)
+			guard.Fail("user:3", "", lockedAt)
+			if wait, _, _ := guard.Check("user:3", "", lockedAt); wait != time.Second {
+				t.Errorf("user:3 wait = %v", wait)
+			}
+			if wait, _, _ := guard.Check("user:3", "", expired); wait != 0 {
+				t.Errorf("user:3 setelah window: wait = %v", wait)
+			}
+			if entry, _ := guard.Store.RecordFailure("user:3", expired, policy.Window); entry.Failures != 1 {
+				t.Errorf("user:3 setelah window: %+v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package mailer menyediakan abstraksi pengiriman email. Implementasi SMTP
+// dipakai di production, sedangkan LogMailer menyimpan email di memori dan
+// menuliskannya ke log sehingga cocok untuk development dan pengujian.
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message adalah email teks sederhana.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirimkan email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer mengirim email melalui server SMTP.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send mengirim msg melalui server SMTP. Autentikasi PLAIN hanya dipakai jika
+// Username diisi.
+func (m *SMTPMailer) Send(msg Message) error {
+	addr := m.Host + ":" + strconv.Itoa(m.Port)
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(addr, auth, m.From, []string{msg.To}, buildMessage(m.From, msg))
+}
+
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", sanitizeHeader(from))
+	fmt.Fprintf(&b, "To: %s\r\n", sanitizeHeader(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", sanitizeHeader(msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitizeHeader membuang karakter baris baru untuk mencegah header injection.
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// LogMailer tidak benar-benar mengirim email; setiap pesan ditulis ke log dan
+// disimpan di memori agar bisa diperiksa oleh pengujian.
+type LogMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	log.Printf("[mailer] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// Messages mengembalikan salinan semua pesan yang sudah "dikirim".
+func (m *LogMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// LastTo mengembalikan pesan terakhir yang dikirim ke alamat to.
+func (m *LogMailer) LastTo(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// New memilih implementasi berdasarkan konfigurasi SMTP. Jika Host diisi
+// dipakai SMTPMailer (port default 587), selain itu LogMailer.
+func New(smtpConfig SMTPMailer) Mailer {
+	if smtpConfig.Host == "" {
+		return NewLogMailer()
+	}
+	if smtpConfig.Port == 0 {
+		smtpConfig.Port = 587
+	}
+	return &smtpConfig
+}
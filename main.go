@@ -1,75 +1,61 @@
 package main
 
 import (
+    "log"
     "os"
     "time"
 
-    "github.com/gin-contrib/cors"
     "github.com/gin-gonic/gin"
     "social-media-backend/config"
     "social-media-backend/controllers"
+    "social-media-backend/migrations"
 )
 
 func main() {
-    config.ConnectDatabase()
-
-    r := gin.Default()
-
-    // Konfigurasi CORS
-    r.Use(cors.New(cors.Config{
-        AllowOrigins:     []string{"https://feedsapp.vercel.app"}, // Sesuaikan dengan URL frontend kamu
-        AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
-        AllowCredentials: true,
-        MaxAge:           12 * time.Hour,
-    }))
-
-    // Endpoint autentikasi.
-    r.Static("/public", "./public")
-    r.POST("/register", controllers.Register)
-    r.POST("/login", controllers.Login)
-
-    // Group endpoint yang dilindungi oleh autentikasi.
-    authorized := r.Group("/")
-    authorized.Use(controllers.AuthMiddleware())
-    {
-        // Endpoint profile user.
-        authorized.GET("/profile", controllers.GetProfile)
-        authorized.PUT("/profile", controllers.UpdateProfile)
-        authorized.PUT("/profile/password", controllers.ChangePassword)
-        authorized.DELETE("/profile", controllers.DeactivateAccount)
-
-        // Endpoint follow.
-        authorized.POST("/follow/:id", controllers.FollowUser)
-        authorized.DELETE("/follow/:id", controllers.UnfollowUser)
-        authorized.GET("/followers", controllers.GetFollowers)
-        authorized.GET("/following", controllers.GetFollowing)
+    // Subcommand: migrate up|down|status|create.
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        os.Exit(runMigrate(os.Args[2:]))
+    }
 
-        // Endpoint feeds & comments.
-		authorized.GET("/feeds", controllers.GetFeeds)
-        authorized.POST("/feeds", controllers.CreateFeed)
-        authorized.PUT("/feeds/:feed_id", controllers.UpdateFeed)
-        authorized.DELETE("/feeds/:feed_id", controllers.DeleteFeed)
-        authorized.POST("/feeds/:feed_id/comments", controllers.CreateComment)
-        authorized.PUT("/comments/:id", controllers.UpdateComment)
-        authorized.DELETE("/comments/:id", controllers.DeleteComment)
-        authorized.POST("/feeds/:feed_id/like", controllers.LikeFeed)
-        authorized.POST("/feeds/:feed_id/dislike", controllers.DislikeFeed)
+    // Konfigurasi dibaca dari file CONFIG_FILE (opsional) dan environment
+    // variable, lalu divalidasi sebelum apa pun dijalankan.
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatal("Konfigurasi tidak valid:\n", err)
+    }
+    if cfg.Env != config.EnvDevelopment {
+        gin.SetMode(gin.ReleaseMode)
+    }
+    log.Printf("Menjalankan aplikasi dengan env %s", cfg.Env)
+
+    config.ConnectDatabase(cfg.Database)
+
+    // Skema dikelola oleh package migrations. Tanpa DB_MIGRATE_ON_START,
+    // migrasi dijalankan terpisah (migrate up) sebelum deploy.
+    if cfg.Database.MigrateOnStart {
+        applied, err := migrations.Up(config.DB)
+        if err != nil {
+            log.Fatal("Migrasi database gagal:", err)
+        }
+        for _, m := range applied {
+            log.Printf("Migrasi dijalankan: %s_%s", m.Version, m.Name)
+        }
+    } else if pending, err := migrations.Pending(config.DB); err != nil {
+        log.Fatal("Gagal memeriksa migrasi database:", err)
+    } else if len(pending) > 0 {
+        log.Fatalf("%d migrasi belum dijalankan; jalankan \"%s migrate up\" atau set DB_MIGRATE_ON_START=true", len(pending), os.Args[0])
+    }
 
-        // Endpoint chat.
-		authorized.GET("/users", controllers.GetAllUsers)
-        authorized.POST("/chatrooms", controllers.CreateChatroom)
-        authorized.GET("/chatrooms", controllers.GetChatrooms)
-        authorized.GET("/chatrooms/:id/messages", controllers.GetChatroomMessages)
-        authorized.POST("/chatrooms/:id/messages", controllers.SendMessage)
-        authorized.DELETE("/chatrooms/:id", controllers.DeleteChatroom)
-        authorized.DELETE("/messages/:id", controllers.DeleteMessage)
+    if err := configureControllers(cfg); err != nil {
+        log.Fatal(err)
     }
+    controllers.StartAccountPurger(time.Hour)
 
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "8080"
+    // Admin awal, misalnya ADMIN_EMAILS=admin@example.com.
+    if len(cfg.Security.AdminEmails) > 0 {
+        controllers.BootstrapAdmins(cfg.Security.AdminEmails)
     }
-    r.Run(":" + port)
+
+    r := newRouter(cfg)
+    r.Run(":" + cfg.Server.Port)
 };
\ No newline at end of file
@@ -0,0 +1,140 @@
+package main
+
+import (
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "social-media-backend/config"
+    "social-media-backend/migrations"
+)
+
+const migrateUsage = `Penggunaan: %s migrate <perintah>
+
+Perintah:
+  up             jalankan semua migrasi yang belum dijalankan
+  down [n]       batalkan n migrasi terakhir (default 1)
+  status         tampilkan status semua migrasi
+  create <nama>  buat file migrasi baru di direktori migrations
+`
+
+var migrationNameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// runMigrate menjalankan subcommand migrate dan mengembalikan exit code.
+func runMigrate(args []string) int {
+    if len(args) == 0 {
+        fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+        return 2
+    }
+    if args[0] == "create" {
+        if len(args) != 2 {
+            fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+            return 2
+        }
+        path, err := createMigration("migrations", args[1], time.Now())
+        if err != nil {
+            log.Println("Gagal membuat migrasi:", err)
+            return 1
+        }
+        fmt.Println("Migrasi dibuat:", path)
+        return 0
+    }
+
+    cfg, err := config.Load()
+    if err != nil {
+        log.Println("Konfigurasi tidak valid:\n", err)
+        return 1
+    }
+    config.ConnectDatabase(cfg.Database)
+
+    switch args[0] {
+    case "up":
+        applied, err := migrations.Up(config.DB)
+        for _, m := range applied {
+            fmt.Printf("up    %s_%s\n", m.Version, m.Name)
+        }
+        if err != nil {
+            log.Println(err)
+            return 1
+        }
+        if len(applied) == 0 {
+            fmt.Println("Tidak ada migrasi yang tertunda")
+        }
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            steps, err = strconv.Atoi(args[1])
+            if err != nil || steps < 1 {
+                log.Println("Jumlah langkah tidak valid:", args[1])
+                return 2
+            }
+        }
+        reverted, err := migrations.Down(config.DB, steps)
+        for _, m := range reverted {
+            fmt.Printf("down  %s_%s\n", m.Version, m.Name)
+        }
+        if err != nil {
+            log.Println(err)
+            return 1
+        }
+    case "status":
+        statuses, err := migrations.StatusOf(config.DB)
+        if err != nil {
+            log.Println(err)
+            return 1
+        }
+        for _, status := range statuses {
+            state := "pending"
+            if status.AppliedAt != nil {
+                state = status.AppliedAt.Format(time.RFC3339)
+            }
+            fmt.Printf("%-25s %s_%s\n", state, status.Version, status.Name)
+        }
+    default:
+        fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+        return 2
+    }
+    return 0
+}
+
+// createMigration menulis kerangka migrasi Go baru. Binary perlu di-build
+// ulang agar migrasi tersebut ikut terdaftar.
+func createMigration(dir, name string, now time.Time) (string, error) {
+    name = strings.Trim(migrationNameInvalidChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+    if name == "" {
+        return "", fmt.Errorf("nama migrasi tidak valid")
+    }
+    version := now.UTC().Format(migrations.VersionFormat)
+    path := filepath.Join(dir, version+"_"+name+".go")
+    content := fmt.Sprintf(`package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(Migration{
+		Version: %q,
+		Name:    %q,
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`, version, name)
+    file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+    if err != nil {
+        return "", err
+    }
+    defer file.Close()
+    if _, err := file.WriteString(content); err != nil {
+        return "", err
+    }
+    return path, nil
+}
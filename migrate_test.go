@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateMigration(t *testing.T) {
+	now := time.Date(2026, 10, 17, 16, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	dir := t.TempDir()
+	path, err := createMigration(dir, " Tambah Kolom-Bio! ", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Versi memakai UTC walaupun waktu lokal berbeda zona.
+	if want := filepath.Join(dir, "20261017093000_tambah_kolom_bio.go"); path != want {
+		t.Fatalf("path = %s, ingin %s", path, want)
+	}
+	if _, err := createMigration(dir, "tambah kolom bio", now); err == nil {
+		t.Error("file yang sudah ada ditimpa")
+	}
+	if _, err := createMigration(dir, "!!!", now); err == nil {
+		t.Error("nama kosong diterima")
+	}
+
+	if testing.Short() {
+		t.Skip("kompilasi migrasi dilewati pada -short")
+	}
+	// File hasil createMigration dikompilasi bersama package migrations di
+	// direktori sementara di dalam module, lalu diperiksa sudah terdaftar.
+	pkg, err := os.MkdirTemp(".", "migrate_create_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(pkg) })
+	for _, name := range []string{"migrations.go", "lock.go"} {
+		data, err := os.ReadFile(filepath.Join("migrations", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkg, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := createMigration(pkg, "tambah kolom bio", now); err != nil {
+		t.Fatal(err)
+	}
+	check := `package migrations
+
+import "testing"
+
+func TestRegistered(t *testing.T) {
+	all := All()
+	if len(all) != 1 || all[0].Version != "20261017093000" || all[0].Name != "tambah_kolom_bio" {
+		t.Fatalf("All() = %+v", all)
+	}
+	if err := all[0].Up(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := all[0].Down(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(pkg, "registered_test.go"), []byte(check), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("go", "test", "-count=1", "./"+filepath.ToSlash(pkg)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("migrasi baru tidak dapat dikompilasi atau tidak terdaftar: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "ok") {
+		t.Fatalf("go test: %s", out)
+	}
+}
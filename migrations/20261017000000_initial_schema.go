@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"social-media-backend/migrations/baseline"
+)
+
+// initial_schema membuat skema yang sebelumnya dibuat oleh AutoMigrate saat
+// aplikasi start, memakai salinan beku model di package baseline. Karena
+// memakai AutoMigrate, migrasi ini juga aman dijalankan pada database lama
+// yang tabelnya sudah dibuat oleh AutoMigrate.
+func init() {
+	register(Migration{
+		Version: "20261017000000",
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&baseline.User{}, &baseline.Session{}, &baseline.RefreshToken{}, &baseline.UserToken{},
+				&baseline.RecoveryCode{}, &baseline.Identity{}, &baseline.OAuthState{}, &baseline.LoginAttempt{},
+				&baseline.APIToken{}, &baseline.AuditEvent{}, &baseline.Follow{}, &baseline.Feed{},
+				&baseline.Comment{}, &baseline.Reaction{}, &baseline.Chatroom{}, &baseline.Message{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				"messages", "chatroom_users", "chatrooms", "reactions", "comments", "feeds",
+				"follows", "audit_events", "api_tokens", "login_attempts", "o_auth_states",
+				"identities", "recovery_codes", "user_tokens", "refresh_tokens", "sessions", "users",
+			)
+		},
+	})
+}
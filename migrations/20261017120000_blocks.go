@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// blocks membuat tabel blocks untuk fitur blokir user.
+func init() {
+	type Block struct {
+		BlockerID uint `gorm:"primaryKey"`
+		BlockedID uint `gorm:"primaryKey;index"`
+		CreatedAt time.Time
+	}
+	register(Migration{
+		Version: "20261017120000",
+		Name:    "blocks",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Block{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("blocks")
+		},
+	})
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// private_profiles menambahkan pengaturan akun privat pada users.
+func init() {
+	type User struct {
+		IsPrivate bool `gorm:"not null;default:false"`
+	}
+	register(Migration{
+		Version: "20261017130000",
+		Name:    "private_profiles",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&User{}, "IsPrivate")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &User{}, "IsPrivate")
+		},
+	})
+}
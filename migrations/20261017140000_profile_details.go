@@ -0,0 +1,32 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// profile_details menambahkan banner, bio, lokasi, pronoun, tautan, dan
+// pengaturan visibilitas field profil pada users.
+func init() {
+	type User struct {
+		Banner     string `gorm:"type:varchar(255)"`
+		Bio        string `gorm:"type:text"`
+		Location   string `gorm:"type:varchar(100)"`
+		Pronouns   string `gorm:"type:varchar(50)"`
+		Links      string `gorm:"type:text"`
+		Visibility string `gorm:"type:text"`
+	}
+	columns := []string{"Banner", "Bio", "Location", "Pronouns", "Links", "Visibility"}
+	register(Migration{
+		Version: "20261017140000",
+		Name:    "profile_details",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range columns {
+				if err := tx.Migrator().AddColumn(&User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &User{}, columns...)
+		},
+	})
+}
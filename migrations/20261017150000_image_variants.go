@@ -0,0 +1,28 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// image_variants menambahkan path setiap ukuran foto profil dan banner pada
+// users.
+func init() {
+	type User struct {
+		PhotoVariants  string `gorm:"type:text"`
+		BannerVariants string `gorm:"type:text"`
+	}
+	columns := []string{"PhotoVariants", "BannerVariants"}
+	register(Migration{
+		Version: "20261017150000",
+		Name:    "image_variants",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range columns {
+				if err := tx.Migrator().AddColumn(&User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &User{}, columns...)
+		},
+	})
+}
@@ -0,0 +1,198 @@
+// Package baseline adalah salinan beku model pada migrasi
+// 20261017000000_initial_schema. Jangan diubah: perubahan skema berikutnya
+// dibuat sebagai migrasi baru, bukan dengan mengedit struct di sini.
+package baseline
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type User struct {
+	ID              uint       `gorm:"primaryKey"`
+	Fullname        string     `gorm:"type:varchar(255)"`
+	Username        string     `gorm:"type:varchar(100);uniqueIndex"`
+	Email           string     `gorm:"type:varchar(100);uniqueIndex"`
+	Password        string     `gorm:"type:varchar(255)"`
+	PhotoProfile    string     `gorm:"type:varchar(255)"`
+	JenisKelamin    string     `gorm:"type:varchar(50)"`
+	TanggalLahir    *time.Time `gorm:"type:date"`
+	EmailVerifiedAt *time.Time
+	Role            string     `gorm:"type:varchar(20);default:user"`
+	DeactivatedAt   *time.Time `gorm:"index"`
+	TOTPSecret      string     `gorm:"type:varchar(64)"`
+	TOTPEnabled     bool
+	TOTPLastStep    int64
+	Followers       []*User `gorm:"many2many:follows;joinForeignKey:FollowingID;JoinReferences:FollowerID"`
+	Following       []*User `gorm:"many2many:follows;joinForeignKey:FollowerID;JoinReferences:FollowingID"`
+	Feeds           []Feed
+	Comments        []Comment
+	Chatrooms       []Chatroom `gorm:"many2many:chatroom_users;"`
+	Messages        []Message
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+type Session struct {
+	ID         uint `gorm:"primaryKey"`
+	UserID     uint `gorm:"index"`
+	User       User
+	UserAgent  string `gorm:"type:varchar(255)"`
+	IPAddress  string `gorm:"type:varchar(45)"`
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RefreshToken struct {
+	ID        uint `gorm:"primaryKey"`
+	SessionID uint `gorm:"index"`
+	Session   Session
+	TokenHash string `gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type UserToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	Purpose   string `gorm:"type:varchar(50);index"`
+	TokenHash string `gorm:"type:varchar(64);uniqueIndex"`
+	Email     string `gorm:"type:varchar(100)"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	CodeHash  string `gorm:"type:varchar(64)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type Identity struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"index"`
+	User      User
+	Provider  string `gorm:"type:varchar(50);uniqueIndex:idx_identity_provider_subject"`
+	Subject   string `gorm:"type:varchar(255);uniqueIndex:idx_identity_provider_subject"`
+	Email     string `gorm:"type:varchar(100)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OAuthState struct {
+	ID           uint   `gorm:"primaryKey"`
+	State        string `gorm:"type:varchar(64);uniqueIndex"`
+	Provider     string `gorm:"type:varchar(50)"`
+	Nonce        string `gorm:"type:varchar(64)"`
+	CodeVerifier string `gorm:"type:varchar(128)"`
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type LoginAttempt struct {
+	Key           string `gorm:"column:attempt_key;type:varchar(191);primaryKey"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+type APIToken struct {
+	ID         uint `gorm:"primaryKey"`
+	UserID     uint `gorm:"index"`
+	User       User
+	Name       string `gorm:"type:varchar(100)"`
+	Prefix     string `gorm:"type:varchar(16)"`
+	TokenHash  string `gorm:"type:varchar(64);uniqueIndex"`
+	Scopes     string `gorm:"type:varchar(255)"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index"`
+	Type      string    `gorm:"type:varchar(50);index"`
+	IPAddress string    `gorm:"type:varchar(45);index"`
+	UserAgent string    `gorm:"type:varchar(255)"`
+	Detail    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+type Follow struct {
+	FollowerID  uint `gorm:"primaryKey"`
+	FollowingID uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+type Feed struct {
+	ID        uint `gorm:"primaryKey"`
+	Feed      string
+	File      string
+	UserID    uint
+	User      User
+	Reactions []Reaction
+	Comments  []Comment
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type Comment struct {
+	ID        uint `gorm:"primaryKey"`
+	Comment   string
+	File      string
+	FeedID    uint
+	Feed      Feed
+	UserID    uint
+	User      User
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type Reaction struct {
+	ID        uint `gorm:"primaryKey"`
+	FeedID    uint
+	Feed      Feed
+	UserID    uint
+	User      User
+	Reaction  string
+	CreatedAt time.Time
+}
+
+type Chatroom struct {
+	ID        uint `gorm:"primaryKey"`
+	Name      string
+	OwnerID   uint
+	Users     []User `gorm:"many2many:chatroom_users;"`
+	Messages  []Message
+	IsGroup   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type Message struct {
+	ID         uint `gorm:"primaryKey"`
+	Message    string
+	File       string
+	ChatroomID uint
+	Chatroom   Chatroom
+	UserID     uint
+	User       User
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
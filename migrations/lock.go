@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// lockName dipakai untuk GET_LOCK di MySQL dan lockID untuk advisory
+	// lock PostgreSQL (angka bebas yang unik untuk aplikasi ini).
+	lockName = "social_media_backend_schema_migrations"
+	lockID   = 7291840551
+)
+
+// withLock menjalankan fn dengan satu koneksi database yang memegang
+// advisory lock, sehingga hanya satu proses yang menjalankan migrasi pada
+// satu waktu. Advisory lock terikat pada koneksi, karena itu fn harus memakai
+// conn, bukan db. SQLite tidak memiliki advisory lock; penulisan ke file
+// database sudah diserialkan dan versi yang tercatat dua kali ditolak oleh
+// primary key schema_migrations.
+func withLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		switch conn.Dialector.Name() {
+		case "mysql":
+			var acquired *int
+			if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, int(LockTimeout.Seconds())).Scan(&acquired).Error; err != nil {
+				return err
+			}
+			if acquired == nil || *acquired != 1 {
+				return ErrLocked
+			}
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+		case "postgres":
+			deadline := time.Now().Add(LockTimeout)
+			for {
+				var acquired bool
+				if err := conn.Raw("SELECT pg_try_advisory_lock(?)", lockID).Scan(&acquired).Error; err != nil {
+					return err
+				}
+				if acquired {
+					break
+				}
+				if time.Now().After(deadline) {
+					return ErrLocked
+				}
+				time.Sleep(time.Second)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockID)
+		}
+		// Session baru agar query di fn tidak berbagi Statement, tetapi
+		// tetap memakai koneksi yang sama.
+		return fn(conn.Session(&gorm.Session{NewDB: true}))
+	})
+}
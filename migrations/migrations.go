@@ -0,0 +1,217 @@
+// Package migrations mengelola perubahan skema database secara berversi.
+// Setiap migrasi adalah fungsi Go yang didaftarkan dari file
+// <versi>_<nama>.go di package ini, sehingga satu migrasi berlaku untuk semua
+// driver (MySQL, PostgreSQL, SQLite) dan dapat berisi backfill data.
+//
+// Versi yang sudah dijalankan dicatat di tabel schema_migrations. Up dan Down
+// memegang advisory lock selama berjalan, sehingga aman dijalankan dari
+// beberapa instance sekaligus.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migration adalah satu langkah perubahan skema. Up dan Down dijalankan di
+// dalam transaksi bersama pencatatan versinya. Catatan: MySQL melakukan
+// commit implisit untuk DDL, sehingga migrasi sebaiknya kecil dan hanya
+// berisi satu perubahan skema.
+type Migration struct {
+	Version string // timestamp UTC, format 20060102150405
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status adalah keadaan satu migrasi di database.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// VersionFormat adalah format waktu untuk Version.
+const VersionFormat = "20060102150405"
+
+// LockTimeout adalah lama maksimum menunggu lock migrasi dari instance lain.
+var LockTimeout = 5 * time.Minute
+
+var (
+	ErrLocked  = errors.New("migrasi sedang dijalankan oleh proses lain")
+	ErrPending = errors.New("ada migrasi yang belum dijalankan")
+)
+
+type schemaMigration struct {
+	Version   string `gorm:"type:varchar(14);primaryKey"`
+	Name      string `gorm:"type:varchar(255)"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+var registry []Migration
+
+// register dipanggil dari init() setiap file migrasi.
+func register(m Migration) {
+	if _, err := time.Parse(VersionFormat, m.Version); err != nil {
+		panic(fmt.Sprintf("migrations: versi %q tidak valid", m.Version))
+	}
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("migrations: versi %s terdaftar dua kali", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All mengembalikan semua migrasi terdaftar, urut dari versi terlama.
+func All() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+// Up menjalankan semua migrasi yang belum dijalankan dan mengembalikan daftar
+// migrasi yang baru diterapkan.
+func Up(db *gorm.DB) ([]Migration, error) {
+	var applied []Migration
+	err := withLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range registry {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := m.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migrasi %s_%s gagal: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down membatalkan steps migrasi terakhir yang sudah dijalankan, dimulai dari
+// versi terbaru.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := withLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(registry) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := registry[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if m.Down == nil {
+				return fmt.Errorf("migrasi %s_%s tidak dapat dibatalkan", m.Version, m.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := m.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %s_%s gagal: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// StatusOf mengembalikan status semua migrasi terdaftar.
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	done, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(registry))
+	for _, m := range registry {
+		status := Status{Migration: m}
+		if record, ok := done[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending mengembalikan migrasi yang belum dijalankan.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	statuses, err := StatusOf(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+func appliedVersions(db *gorm.DB) (map[string]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]schemaMigration, len(records))
+	for _, record := range records {
+		done[record.Version] = record
+	}
+	return done, nil
+}
+
+// dropColumns menghapus kolom field milik model. DropColumn driver SQLite
+// membuat ulang tabel tanpa index-nya (termasuk index unique), sehingga di
+// SQLite dipakai ALTER TABLE ... DROP COLUMN (SQLite 3.35+) yang
+// mempertahankan index lain.
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	if tx.Dialector.Name() != "sqlite" {
+		for _, field := range fields {
+			if err := tx.Migrator().DropColumn(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		column := field
+		if f := stmt.Schema.LookUpField(field); f != nil {
+			column = f.DBName
+		}
+		if err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: stmt.Table}, clause.Column{Name: column}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"social-media-backend/config"
+)
+
+// testDatabases membuka database untuk setiap driver yang tersedia: SQLite
+// selalu, PostgreSQL dan MySQL jika TEST_POSTGRES_DSN dan TEST_MYSQL_DSN
+// diisi. Database PostgreSQL dan MySQL harus khusus untuk test karena semua
+// migrasinya dibatalkan sebelum dan sesudah test.
+func testDatabases(t *testing.T) map[string]*gorm.DB {
+	t.Helper()
+	configs := map[string]config.DatabaseConfig{
+		"sqlite": {Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")},
+	}
+	for driver, env := range map[string]string{"postgres": "TEST_POSTGRES_DSN", "mysql": "TEST_MYSQL_DSN"} {
+		if dsn := os.Getenv(env); dsn != "" {
+			configs[driver] = config.DatabaseConfig{Driver: driver, DSN: dsn}
+		}
+	}
+	databases := map[string]*gorm.DB{}
+	for driver, cfg := range configs {
+		dialector, err := config.Dialector(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// GetIndexes milik driver SQLite selalu memakai Debug(), sehingga
+		// logger diarahkan ke io.Discard, bukan hanya dibuat Silent.
+		silent := logger.New(log.New(io.Discard, "", 0), logger.Config{LogLevel: logger.Silent})
+		db, err := gorm.Open(dialector, &gorm.Config{Logger: silent})
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		reset := func() {
+			if _, err := Down(db, len(registry)); err != nil {
+				t.Fatalf("%s: %v", driver, err)
+			}
+		}
+		reset()
+		t.Cleanup(func() {
+			reset()
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		databases[driver] = db
+	}
+	return databases
+}
+
+// schema menggambarkan semua tabel, kolom, dan index selain
+// schema_migrations dalam bentuk yang dapat dibandingkan.
+func schema(t *testing.T, db *gorm.DB) map[string][]string {
+	t.Helper()
+	migrator := db.Migrator()
+	tables, err := migrator.GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := map[string][]string{}
+	for _, table := range tables {
+		// sqlite_sequence adalah tabel internal SQLite untuk AUTOINCREMENT.
+		if table == (schemaMigration{}).TableName() || table == "sqlite_sequence" {
+			continue
+		}
+		columns, err := migrator.ColumnTypes(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []string
+		for _, column := range columns {
+			nullable, _ := column.Nullable()
+			items = append(items, fmt.Sprintf("kolom %s %s null=%v", column.Name(), column.DatabaseTypeName(), nullable))
+		}
+		indexes, err := migrator.GetIndexes(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range indexes {
+			unique, _ := index.Unique()
+			items = append(items, fmt.Sprintf("index %s %v unique=%v", index.Name(), index.Columns(), unique))
+		}
+		sort.Strings(items)
+		result[table] = items
+	}
+	return result
+}
+
+// schemaDiff mengembalikan perbedaan want dan got per baris; kosong jika
+// sama.
+func schemaDiff(want, got map[string][]string) string {
+	var diff strings.Builder
+	for table := range want {
+		if _, ok := got[table]; !ok {
+			fmt.Fprintf(&diff, "\n  tabel %s hilang", table)
+		}
+	}
+	for table, items := range got {
+		if _, ok := want[table]; !ok {
+			fmt.Fprintf(&diff, "\n  tabel %s tersisa", table)
+		} else if !reflect.DeepEqual(items, want[table]) {
+			fmt.Fprintf(&diff, "\n  tabel %s:\n    ingin %v\n    dapat %v", table, want[table], items)
+		}
+	}
+	return diff.String()
+}
+
+// TestUpDown menjalankan migrasi satu per satu sambil mencatat skema setelah
+// setiap langkah, lalu membatalkan semuanya satu per satu dan memastikan
+// setiap Down mengembalikan skema persis seperti sebelum Up-nya. Terakhir
+// Up dijalankan lagi dari awal.
+func TestUpDown(t *testing.T) {
+	all := append([]Migration(nil), registry...)
+	defer func() { registry = all }()
+
+	for driver, db := range testDatabases(t) {
+		t.Run(driver, func(t *testing.T) {
+			snapshots := []map[string][]string{schema(t, db)}
+			for i := range all {
+				registry = all[:i+1]
+				applied, err := Up(db)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(applied) != 1 || applied[0].Version != all[i].Version {
+					t.Fatalf("Up menerapkan %v, ingin hanya %s", applied, all[i].Version)
+				}
+				snapshots = append(snapshots, schema(t, db))
+			}
+			registry = all
+			if pending, err := Pending(db); err != nil || len(pending) != 0 {
+				t.Fatalf("Pending = %v, %v", pending, err)
+			}
+			final := snapshots[len(all)]
+
+			for i := len(all) - 1; i >= 0; i-- {
+				reverted, err := Down(db, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(reverted) != 1 || reverted[0].Version != all[i].Version {
+					t.Fatalf("Down membatalkan %v, ingin %s", reverted, all[i].Version)
+				}
+				if diff := schemaDiff(snapshots[i], schema(t, db)); diff != "" {
+					t.Errorf("Down %s_%s tidak membalik Up-nya:%s", all[i].Version, all[i].Name, diff)
+				}
+			}
+			if reverted, err := Down(db, 1); err != nil || len(reverted) != 0 {
+				t.Fatalf("Down tanpa migrasi = %v, %v", reverted, err)
+			}
+
+			if applied, err := Up(db); err != nil || len(applied) != len(all) {
+				t.Fatalf("Up ulang = %d migrasi, %v", len(applied), err)
+			}
+			if diff := schemaDiff(final, schema(t, db)); diff != "" {
+				t.Errorf("skema setelah Up ulang berbeda:%s", diff)
+			}
+			statuses, err := StatusOf(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, status := range statuses {
+				if status.AppliedAt == nil {
+					t.Errorf("%s belum tercatat", status.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestUpFailureRollsBack(t *testing.T) {
+	all := append([]Migration(nil), registry...)
+	defer func() { registry = all }()
+	failing := Migration{
+		Version: "29991231235959",
+		Name:    "gagal",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE migration_test_partial (id integer)").Error; err != nil {
+				return err
+			}
+			return errors.New("sengaja gagal")
+		},
+		Down: func(tx *gorm.DB) error { return nil },
+	}
+
+	for driver, db := range testDatabases(t) {
+		t.Run(driver, func(t *testing.T) {
+			registry = append(append([]Migration(nil), all...), failing)
+			applied, err := Up(db)
+			if err == nil || len(applied) != len(all) {
+				t.Fatalf("Up = %d migrasi, %v", len(applied), err)
+			}
+			pending, _ := Pending(db)
+			if len(pending) != 1 || pending[0].Version != failing.Version {
+				t.Fatalf("Pending = %v", pending)
+			}
+			// MySQL melakukan commit implisit untuk DDL, sehingga tabel
+			// tetap ada; di driver lain transaksi dibatalkan seluruhnya.
+			if driver != "mysql" && db.Migrator().HasTable("migration_test_partial") {
+				t.Error("perubahan migrasi yang gagal tidak dibatalkan")
+			}
+			db.Migrator().DropTable("migration_test_partial")
+			registry = all
+		})
+	}
+}
+
+func TestDownWithoutDown(t *testing.T) {
+	all := append([]Migration(nil), registry...)
+	defer func() { registry = all }()
+	for driver, db := range testDatabases(t) {
+		t.Run(driver, func(t *testing.T) {
+			registry = append(append([]Migration(nil), all...), Migration{
+				Version: "29991231235959",
+				Name:    "tanpa_down",
+				Up:      func(tx *gorm.DB) error { return nil },
+			})
+			if _, err := Up(db); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Down(db, 1); err == nil {
+				t.Error("Down migrasi tanpa fungsi Down tidak mengembalikan error")
+			}
+			db.Delete(&schemaMigration{}, "version = ?", "29991231235959")
+			registry = all
+		})
+	}
+}
+
+// TestWithLock memastikan lock migrasi menolak proses lain selama masih
+// dipegang. SQLite tidak memiliki advisory lock, sehingga di sana fn
+// bersarang tetap berjalan.
+func TestWithLock(t *testing.T) {
+	previous := LockTimeout
+	LockTimeout = 0
+	defer func() { LockTimeout = previous }()
+
+	for driver, db := range testDatabases(t) {
+		t.Run(driver, func(t *testing.T) {
+			var nested error
+			ran := false
+			err := withLock(db, func(conn *gorm.DB) error {
+				// db memakai koneksi lain dari pool, seperti instance lain.
+				nested = withLock(db, func(*gorm.DB) error {
+					ran = true
+					return nil
+				})
+				return conn.Exec("SELECT 1").Error
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if driver == "sqlite" {
+				if nested != nil || !ran {
+					t.Errorf("withLock bersarang = %v, berjalan %v", nested, ran)
+				}
+			} else if !errors.Is(nested, ErrLocked) || ran {
+				t.Errorf("withLock saat lock dipegang = %v, berjalan %v", nested, ran)
+			}
+			// Lock dilepas setelah fn selesai.
+			if err := withLock(db, func(*gorm.DB) error { return nil }); err != nil {
+				t.Errorf("withLock setelah lock dilepas = %v", err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	all := append([]Migration(nil), registry...)
+	defer func() { registry = all }()
+	for name, m := range map[string]Migration{
+		"versi tidak valid": {Version: "2026-10-17", Name: "x"},
+		"versi ganda":       {Version: all[0].Version, Name: "x"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: register tidak panic", name)
+				}
+			}()
+			register(m)
+		}()
+	}
+	register(Migration{Version: "20000101000000", Name: "awal"})
+	if got := All(); got[0].Version != "20000101000000" || len(got) != len(all)+1 {
+		t.Errorf("All tidak urut: %v", got[0])
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,10 +12,32 @@ type User struct {
     Fullname     string         `gorm:"type:varchar(255)"`
     Username     string         `gorm:"type:varchar(100);uniqueIndex"`
     Email        string         `gorm:"type:varchar(100);uniqueIndex"`
-    Password     string         `gorm:"type:varchar(255)"`
+    // Hash password. Tidak pernah dikirim ke client; respons memakai
+    // package views.
+    Password     string         `gorm:"type:varchar(255)" json:"-"`
     PhotoProfile string         `gorm:"type:varchar(255)"`
+    Banner       string         `gorm:"type:varchar(255)"`
+    // Path setiap ukuran foto profil dan banner hasil package imaging,
+    // dengan nama ukuran sebagai key. PhotoProfile dan Banner berisi ukuran
+    // terbesar.
+    PhotoVariants  map[string]string `gorm:"type:text;serializer:json"`
+    BannerVariants map[string]string `gorm:"type:text;serializer:json"`
     JenisKelamin string         `gorm:"type:varchar(50)"`
     TanggalLahir *time.Time     `gorm:"type:date"`      
+    EmailVerifiedAt *time.Time
+    Role         string         `gorm:"type:varchar(20);default:user"` // user, moderator, atau admin
+    // Akun privat hanya menampilkan feed, followers, dan following kepada
+    // pemiliknya dan user yang ia ikuti (lihat policy.CanViewContent).
+    IsPrivate    bool           `gorm:"not null;default:false"`
+    ProfileDetails `gorm:"embedded"`
+    // Terisi saat user menonaktifkan akunnya; akun dihapus permanen setelah
+    // masa tenggang habis.
+    DeactivatedAt *time.Time    `gorm:"index"`
+    // Two-factor authentication (TOTP). Secret tidak pernah dikirim ke client
+    // selain saat enrollment.
+    TOTPSecret   string         `gorm:"type:varchar(64)" json:"-"`
+    TOTPEnabled  bool
+    TOTPLastStep int64          `json:"-"`
     // Relasi many-to-many (follow)
     Followers    []*User        `gorm:"many2many:follows;joinForeignKey:FollowingID;JoinReferences:FollowerID"`
     Following    []*User        `gorm:"many2many:follows;joinForeignKey:FollowerID;JoinReferences:FollowingID"`
@@ -27,6 +50,146 @@ type User struct {
     DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
+// ProfileDetails adalah detail profil yang diisi user sendiri dan ditampilkan
+// di profil publiknya sesuai Visibility.
+type ProfileDetails struct {
+	Bio      string   `gorm:"type:text"`
+	Location string   `gorm:"type:varchar(100)"`
+	Pronouns string   `gorm:"type:varchar(50)"`
+	Links    []string `gorm:"type:text;serializer:json"`
+	// Visibility memetakan nama field profil ke tingkat visibilitasnya;
+	// field yang tidak ada memakai default (lihat policy.FieldVisibility).
+	Visibility map[string]string `gorm:"type:text;serializer:json"`
+}
+
+// Session mewakili satu sesi login (perangkat). Semua refresh token hasil
+// rotasi dari login yang sama berada dalam satu sesi, sehingga mencabut sesi
+// berarti mencabut seluruh rantai refresh token tersebut.
+type Session struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"index"`
+	User       User
+	UserAgent  string     `gorm:"type:varchar(255)"`
+	IPAddress  string     `gorm:"type:varchar(45)"`
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// RefreshToken hanya disimpan dalam bentuk hash (SHA-256). UsedAt terisi saat
+// token ditukar dengan pasangan token baru; pemakaian ulang token yang sudah
+// terpakai dianggap sebagai kebocoran token.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	SessionID uint      `gorm:"index"`
+	Session   Session
+	TokenHash string    `gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// UserToken adalah token sekali pakai yang dikirim ke user melalui email,
+// misalnya untuk verifikasi alamat email. Purpose membedakan kegunaannya dan
+// hanya hash token yang disimpan.
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"index"`
+	Purpose   string     `gorm:"type:varchar(50);index"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex"`
+	Email     string     `gorm:"type:varchar(100)"` // alamat email tujuan saat token dibuat
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// RecoveryCode adalah kode cadangan sekali pakai untuk login ketika perangkat
+// authenticator tidak tersedia. Hanya hash-nya yang disimpan.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"index"`
+	CodeHash  string     `gorm:"type:varchar(64)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Identity menautkan akun user dengan identitas dari provider eksternal
+// (OAuth2/OpenID Connect). Pasangan Provider dan Subject bersifat unik.
+type Identity struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index"`
+	User      User
+	Provider  string    `gorm:"type:varchar(50);uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `gorm:"type:varchar(255);uniqueIndex:idx_identity_provider_subject"`
+	Email     string    `gorm:"type:varchar(100)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OAuthState menyimpan state, nonce, dan PKCE code verifier selama alur
+// login OAuth2 berlangsung. Setiap baris hanya dapat dipakai sekali.
+type OAuthState struct {
+	ID           uint      `gorm:"primaryKey"`
+	State        string    `gorm:"type:varchar(64);uniqueIndex"`
+	Provider     string    `gorm:"type:varchar(50)"`
+	Nonce        string    `gorm:"type:varchar(64)"`
+	CodeVerifier string    `gorm:"type:varchar(128)"`
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// LoginAttempt menyimpan jumlah kegagalan login per kunci (akun atau IP)
+// untuk perlindungan brute-force ketika aplikasi berjalan di beberapa instance.
+type LoginAttempt struct {
+	Key           string     `gorm:"column:attempt_key;type:varchar(191);primaryKey"`
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// APIToken adalah personal access token untuk script dan integrasi. Token
+// hanya ditampilkan sekali saat dibuat; yang disimpan adalah hash SHA-256 dan
+// Prefix (beberapa karakter awal) untuk membantu user mengenali token.
+// Scopes disimpan dipisahkan koma, misalnya "feeds:read,feeds:write".
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"index"`
+	User       User
+	Name       string     `gorm:"type:varchar(100)"`
+	Prefix     string     `gorm:"type:varchar(16)"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex"`
+	Scopes     string     `gorm:"type:varchar(255)"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AuditEvent adalah catatan kejadian keamanan akun (login, ganti password,
+// perubahan role, dan sebagainya). Tabel ini append-only: hook di bawah
+// menolak setiap update dan delete, termasuk saat akun di-purge.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index"`
+	Type      string    `gorm:"type:varchar(50);index"`
+	IPAddress string    `gorm:"type:varchar(45);index"`
+	UserAgent string    `gorm:"type:varchar(255)"`
+	Detail    string    `gorm:"type:text"` // JSON
+	CreatedAt time.Time `gorm:"index"`
+}
+
+var ErrAuditEventImmutable = errors.New("audit event tidak dapat diubah atau dihapus")
+
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
+
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
+
 type Follow struct {
 	FollowerID  uint      `gorm:"primaryKey"`
 	FollowingID uint      `gorm:"primaryKey"`
@@ -34,6 +197,14 @@ type Follow struct {
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
 }
 
+// Block mencatat bahwa BlockerID memblokir BlockedID. Keduanya tidak saling
+// muncul di direktori user dan tidak dapat saling mengikuti.
+type Block struct {
+	BlockerID uint      `gorm:"primaryKey"`
+	BlockedID uint      `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
 type Feed struct {
 	ID        uint           `gorm:"primaryKey"`
 	Feed      string         
@@ -0,0 +1,455 @@
+// Package oidc mengimplementasikan sisi client dari alur OAuth2
+// authorization code dengan PKCE dan verifikasi ID token OpenID Connect.
+// Provider dikonfigurasi secara generik, baik lewat discovery
+// (/.well-known/openid-configuration) maupun endpoint yang diisi manual untuk
+// provider OAuth2 non-OIDC seperti GitHub.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Provider adalah konfigurasi satu identity provider.
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Issuer mengaktifkan discovery dan validasi klaim "iss" pada ID token.
+	Issuer      string
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	JWKSURL     string
+
+	HTTPClient *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+	keys       map[string]interface{}
+}
+
+// TokenResponse adalah respons token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Claims adalah informasi identitas user dari provider.
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Picture           string
+}
+
+func (p *Provider) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// Discover mengisi endpoint yang masih kosong dari dokumen discovery issuer.
+// Hasilnya di-cache sehingga aman dipanggil di setiap request.
+func (p *Provider) Discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered || p.Issuer == "" {
+		return nil
+	}
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(p.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, "", &doc); err != nil {
+		return fmt.Errorf("discovery %s gagal: %w", p.Name, err)
+	}
+	if doc.Issuer != p.Issuer {
+		return fmt.Errorf("issuer discovery %q tidak sesuai dengan %q", doc.Issuer, p.Issuer)
+	}
+	if p.AuthURL == "" {
+		p.AuthURL = doc.AuthorizationEndpoint
+	}
+	if p.TokenURL == "" {
+		p.TokenURL = doc.TokenEndpoint
+	}
+	if p.UserInfoURL == "" {
+		p.UserInfoURL = doc.UserInfoEndpoint
+	}
+	if p.JWKSURL == "" {
+		p.JWKSURL = doc.JWKSURI
+	}
+	p.discovered = true
+	return nil
+}
+
+// RandomString menghasilkan string acak base64url dari n byte.
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewPKCE menghasilkan code verifier dan code challenge S256 (RFC 7636).
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// AuthCodeURL membuat URL authorization endpoint tempat user diarahkan.
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	if nonce != "" {
+		params.Set("nonce", nonce)
+	}
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + params.Encode()
+}
+
+// Exchange menukar authorization code dengan token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint mengembalikan status %d: %s", resp.StatusCode, body)
+	}
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token endpoint tidak mengembalikan access_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken memverifikasi tanda tangan ID token terhadap JWKS provider,
+// lalu memeriksa issuer, audience, masa berlaku, dan nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("algoritma ID token tidak didukung: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("klaim ID token tidak valid")
+	}
+	if p.Issuer != "" && claims["iss"] != p.Issuer {
+		return nil, fmt.Errorf("issuer ID token tidak sesuai: %v", claims["iss"])
+	}
+	if !audienceContains(claims["aud"], p.ClientID) {
+		return nil, errors.New("audience ID token tidak sesuai")
+	}
+	if nonce != "" && claims["nonce"] != nonce {
+		return nil, errors.New("nonce ID token tidak sesuai")
+	}
+	return claimsFromMap(claims), nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key mengembalikan public key dengan kid tertentu dari JWKS. Jika kid tidak
+// ditemukan, JWKS diambil ulang sekali untuk menangani rotasi kunci provider.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.JWKSURL == "" {
+		return nil, errors.New("provider tidak memiliki jwks_uri")
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.JWKSURL, "", &set); err != nil {
+		return nil, fmt.Errorf("gagal mengambil JWKS: %w", err)
+	}
+	p.keys = map[string]interface{}{}
+	for _, raw := range set.Keys {
+		k, err := parseJWK(raw)
+		if err != nil {
+			continue
+		}
+		p.keys[k.kid] = k.key
+	}
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kunci dengan kid %q tidak ditemukan", kid)
+}
+
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	// Token tanpa kid dapat diverifikasi jika provider hanya punya satu kunci.
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+type jwk struct {
+	kid string
+	key interface{}
+}
+
+func parseJWK(raw json.RawMessage) (*jwk, error) {
+	var k struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return nil, err
+	}
+	if k.Use != "" && k.Use != "sig" {
+		return nil, errors.New("kunci bukan untuk tanda tangan")
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &jwk{kid: k.Kid, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("kurva tidak didukung: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &jwk{kid: k.Kid, key: &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}}, nil
+	}
+	return nil, fmt.Errorf("tipe kunci tidak didukung: %s", k.Kty)
+}
+
+// UserInfo mengambil informasi user dari userinfo endpoint. Respons OIDC
+// standar maupun format GitHub (id, login, avatar_url) didukung.
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) (*Claims, error) {
+	if p.UserInfoURL == "" {
+		return nil, errors.New("provider tidak memiliki userinfo endpoint")
+	}
+	var data map[string]interface{}
+	if err := p.getJSON(ctx, p.UserInfoURL, accessToken, &data); err != nil {
+		return nil, err
+	}
+	claims := claimsFromMap(data)
+	if claims.Subject == "" {
+		switch id := data["id"].(type) {
+		case float64:
+			claims.Subject = strconv.FormatFloat(id, 'f', -1, 64)
+		case string:
+			claims.Subject = id
+		}
+	}
+	if claims.PreferredUsername == "" {
+		claims.PreferredUsername, _ = data["login"].(string)
+	}
+	if claims.Picture == "" {
+		claims.Picture, _ = data["avatar_url"].(string)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("userinfo tidak mengandung identitas user")
+	}
+	return claims, nil
+}
+
+func claimsFromMap(m map[string]interface{}) *Claims {
+	c := &Claims{}
+	c.Subject, _ = m["sub"].(string)
+	c.Email, _ = m["email"].(string)
+	c.Name, _ = m["name"].(string)
+	c.PreferredUsername, _ = m["preferred_username"].(string)
+	c.Picture, _ = m["picture"].(string)
+	switch v := m["email_verified"].(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+	return c
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint, bearer string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s mengembalikan status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// ProvidersFromEnv membaca daftar provider dari OIDC_PROVIDERS (dipisahkan
+// koma) dan konfigurasi tiap provider dari variabel OIDC_<NAMA>_*, misalnya
+// OIDC_GOOGLE_ISSUER, OIDC_GOOGLE_CLIENT_ID, dan OIDC_GOOGLE_CLIENT_SECRET.
+// Jika REDIRECT_URL kosong, dipakai <baseURL>/auth/<nama>/callback.
+func ProvidersFromEnv(baseURL string) (map[string]*Provider, error) {
+	providers := map[string]*Provider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := &Provider{
+			Name:         name,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			UserInfoURL:  os.Getenv(prefix + "USERINFO_URL"),
+			JWKSURL:      os.Getenv(prefix + "JWKS_URL"),
+			Scopes:       strings.Fields(strings.ReplaceAll(os.Getenv(prefix+"SCOPES"), ",", " ")),
+		}
+		if p.ClientID == "" {
+			return nil, fmt.Errorf("%sCLIENT_ID wajib diisi", prefix)
+		}
+		if p.Issuer == "" && (p.AuthURL == "" || p.TokenURL == "") {
+			return nil, fmt.Errorf("%sISSUER atau %sAUTH_URL dan %sTOKEN_URL wajib diisi", prefix, prefix, prefix)
+		}
+		if p.RedirectURL == "" {
+			p.RedirectURL = strings.TrimSuffix(baseURL, "/") + "/auth/" + name + "/callback"
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers[name] = p
+	}
+	return providers, nil
+}
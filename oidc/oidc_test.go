@@ -0,0 +1,192 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"social-media-backend/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	t.Helper()
+	srv := oidctest.NewServer(t)
+	p := &Provider{
+		Name:         "mock",
+		ClientID:     srv.ClientID,
+		ClientSecret: srv.ClientSecret,
+		RedirectURL:  "http://app.test/auth/mock/callback",
+		Scopes:       []string{"openid", "email"},
+		Issuer:       srv.Issuer(),
+	}
+	if err := p.Discover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return p, srv
+}
+
+// authorize menjalankan AuthCodeURL dan login di provider, lalu mengembalikan
+// authorization code dari redirect.
+func authorize(t *testing.T, p *Provider, srv *oidctest.Server, nonce, challenge string, claims jwt.MapClaims) string {
+	t.Helper()
+	redirect, err := srv.Authorize(p.AuthCodeURL("state-1", nonce, challenge), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("state") != "state-1" {
+		t.Fatalf("state = %q", u.Query().Get("state"))
+	}
+	return u.Query().Get("code")
+}
+
+func TestDiscover(t *testing.T) {
+	p, srv := newTestProvider(t)
+	for name, got := range map[string]string{
+		"AuthURL": p.AuthURL, "TokenURL": p.TokenURL,
+		"UserInfoURL": p.UserInfoURL, "JWKSURL": p.JWKSURL,
+	} {
+		if !strings.HasPrefix(got, srv.URL+"/") {
+			t.Errorf("%s = %q", name, got)
+		}
+	}
+
+	wrong := &Provider{Name: "mock", Issuer: srv.Issuer() + "/lain"}
+	if err := wrong.Discover(context.Background()); err == nil {
+		t.Fatal("discovery dengan issuer berbeda harus gagal")
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	p, srv := newTestProvider(t)
+	ctx := context.Background()
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{"sub": "123", "email": "Alice@Example.com", "email_verified": true, "name": "Alice"}
+
+	// code_verifier yang salah ditolak token endpoint, dan code tidak dapat
+	// dipakai lagi.
+	code := authorize(t, p, srv, "nonce-1", challenge, claims)
+	if _, err := p.Exchange(ctx, code, verifier+"x"); err == nil {
+		t.Fatal("exchange dengan code_verifier salah harus gagal")
+	}
+	if _, err := p.Exchange(ctx, code, verifier); err == nil {
+		t.Fatal("code yang sudah dipakai harus ditolak")
+	}
+
+	code = authorize(t, p, srv, "nonce-1", challenge, claims)
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != "123" || got.Email != "Alice@Example.com" || !got.EmailVerified || got.Name != "Alice" {
+		t.Fatalf("claims = %+v", got)
+	}
+	info, err := p.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *info != *got {
+		t.Fatalf("userinfo = %+v, ID token = %+v", info, got)
+	}
+	if _, err := p.UserInfo(ctx, "token-palsu"); err == nil {
+		t.Fatal("userinfo dengan access token palsu harus gagal")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	p, srv := newTestProvider(t)
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": srv.Issuer(), "aud": srv.ClientID, "sub": "123", "nonce": "nonce-1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	with := func(key string, value interface{}) string {
+		claims := valid()
+		claims[key] = value
+		return srv.IDToken(claims)
+	}
+	if _, err := p.VerifyIDToken(context.Background(), srv.IDToken(valid()), "nonce-1"); err != nil {
+		t.Fatalf("ID token valid ditolak: %v", err)
+	}
+	if _, err := p.VerifyIDToken(context.Background(), with("aud", []interface{}{"lain", srv.ClientID}), "nonce-1"); err != nil {
+		t.Fatalf("aud berupa array ditolak: %v", err)
+	}
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte("rahasia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, valid())
+	forged.Header["kid"] = srv.KeyID
+	forgedToken, err := forged.SignedString(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodRS256, valid())
+	unknownKid.Header["kid"] = "kunci-lain"
+	unknownKidToken, err := unknownKid.SignedString(srv.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		token string
+	}{
+		{"nonce salah", with("nonce", "nonce-lain")},
+		{"tanpa nonce", with("nonce", nil)},
+		{"aud salah", with("aud", "client-lain")},
+		{"iss salah", with("iss", "https://issuer-lain.example")},
+		{"kedaluwarsa", with("exp", time.Now().Add(-time.Minute).Unix())},
+		{"alg HS256", hmac},
+		{"alg none", none},
+		{"tanda tangan kunci lain", forgedToken},
+		{"kid tidak dikenal", unknownKidToken},
+	} {
+		if _, err := p.VerifyIDToken(context.Background(), c.token, "nonce-1"); err == nil {
+			t.Errorf("%s: ID token harus ditolak", c.name)
+		}
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p := &Provider{ClientID: "client", RedirectURL: "http://app.test/cb", AuthURL: "https://idp.example/auth?prompt=login", Scopes: []string{"openid", "email"}}
+	u, err := url.Parse(p.AuthCodeURL("state-1", "nonce-1", "challenge-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"prompt": "login", "response_type": "code", "client_id": "client", "redirect_uri": "http://app.test/cb",
+		"scope": "openid email", "state": "state-1", "nonce": "nonce-1",
+		"code_challenge": "challenge-1", "code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := u.Query().Get(key); got != value {
+			t.Errorf("%s = %q, ingin %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Package password meng-hash dan memverifikasi password user. Hash disimpan
+// dalam format PHC string ($argon2id$v=19$m=...,t=...,p=...$salt$hash) atau
+// format bcrypt ($2a$...), sehingga algoritma dan parameternya selalu
+// tercatat bersama hash dan dapat dimigrasikan bertahap.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHash dikembalikan jika format hash tidak dikenali hasher mana pun.
+var ErrUnknownHash = errors.New("password: format hash tidak dikenal")
+
+// Hasher adalah satu algoritma hash password.
+type Hasher interface {
+	// Hash menghasilkan hash baru dengan parameter hasher saat ini.
+	Hash(password string) (string, error)
+	// Recognizes memeriksa apakah hash dibuat dengan algoritma ini.
+	Recognizes(encoded string) bool
+	// Verify memeriksa password terhadap hash yang dikenali hasher ini.
+	Verify(password, encoded string) (bool, error)
+	// Outdated bernilai true jika hash memakai parameter yang berbeda dari
+	// konfigurasi hasher saat ini.
+	Outdated(encoded string) bool
+}
+
+// Argon2id meng-hash password dengan Argon2id. Memory dalam KiB.
+type Argon2id struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2id mengikuti rekomendasi minimum OWASP (19 MiB, 2 iterasi)
+// yang cukup ringan untuk instance kecil.
+func DefaultArgon2id() Argon2id {
+	return Argon2id{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+const argon2idPrefix = "$argon2id$"
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (a Argon2id) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (a Argon2id) Outdated(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != a.Memory || params.Iterations != a.Iterations ||
+		params.Parallelism != a.Parallelism ||
+		uint32(len(salt)) != a.SaltLength || uint32(len(key)) != a.KeyLength
+}
+
+// decodeArgon2id mem-parsing PHC string Argon2id.
+func decodeArgon2id(encoded string) (Argon2id, []byte, []byte, error) {
+	var params Argon2id
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHash
+	}
+	return params, salt, key, nil
+}
+
+// Bcrypt meng-hash password dengan bcrypt.
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(hash), err
+}
+
+func (b Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b Bcrypt) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.Cost
+}
+
+// Manager membuat hash dengan hasher Current dan tetap dapat memverifikasi
+// hash lama dari hasher di Legacy.
+type Manager struct {
+	Current Hasher
+	Legacy  []Hasher
+}
+
+// Default memakai Argon2id untuk hash baru dan tetap menerima hash bcrypt
+// lama (cost 14, seperti sebelumnya dipakai aplikasi).
+func Default() *Manager {
+	return &Manager{Current: DefaultArgon2id(), Legacy: []Hasher{Bcrypt{Cost: 14}}}
+}
+
+// Hash meng-hash password dengan hasher Current.
+func (m *Manager) Hash(password string) (string, error) {
+	return m.Current.Hash(password)
+}
+
+// Verify memeriksa password. needsRehash bernilai true jika password benar
+// tetapi hash dibuat dengan algoritma atau parameter yang sudah usang,
+// sehingga pemanggil sebaiknya menyimpan hash baru dari Hash.
+func (m *Manager) Verify(password, encoded string) (ok, needsRehash bool) {
+	for i, hasher := range append([]Hasher{m.Current}, m.Legacy...) {
+		if !hasher.Recognizes(encoded) {
+			continue
+		}
+		ok, err := hasher.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false
+		}
+		return true, i > 0 || hasher.Outdated(encoded)
+	}
+	return false, false
+}
+
+// Options adalah parameter Manager. Field yang bernilai nol memakai default:
+//
+//	Hasher             argon2id (default) atau bcrypt
+//	Argon2MemoryKiB    memory Argon2id dalam KiB (default 19456)
+//	Argon2Iterations   jumlah iterasi Argon2id (default 2)
+//	Argon2Parallelism  parallelism Argon2id (default 1)
+//	BcryptCost         cost bcrypt (default 12)
+type Options struct {
+	Hasher            string
+	Argon2MemoryKiB   uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+}
+
+// NewManager membuat Manager dari opts. Algoritma yang tidak dipilih tetap
+// dipakai untuk memverifikasi hash lama.
+func NewManager(opts Options) (*Manager, error) {
+	argon := DefaultArgon2id()
+	if opts.Argon2MemoryKiB != 0 {
+		if opts.Argon2MemoryKiB < 8*1024 || opts.Argon2MemoryKiB > 4*1024*1024 {
+			return nil, fmt.Errorf("memory Argon2id tidak valid: %d KiB", opts.Argon2MemoryKiB)
+		}
+		argon.Memory = opts.Argon2MemoryKiB
+	}
+	if opts.Argon2Iterations != 0 {
+		if opts.Argon2Iterations > 100 {
+			return nil, fmt.Errorf("iterasi Argon2id tidak valid: %d", opts.Argon2Iterations)
+		}
+		argon.Iterations = opts.Argon2Iterations
+	}
+	if opts.Argon2Parallelism != 0 {
+		argon.Parallelism = opts.Argon2Parallelism
+	}
+
+	bcryptHasher := Bcrypt{Cost: 12}
+	if opts.BcryptCost != 0 {
+		if opts.BcryptCost < bcrypt.MinCost || opts.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("cost bcrypt tidak valid: %d", opts.BcryptCost)
+		}
+		bcryptHasher.Cost = opts.BcryptCost
+	}
+
+	switch opts.Hasher {
+	case "", "argon2id":
+		return &Manager{Current: argon, Legacy: []Hasher{bcryptHasher}}, nil
+	case "bcrypt":
+		return &Manager{Current: bcryptHasher, Legacy: []Hasher{argon}}, nil
+	default:
+		return nil, fmt.Errorf("hasher password tidak dikenal: %q", opts.Hasher)
+	}
+}
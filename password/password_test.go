@@ -0,0 +1,252 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Parameter ringan agar test cepat; nilainya tidak penting untuk perilaku
+// yang diuji.
+var (
+	testArgon  = Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	testBcrypt = Bcrypt{Cost: 4}
+)
+
+func hash(t *testing.T, hasher Hasher, password string) string {
+	t.Helper()
+	encoded, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestArgon2id(t *testing.T) {
+	encoded := hash(t, testArgon, "rahasia")
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") || len(strings.Split(encoded, "$")) != 6 {
+		t.Fatalf("format hash = %s", encoded)
+	}
+	if other := hash(t, testArgon, "rahasia"); other == encoded {
+		t.Error("salt tidak acak")
+	}
+	if ok, err := testArgon.Verify("rahasia", encoded); !ok || err != nil {
+		t.Errorf("Verify password benar = %v, %v", ok, err)
+	}
+	if ok, _ := testArgon.Verify("Rahasia", encoded); ok {
+		t.Error("password salah diterima")
+	}
+	// Parameter dibaca dari hash, bukan dari hasher.
+	if ok, _ := DefaultArgon2id().Verify("rahasia", encoded); !ok {
+		t.Error("hash dengan parameter lain ditolak")
+	}
+	if testArgon.Outdated(encoded) {
+		t.Error("hash dengan parameter sama dianggap usang")
+	}
+	if !DefaultArgon2id().Outdated(encoded) {
+		t.Error("hash dengan parameter berbeda tidak dianggap usang")
+	}
+}
+
+func TestArgon2idMalformed(t *testing.T) {
+	valid := hash(t, testArgon, "rahasia")
+	parts := strings.Split(valid, "$")
+	replace := func(i int, value string) string {
+		copied := append([]string(nil), parts...)
+		copied[i] = value
+		return strings.Join(copied, "$")
+	}
+	for name, encoded := range map[string]string{
+		"kosong":           "",
+		"bagian kurang":    strings.Join(parts[:5], "$"),
+		"bagian lebih":     valid + "$x",
+		"algoritma lain":   replace(1, "argon2i"),
+		"versi lama":       replace(2, "v=16"),
+		"versi bukan":      replace(2, "versi"),
+		"parameter rusak":  replace(3, "m=x,t=1,p=1"),
+		"parameter kurang": replace(3, "m=1024"),
+		"salt bukan b64":   replace(4, "!!!"),
+		"hash bukan b64":   replace(5, "!!!"),
+		"hash kosong":      replace(5, ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if ok, err := testArgon.Verify("rahasia", encoded); ok || err == nil {
+				t.Errorf("Verify = %v, %v", ok, err)
+			}
+			if !testArgon.Outdated(encoded) {
+				t.Error("hash rusak tidak dianggap usang")
+			}
+			manager := &Manager{Current: testArgon}
+			if ok, rehash := manager.Verify("rahasia", encoded); ok || rehash {
+				t.Errorf("Manager.Verify = %v, %v", ok, rehash)
+			}
+		})
+	}
+}
+
+func TestManagerVerify(t *testing.T) {
+	manager := &Manager{Current: testArgon, Legacy: []Hasher{testBcrypt}}
+	stronger := testArgon
+	stronger.Iterations = 2
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		ok       bool
+		rehash   bool
+	}{
+		{"argon2id terkini", hash(t, testArgon, "rahasia"), "rahasia", true, false},
+		{"argon2id parameter lama", hash(t, stronger, "rahasia"), "rahasia", true, true},
+		{"bcrypt lama", hash(t, testBcrypt, "rahasia"), "rahasia", true, true},
+		{"bcrypt cost berbeda", hash(t, Bcrypt{Cost: 5}, "rahasia"), "rahasia", true, true},
+		{"argon2id password salah", hash(t, testArgon, "rahasia"), "salah", false, false},
+		{"bcrypt password salah", hash(t, testBcrypt, "rahasia"), "salah", false, false},
+		{"bcrypt rusak", "$2a$04$rusak", "rahasia", false, false},
+		{"format tidak dikenal", "rahasia", "rahasia", false, false},
+		{"scrypt", "$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA", "rahasia", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash := manager.Verify(tt.password, tt.encoded)
+			if ok != tt.ok || rehash != tt.rehash {
+				t.Errorf("Verify = %v, %v; ingin %v, %v", ok, rehash, tt.ok, tt.rehash)
+			}
+		})
+	}
+
+	// Setelah rehash, hash baru tidak perlu di-rehash lagi.
+	rehashed, err := manager.Hash("rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, rehash := manager.Verify("rahasia", rehashed); !ok || rehash {
+		t.Errorf("Verify hash baru = %v, %v", ok, rehash)
+	}
+	// Manager tanpa Legacy menolak bcrypt.
+	if ok, _ := (&Manager{Current: testArgon}).Verify("rahasia", hash(t, testBcrypt, "rahasia")); ok {
+		t.Error("bcrypt diterima tanpa Legacy")
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	manager, err := NewManager(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := manager.Current.(Argon2id); !ok || len(manager.Legacy) != 1 {
+		t.Errorf("default = %+v", manager)
+	}
+	manager, err = NewManager(Options{Hasher: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current, ok := manager.Current.(Bcrypt); !ok || current.Cost != 4 {
+		t.Errorf("bcrypt = %+v", manager)
+	}
+	for name, opts := range map[string]Options{
+		"hasher tidak dikenal": {Hasher: "md5"},
+		"memory terlalu kecil": {Argon2MemoryKiB: 1024},
+		"iterasi berlebihan":   {Argon2Iterations: 1000},
+		"cost bcrypt":          {BcryptCost: 3},
+	} {
+		if _, err := NewManager(opts); err == nil {
+			t.Errorf("%s: tidak ada error", name)
+		}
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	log2 := math.Log2
+	tests := []struct {
+		password string
+		want     float64
+	}{
+		{"", 0},
+		{"aaaaaaaa", 1 * log2(26)},
+		{"abcdefgh", 1 * log2(26)},
+		{"87654321", 1 * log2(10)},
+		{"password", 7 * log2(26)},
+		{"Tr0ub4dor&3", 11 * log2(26+26+10+33)},
+		{"kopi☕susu", 8 * log2(26+100)},
+	}
+	for _, tt := range tests {
+		if got := Entropy(tt.password); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Entropy(%q) = %v, ingin %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	corpusFile := filepath.Join(t.TempDir(), "breached.txt")
+	sum := sha1.Sum([]byte("Bocor-Sekali-123"))
+	content := "# korpus test\n\n" + hex.EncodeToString(sum[:]) + ":42\n" +
+		strings.Repeat("A", 40) + "\n"
+	if err := os.WriteFile(corpusFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicy(PolicyOptions{MinLength: 8, MaxLength: 20, MinEntropy: 40, BreachedPasswordsFile: corpusFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Breached.Len() != 2 {
+		t.Fatalf("ukuran korpus = %d", policy.Breached.Len())
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"kuat", "Kopi-Susu-42", nil},
+		{"terlalu pendek", "Ab1!", []string{CodeTooShort}},
+		{"terlalu panjang", strings.Repeat("Ab1!x", 5), []string{CodeTooLong}},
+		{"lemah", "password", []string{CodeTooWeak}},
+		{"urutan", "abcdefghijkl", []string{CodeTooWeak}},
+		{"sama dengan username", "Alice-Wonder", []string{CodeMatchesUser}},
+		{"sama dengan bagian lokal email", "ALICE.W-2000", []string{CodeMatchesUser}},
+		{"bocor", "Bocor-Sekali-123", []string{CodeBreached}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range policy.Validate(tt.password, "alice-wonder", "alice.w-2000@example.com") {
+				got = append(got, violation.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate(%q) = %v, ingin %v", tt.password, got, tt.want)
+			}
+		})
+	}
+
+	violation := policy.Validate("Ab1!")[0]
+	if got := violation.Message("en"); got != "Password must be at least 8 characters" {
+		t.Errorf("Message(en) = %q", got)
+	}
+	if got := violation.Message("fr"); got != "Password minimal 8 karakter" {
+		t.Errorf("Message(fr) = %q", got)
+	}
+}
+
+func TestLoadBreachedCorpus(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"pendek":    "ABC\n",
+		"bukan hex": strings.Repeat("Z", 40) + "\n",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0600)
+		if _, err := LoadBreachedCorpus(path); err == nil || !strings.Contains(err.Error(), ":1:") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+	if _, err := LoadBreachedCorpus(filepath.Join(dir, "tidak-ada")); err == nil {
+		t.Error("file tidak ada: tidak ada error")
+	}
+	if _, err := NewPolicy(PolicyOptions{MinLength: 10, MaxLength: 5}); err == nil {
+		t.Error("NewPolicy min > max: tidak ada error")
+	}
+}
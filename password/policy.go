@@ -0,0 +1,255 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kode pelanggaran kebijakan password.
+const (
+	CodeTooShort    = "password_too_short"
+	CodeTooLong     = "password_too_long"
+	CodeTooWeak     = "password_too_weak"
+	CodeMatchesUser = "password_matches_account"
+	CodeBreached    = "password_breached"
+)
+
+const (
+	defaultMinLength  = 8
+	defaultMaxLength  = 128
+	defaultMinEntropy = 40
+)
+
+// Violation adalah satu aturan kebijakan password yang dilanggar.
+type Violation struct {
+	Code   string
+	Params map[string]interface{}
+}
+
+var violationMessages = map[string]map[string]string{
+	"id": {
+		CodeTooShort:    "Password minimal %v karakter",
+		CodeTooLong:     "Password maksimal %v karakter",
+		CodeTooWeak:     "Password terlalu mudah ditebak, gunakan kombinasi huruf besar, huruf kecil, angka, dan simbol",
+		CodeMatchesUser: "Password tidak boleh sama dengan username atau email",
+		CodeBreached:    "Password ini pernah bocor dalam pelanggaran data, gunakan password lain",
+	},
+	"en": {
+		CodeTooShort:    "Password must be at least %v characters",
+		CodeTooLong:     "Password must be at most %v characters",
+		CodeTooWeak:     "Password is too easy to guess, mix upper and lower case letters, digits and symbols",
+		CodeMatchesUser: "Password must not be the same as your username or email",
+		CodeBreached:    "This password has appeared in a data breach, choose a different one",
+	},
+}
+
+// Message mengembalikan pesan pelanggaran dalam bahasa lang ("id" atau
+// "en"); bahasa lain memakai bahasa Indonesia.
+func (v Violation) Message(lang string) string {
+	messages, ok := violationMessages[lang]
+	if !ok {
+		messages = violationMessages["id"]
+	}
+	switch v.Code {
+	case CodeTooShort:
+		return fmt.Sprintf(messages[v.Code], v.Params["min"])
+	case CodeTooLong:
+		return fmt.Sprintf(messages[v.Code], v.Params["max"])
+	}
+	return messages[v.Code]
+}
+
+// Policy adalah kebijakan kekuatan password.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// MinEntropy adalah perkiraan entropi minimum dalam bit (lihat Entropy).
+	MinEntropy float64
+	// Breached berisi korpus password yang pernah bocor; nil berarti
+	// pemeriksaan dilewati.
+	Breached *BreachedCorpus
+}
+
+// DefaultPolicy mengembalikan kebijakan default tanpa korpus password bocor.
+func DefaultPolicy() *Policy {
+	return &Policy{MinLength: defaultMinLength, MaxLength: defaultMaxLength, MinEntropy: defaultMinEntropy}
+}
+
+// Validate memeriksa password terhadap kebijakan. accountValues (username,
+// email) tidak boleh dipakai sebagai password.
+func (p *Policy) Validate(password string, accountValues ...string) []Violation {
+	var violations []Violation
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, Violation{Code: CodeTooShort, Params: map[string]interface{}{"min": p.MinLength}})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{Code: CodeTooLong, Params: map[string]interface{}{"max": p.MaxLength}})
+	}
+	if matchesAccount(password, accountValues) {
+		violations = append(violations, Violation{Code: CodeMatchesUser})
+	}
+	if length >= p.MinLength && Entropy(password) < p.MinEntropy {
+		violations = append(violations, Violation{Code: CodeTooWeak, Params: map[string]interface{}{"min_entropy": p.MinEntropy}})
+	}
+	if p.Breached != nil && p.Breached.Contains(password) {
+		violations = append(violations, Violation{Code: CodeBreached})
+	}
+	return violations
+}
+
+func matchesAccount(password string, accountValues []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range accountValues {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if lower == value {
+			return true
+		}
+		// Bagian lokal email (sebelum @) juga dianggap sama.
+		if at := strings.LastIndex(value, "@"); at > 0 && lower == value[:at] {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy memperkirakan entropi password dalam bit: panjang efektif dikali
+// log2 ukuran himpunan karakter yang dipakai. Karakter yang mengulang atau
+// melanjutkan urutan karakter sebelumnya ("aaaa", "abcd", "4321") tidak
+// menambah panjang efektif.
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	effective := 0
+	var prev rune
+	for i, r := range []rune(password) {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+		if i == 0 || (r != prev && r != prev+1 && r != prev-1) {
+			effective++
+		}
+		prev = r
+	}
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(effective) * math.Log2(float64(pool))
+}
+
+// BreachedCorpus adalah daftar hash SHA-1 password yang pernah bocor,
+// dikelompokkan per 5 karakter awal hash seperti API k-anonymity Have I Been
+// Pwned. Pencarian hanya membandingkan sisa hash di dalam kelompok prefix.
+type BreachedCorpus struct {
+	suffixes map[string][]string
+	size     int
+}
+
+// LoadBreachedCorpus membaca file korpus dengan satu hash SHA-1 (hex) per
+// baris, opsional diikuti ":jumlah" seperti file unduhan Pwned Passwords.
+// Baris kosong dan baris yang diawali # diabaikan.
+func LoadBreachedCorpus(path string) (*BreachedCorpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	corpus := &BreachedCorpus{suffixes: map[string][]string{}}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash := strings.ToUpper(strings.SplitN(line, ":", 2)[0])
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: hash SHA-1 tidak valid", path, lineNumber)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("%s:%d: hash SHA-1 tidak valid", path, lineNumber)
+		}
+		corpus.suffixes[hash[:5]] = append(corpus.suffixes[hash[:5]], hash[5:])
+		corpus.size++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, suffixes := range corpus.suffixes {
+		sort.Strings(suffixes)
+	}
+	return corpus, nil
+}
+
+// Len mengembalikan jumlah hash di korpus.
+func (b *BreachedCorpus) Len() int {
+	return b.size
+}
+
+// Contains memeriksa apakah password ada di korpus.
+func (b *BreachedCorpus) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	suffixes := b.suffixes[hash[:5]]
+	i := sort.SearchStrings(suffixes, hash[5:])
+	return i < len(suffixes) && suffixes[i] == hash[5:]
+}
+
+// PolicyOptions adalah parameter Policy. Berbeda dengan DefaultPolicy,
+// semua field dipakai apa adanya; BreachedPasswordsFile boleh kosong.
+type PolicyOptions struct {
+	MinLength             int
+	MaxLength             int
+	MinEntropy            float64
+	BreachedPasswordsFile string
+}
+
+// NewPolicy membuat Policy dari opts dan memuat korpus password bocor jika
+// BreachedPasswordsFile diisi.
+func NewPolicy(opts PolicyOptions) (*Policy, error) {
+	if opts.MinLength < 1 || opts.MaxLength < opts.MinLength {
+		return nil, fmt.Errorf("panjang password tidak valid: min %d, max %d", opts.MinLength, opts.MaxLength)
+	}
+	if opts.MinEntropy < 0 {
+		return nil, fmt.Errorf("entropi minimum tidak valid: %v", opts.MinEntropy)
+	}
+	policy := &Policy{MinLength: opts.MinLength, MaxLength: opts.MaxLength, MinEntropy: opts.MinEntropy}
+	if opts.BreachedPasswordsFile != "" {
+		corpus, err := LoadBreachedCorpus(opts.BreachedPasswordsFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = corpus
+	}
+	return policy, nil
+}
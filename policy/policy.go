@@ -0,0 +1,216 @@
+// Package policy berisi aturan otorisasi: role, permission yang dimiliki
+// setiap role, dan aturan kepemilikan resource (feed, comment, chat). Semua
+// pemeriksaan hak akses di controllers sebaiknya melalui package ini.
+package policy
+
+import "social-media-backend/models"
+
+// Role yang tersedia.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission adalah hak akses yang dapat dimiliki sebuah role.
+type Permission string
+
+const (
+	DeleteAnyFeed    Permission = "feeds:delete:any"
+	DeleteAnyComment Permission = "comments:delete:any"
+	DeleteAnyMessage Permission = "messages:delete:any"
+	ManageRoles      Permission = "roles:manage"
+	ViewAuditLog     Permission = "audit:read"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleUser:      {},
+	RoleModerator: {DeleteAnyFeed, DeleteAnyComment, DeleteAnyMessage},
+	RoleAdmin:     {DeleteAnyFeed, DeleteAnyComment, DeleteAnyMessage, ManageRoles, ViewAuditLog},
+}
+
+// Roles mengembalikan daftar role yang valid.
+func Roles() []string {
+	return []string{RoleUser, RoleModerator, RoleAdmin}
+}
+
+// ValidRole memeriksa apakah role dikenal.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleOf mengembalikan role user; user lama tanpa role dianggap RoleUser.
+func RoleOf(user models.User) string {
+	if user.Role == "" {
+		return RoleUser
+	}
+	return user.Role
+}
+
+// Permissions mengembalikan permission yang dimiliki user.
+func Permissions(user models.User) []Permission {
+	return rolePermissions[RoleOf(user)]
+}
+
+// Can memeriksa apakah user memiliki permission p.
+func Can(user models.User, p Permission) bool {
+	for _, granted := range Permissions(user) {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// CanEditFeed: hanya pemilik feed yang boleh mengubah feed.
+func CanEditFeed(user models.User, feed models.Feed) bool {
+	return feed.UserID == user.ID
+}
+
+// CanDeleteFeed: pemilik feed atau moderator.
+func CanDeleteFeed(user models.User, feed models.Feed) bool {
+	return feed.UserID == user.ID || Can(user, DeleteAnyFeed)
+}
+
+// CanEditComment: hanya pengirim comment yang boleh mengubah comment.
+func CanEditComment(user models.User, comment models.Comment) bool {
+	return comment.UserID == user.ID
+}
+
+// CanDeleteComment: pengirim comment atau moderator.
+func CanDeleteComment(user models.User, comment models.Comment) bool {
+	return comment.UserID == user.ID || Can(user, DeleteAnyComment)
+}
+
+// CanDeleteMessage: pengirim pesan atau moderator.
+func CanDeleteMessage(user models.User, message models.Message) bool {
+	return message.UserID == user.ID || Can(user, DeleteAnyMessage)
+}
+
+// IsChatroomMember memeriksa apakah user termasuk peserta chatroom.
+func IsChatroomMember(user models.User, participants []models.User) bool {
+	for _, participant := range participants {
+		if participant.ID == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessChatroom: hanya peserta yang boleh membaca dan mengirim pesan.
+func CanAccessChatroom(user models.User, participants []models.User) bool {
+	return IsChatroomMember(user, participants)
+}
+
+// CanDeleteChatroom: group chat hanya oleh owner, direct chat oleh salah satu
+// pesertanya.
+func CanDeleteChatroom(user models.User, chatroom models.Chatroom, participants []models.User) bool {
+	if chatroom.IsGroup {
+		return chatroom.OwnerID == user.ID
+	}
+	return IsChatroomMember(user, participants)
+}
+
+// CanViewContent: feed, followers, dan following akun publik boleh dilihat
+// siapa saja. Akun privat hanya oleh pemiliknya dan user yang diikuti
+// pemiliknya (ownerFollowsViewer); karena follow tidak memerlukan
+// persetujuan, pemilik akun privat menentukan audiensnya dengan mengikuti
+// user tersebut.
+func CanViewContent(viewer, owner models.User, ownerFollowsViewer bool) bool {
+	return !owner.IsPrivate || viewer.ID == owner.ID || ownerFollowsViewer
+}
+
+// Field profil yang visibilitasnya dapat diatur pemiliknya.
+const (
+	FieldTanggalLahir = "tanggal_lahir"
+	FieldJenisKelamin = "jenis_kelamin"
+	FieldLocation     = "location"
+	FieldPronouns     = "pronouns"
+	FieldLinks        = "links"
+)
+
+// Tingkat visibilitas field profil bagi user lain.
+const (
+	VisibilityPublic   = "public"
+	VisibilityHidden   = "hidden"
+	VisibilityMonthDay = "month_day" // tanggal lahir tanpa tahun
+)
+
+// profileFields memetakan field profil ke visibilitas yang boleh dipilih;
+// elemen pertama adalah default. Tanggal lahir dan jenis kelamin tersembunyi
+// secara default karena sebelumnya hanya terlihat oleh pemilik akun.
+var profileFields = map[string][]string{
+	FieldTanggalLahir: {VisibilityHidden, VisibilityMonthDay, VisibilityPublic},
+	FieldJenisKelamin: {VisibilityHidden, VisibilityPublic},
+	FieldLocation:     {VisibilityPublic, VisibilityHidden},
+	FieldPronouns:     {VisibilityPublic, VisibilityHidden},
+	FieldLinks:        {VisibilityPublic, VisibilityHidden},
+}
+
+// ValidFieldVisibility memeriksa apakah visibility boleh dipilih untuk field.
+func ValidFieldVisibility(field, visibility string) bool {
+	for _, allowed := range profileFields[field] {
+		if allowed == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldVisibility mengembalikan visibilitas field profil user, atau default
+// field tersebut jika user belum mengaturnya.
+func FieldVisibility(user models.User, field string) string {
+	if visibility := user.Visibility[field]; ValidFieldVisibility(field, visibility) {
+		return visibility
+	}
+	if allowed := profileFields[field]; len(allowed) > 0 {
+		return allowed[0]
+	}
+	return VisibilityHidden
+}
+
+// FieldVisibilities mengembalikan visibilitas semua field profil user.
+func FieldVisibilities(user models.User) map[string]string {
+	result := make(map[string]string, len(profileFields))
+	for field := range profileFields {
+		result[field] = FieldVisibility(user, field)
+	}
+	return result
+}
+
+// Scope membatasi apa yang dapat dilakukan sebuah personal access token.
+type Scope string
+
+const (
+	ScopeProfileRead  Scope = "profile:read"
+	ScopeUsersRead    Scope = "users:read"
+	ScopeFollowsRead  Scope = "follows:read"
+	ScopeFollowsWrite Scope = "follows:write"
+	ScopeBlocksWrite  Scope = "blocks:write"
+	ScopeFeedsRead    Scope = "feeds:read"
+	ScopeFeedsWrite   Scope = "feeds:write"
+	ScopeChatRead     Scope = "chat:read"
+	ScopeChatWrite    Scope = "chat:write"
+)
+
+// Scopes mengembalikan daftar scope yang valid.
+func Scopes() []Scope {
+	return []Scope{
+		ScopeProfileRead, ScopeUsersRead,
+		ScopeFollowsRead, ScopeFollowsWrite,
+		ScopeBlocksWrite,
+		ScopeFeedsRead, ScopeFeedsWrite,
+		ScopeChatRead, ScopeChatWrite,
+	}
+}
+
+// ValidScope memeriksa apakah scope dikenal.
+func ValidScope(scope Scope) bool {
+	for _, s := range Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// BlockRepository menyimpan daftar user yang diblokir.
+type BlockRepository interface {
+	// Exists memeriksa apakah blockerID memblokir blockedID.
+	Exists(blockerID, blockedID uint) (bool, error)
+	// Between memeriksa apakah salah satu dari a dan b memblokir yang lain.
+	Between(a, b uint) (bool, error)
+	Create(blockerID, blockedID uint) error
+	Delete(blockerID, blockedID uint) error
+}
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+// NewBlockRepository membuat BlockRepository berbasis GORM.
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Exists(blockerID, blockedID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) Between(a, b uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) Create(blockerID, blockedID uint) error {
+	return r.db.Create(&models.Block{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+func (r *blockRepository) Delete(blockerID, blockedID uint) error {
+	return r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&models.Block{}).Error
+}
+
+// NotBlockedWith menyembunyikan user yang memblokir, atau diblokir oleh,
+// viewerID.
+func NotBlockedWith(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("users.id NOT IN (SELECT blocked_id FROM blocks WHERE blocker_id = ?)", viewerID).
+			Where("users.id NOT IN (SELECT blocker_id FROM blocks WHERE blocked_id = ?)", viewerID)
+	}
+}
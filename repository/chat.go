@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// ChatroomRepository menyimpan chatroom beserta pesertanya.
+type ChatroomRepository interface {
+	FindByID(id uint) (models.Chatroom, error)
+	// ListForUser mengembalikan chatroom yang diikuti userID.
+	ListForUser(userID uint) ([]models.Chatroom, error)
+	Create(chatroom *models.Chatroom) error
+	AddMember(chatroom *models.Chatroom, user *models.User) error
+	Members(chatroom *models.Chatroom) ([]models.User, error)
+	Delete(chatroom *models.Chatroom) error
+}
+
+// MessageRepository menyimpan pesan di chatroom.
+type MessageRepository interface {
+	// ListByChatroom mengembalikan pesan dari user aktif beserta User-nya,
+	// terlama lebih dulu.
+	ListByChatroom(chatroomID uint) ([]models.Message, error)
+	FindByID(id uint) (models.Message, error)
+	Create(message *models.Message) error
+	Delete(message *models.Message) error
+}
+
+type chatroomRepository struct {
+	db *gorm.DB
+}
+
+// NewChatroomRepository membuat ChatroomRepository berbasis GORM.
+func NewChatroomRepository(db *gorm.DB) ChatroomRepository {
+	return &chatroomRepository{db: db}
+}
+
+func (r *chatroomRepository) FindByID(id uint) (models.Chatroom, error) {
+	var chatroom models.Chatroom
+	err := r.db.First(&chatroom, id).Error
+	return chatroom, notFound(err)
+}
+
+func (r *chatroomRepository) ListForUser(userID uint) ([]models.Chatroom, error) {
+	var chatrooms []models.Chatroom
+	err := r.db.Model(&models.User{ID: userID}).Association("Chatrooms").Find(&chatrooms)
+	return chatrooms, err
+}
+
+func (r *chatroomRepository) Create(chatroom *models.Chatroom) error {
+	return r.db.Create(chatroom).Error
+}
+
+func (r *chatroomRepository) AddMember(chatroom *models.Chatroom, user *models.User) error {
+	return r.db.Model(chatroom).Association("Users").Append(user)
+}
+
+func (r *chatroomRepository) Members(chatroom *models.Chatroom) ([]models.User, error) {
+	var members []models.User
+	err := r.db.Model(chatroom).Association("Users").Find(&members)
+	return members, err
+}
+
+func (r *chatroomRepository) Delete(chatroom *models.Chatroom) error {
+	return r.db.Delete(chatroom).Error
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository membuat MessageRepository berbasis GORM.
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) ListByChatroom(chatroomID uint) ([]models.Message, error) {
+	var messages []models.Message
+	err := r.db.Where("chatroom_id = ?", chatroomID).
+		Scopes(ByActiveAuthor).
+		Preload("User").
+		Order("created_at asc").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *messageRepository) FindByID(id uint) (models.Message, error) {
+	var message models.Message
+	err := r.db.First(&message, id).Error
+	return message, notFound(err)
+}
+
+func (r *messageRepository) Create(message *models.Message) error {
+	return r.db.Create(message).Error
+}
+
+func (r *messageRepository) Delete(message *models.Message) error {
+	return r.db.Delete(message).Error
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"strings"
+
+	"social-media-backend/models"
+)
+
+// Filter direktori user, relatif terhadap DirectoryQuery.ViewerID.
+const (
+	DirectoryFollowing = "following" // user yang diikuti viewer
+	DirectoryFollowers = "followers" // user yang mengikuti viewer
+	DirectoryMutuals   = "mutuals"   // user yang saling mengikuti dengan viewer
+)
+
+// Urutan direktori user.
+const (
+	SortUsername  = "username"  // username A-Z
+	SortNewest    = "newest"    // user yang paling baru mendaftar lebih dulu
+	SortRelevance = "relevance" // peringkat pencarian, lalu username
+)
+
+// Peringkat hasil pencarian: username atau fullname diawali kata kunci,
+// mengandung kata kunci, atau cocok secara fuzzy (semua huruf kata kunci
+// muncul berurutan).
+const (
+	RankPrefix = iota
+	RankContains
+	RankFuzzy
+)
+
+// DirectoryQuery adalah parameter direktori user. User yang sedang
+// dinonaktifkan dan user yang saling memblokir dengan ViewerID tidak pernah
+// disertakan.
+type DirectoryQuery struct {
+	ViewerID uint
+	Search   string // huruf kecil; kosong berarti tanpa pencarian
+	Filter   string // kosong atau salah satu Directory*
+	Sort     string // salah satu Sort*
+	After    *DirectoryCursor
+	Limit    int
+}
+
+// DirectoryCursor adalah posisi satu user dalam urutan direktori. Halaman
+// berikutnya dimulai setelah posisi ini.
+type DirectoryCursor struct {
+	Rank     int    `json:"r,omitempty"`
+	Username string `json:"u,omitempty"` // username dalam huruf kecil
+	ID       uint   `json:"i"`
+}
+
+// DirectoryEntry adalah satu user hasil direktori beserta posisinya.
+type DirectoryEntry struct {
+	User   models.User
+	Cursor DirectoryCursor
+}
+
+// SearchRank mengembalikan peringkat user untuk kata kunci search (huruf
+// kecil), atau false jika tidak cocok. Implementasi database memakai aturan
+// yang sama lewat LIKE.
+func SearchRank(user models.User, search string) (int, bool) {
+	username, fullname := strings.ToLower(user.Username), strings.ToLower(user.Fullname)
+	switch {
+	case strings.HasPrefix(username, search) || strings.HasPrefix(fullname, search):
+		return RankPrefix, true
+	case strings.Contains(username, search) || strings.Contains(fullname, search):
+		return RankContains, true
+	case subsequence(username, search) || subsequence(fullname, search):
+		return RankFuzzy, true
+	}
+	return 0, false
+}
+
+func subsequence(s, sub string) bool {
+	for _, r := range sub {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// likeEscaper meng-escape karakter khusus LIKE memakai "!" sebagai karakter
+// escape, yang berlaku sama di MySQL, PostgreSQL, dan SQLite.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// fuzzyPattern membuat pola LIKE yang cocok jika semua huruf search muncul
+// berurutan, misalnya "ali" menjadi "%a%l%i%".
+func fuzzyPattern(search string) string {
+	var b strings.Builder
+	b.WriteString("%")
+	for _, r := range search {
+		b.WriteString(likeEscaper.Replace(string(r)))
+		b.WriteString("%")
+	}
+	return b.String()
+}
+
+// directoryRow adalah hasil query direktori: user beserta kolom urutan.
+type directoryRow struct {
+	models.User
+	SortUsername string
+	SearchRank   int
+}
+
+func (r *userRepository) Directory(q DirectoryQuery) ([]DirectoryEntry, error) {
+	inner := r.db.Model(&models.User{}).Scopes(ActiveUsers, NotBlockedWith(q.ViewerID))
+
+	rank, rankVars := "0", []interface{}{}
+	if q.Search != "" {
+		prefix := likeEscaper.Replace(q.Search) + "%"
+		contains := "%" + prefix
+		fuzzy := fuzzyPattern(q.Search)
+		rank = "CASE WHEN LOWER(users.username) LIKE ? ESCAPE '!' OR LOWER(users.fullname) LIKE ? ESCAPE '!' THEN 0 " +
+			"WHEN LOWER(users.username) LIKE ? ESCAPE '!' OR LOWER(users.fullname) LIKE ? ESCAPE '!' THEN 1 ELSE 2 END"
+		rankVars = append(rankVars, prefix, prefix, contains, contains)
+		inner = inner.Where("LOWER(users.username) LIKE ? ESCAPE '!' OR LOWER(users.fullname) LIKE ? ESCAPE '!'", fuzzy, fuzzy)
+	}
+
+	following := "SELECT following_id FROM follows WHERE follower_id = ? AND deleted_at IS NULL"
+	followers := "SELECT follower_id FROM follows WHERE following_id = ? AND deleted_at IS NULL"
+	switch q.Filter {
+	case DirectoryFollowing:
+		inner = inner.Where("users.id IN ("+following+")", q.ViewerID)
+	case DirectoryFollowers:
+		inner = inner.Where("users.id IN ("+followers+")", q.ViewerID)
+	case DirectoryMutuals:
+		inner = inner.Where("users.id IN ("+following+")", q.ViewerID).
+			Where("users.id IN ("+followers+")", q.ViewerID)
+	}
+	inner = inner.Select("users.*, LOWER(users.username) AS sort_username, "+rank+" AS search_rank", rankVars...)
+
+	// Kolom urutan dihitung di subquery agar dapat dipakai di WHERE untuk
+	// cursor.
+	query := r.db.Table("(?) AS users", inner)
+	switch q.Sort {
+	case SortNewest:
+		query = query.Order("users.id DESC")
+		if q.After != nil {
+			query = query.Where("users.id < ?", q.After.ID)
+		}
+	case SortRelevance:
+		query = query.Order("search_rank, sort_username, users.id")
+		if a := q.After; a != nil {
+			query = query.Where("search_rank > ? OR (search_rank = ? AND (sort_username > ? OR (sort_username = ? AND users.id > ?)))",
+				a.Rank, a.Rank, a.Username, a.Username, a.ID)
+		}
+	default:
+		query = query.Order("sort_username, users.id")
+		if a := q.After; a != nil {
+			query = query.Where("sort_username > ? OR (sort_username = ? AND users.id > ?)", a.Username, a.Username, a.ID)
+		}
+	}
+
+	var rows []directoryRow
+	if err := query.Limit(q.Limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]DirectoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, DirectoryEntry{
+			User:   row.User,
+			Cursor: DirectoryCursor{Rank: row.SearchRank, Username: row.SortUsername, ID: row.ID},
+		})
+	}
+	return entries, nil
+}
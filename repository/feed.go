@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// FeedRepository menyimpan feed.
+type FeedRepository interface {
+	// List mengembalikan feed milik user aktif yang boleh dilihat viewerID
+	// (lihat VisibleTo) dan tidak saling memblokir dengannya, terbaru lebih
+	// dulu, beserta User, Comments (dan User-nya) serta Reactions dari user
+	// aktif yang tidak saling memblokir dengan viewerID.
+	List(viewerID uint) ([]models.Feed, error)
+	// ListByAuthor mengembalikan paling banyak limit feed milik authorID
+	// dengan ID lebih kecil dari beforeID (0 berarti dari awal), terbaru
+	// lebih dulu, dengan relasi yang sama seperti List untuk viewerID.
+	ListByAuthor(viewerID, authorID, beforeID uint, limit int) ([]models.Feed, error)
+	// CountByAuthor menghitung feed milik authorID.
+	CountByAuthor(authorID uint) (int64, error)
+	FindByID(id uint) (models.Feed, error)
+	// FindActiveByID hanya menemukan feed yang pemiliknya tidak sedang
+	// dinonaktifkan.
+	FindActiveByID(id uint) (models.Feed, error)
+	Create(feed *models.Feed) error
+	Save(feed *models.Feed) error
+	Delete(feed *models.Feed) error
+}
+
+// CommentRepository menyimpan comment pada feed.
+type CommentRepository interface {
+	FindByID(id uint) (models.Comment, error)
+	Create(comment *models.Comment) error
+	Save(comment *models.Comment) error
+	Delete(comment *models.Comment) error
+}
+
+// ReactionRepository menyimpan reaksi (like/dislike) user pada feed. Setiap
+// user memiliki paling banyak satu reaksi per feed.
+type ReactionRepository interface {
+	Find(feedID, userID uint) (models.Reaction, error)
+	Create(reaction *models.Reaction) error
+	Save(reaction *models.Reaction) error
+	Delete(reaction *models.Reaction) error
+}
+
+type feedRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedRepository membuat FeedRepository berbasis GORM.
+func NewFeedRepository(db *gorm.DB) FeedRepository {
+	return &feedRepository{db: db}
+}
+
+func (r *feedRepository) List(viewerID uint) ([]models.Feed, error) {
+	var feeds []models.Feed
+	err := r.db.Scopes(ByActiveAuthor, VisibleTo(viewerID), r.withFeedDetails(viewerID)).
+		Where("feeds.user_id IN (?)", r.unblocked(viewerID)).
+		Order("created_at desc").
+		Find(&feeds).Error
+	return feeds, err
+}
+
+func (r *feedRepository) ListByAuthor(viewerID, authorID, beforeID uint, limit int) ([]models.Feed, error) {
+	query := r.db.Scopes(r.withFeedDetails(viewerID)).Where("user_id = ?", authorID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var feeds []models.Feed
+	err := query.Order("id desc").Limit(limit).Find(&feeds).Error
+	return feeds, err
+}
+
+func (r *feedRepository) CountByAuthor(authorID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Feed{}).Where("user_id = ?", authorID).Count(&count).Error
+	return count, err
+}
+
+// withFeedDetails memuat penulis, comment, dan reaksi dari user aktif yang
+// tidak saling memblokir dengan viewerID.
+func (r *feedRepository) withFeedDetails(viewerID uint) func(*gorm.DB) *gorm.DB {
+	byUnblockedAuthor := func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id IN (?)", r.unblocked(viewerID))
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload("User").
+			Preload("Comments", ByActiveAuthor, byUnblockedAuthor).
+			Preload("Comments.User").
+			Preload("Reactions", ByActiveAuthor, byUnblockedAuthor)
+	}
+}
+
+// unblocked adalah subquery ID user yang tidak saling memblokir dengan
+// viewerID.
+func (r *feedRepository) unblocked(viewerID uint) *gorm.DB {
+	return r.db.Model(&models.User{}).Select("users.id").Scopes(NotBlockedWith(viewerID))
+}
+
+// VisibleTo menyembunyikan feed milik akun privat dari viewerID, kecuali
+// viewerID adalah pemiliknya atau diikuti pemiliknya. Aturan ini sama dengan
+// policy.CanViewContent.
+func VisibleTo(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("feeds.user_id NOT IN (SELECT id FROM users WHERE is_private = ? AND id <> ? "+
+			"AND id NOT IN (SELECT follower_id FROM follows WHERE following_id = ? AND deleted_at IS NULL))",
+			true, viewerID, viewerID)
+	}
+}
+
+func (r *feedRepository) FindByID(id uint) (models.Feed, error) {
+	var feed models.Feed
+	err := r.db.First(&feed, id).Error
+	return feed, notFound(err)
+}
+
+func (r *feedRepository) FindActiveByID(id uint) (models.Feed, error) {
+	var feed models.Feed
+	err := r.db.Scopes(ByActiveAuthor).First(&feed, id).Error
+	return feed, notFound(err)
+}
+
+func (r *feedRepository) Create(feed *models.Feed) error {
+	return r.db.Create(feed).Error
+}
+
+func (r *feedRepository) Save(feed *models.Feed) error {
+	return r.db.Save(feed).Error
+}
+
+func (r *feedRepository) Delete(feed *models.Feed) error {
+	return r.db.Delete(feed).Error
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository membuat CommentRepository berbasis GORM.
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) FindByID(id uint) (models.Comment, error) {
+	var comment models.Comment
+	err := r.db.First(&comment, id).Error
+	return comment, notFound(err)
+}
+
+func (r *commentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *commentRepository) Save(comment *models.Comment) error {
+	return r.db.Save(comment).Error
+}
+
+func (r *commentRepository) Delete(comment *models.Comment) error {
+	return r.db.Delete(comment).Error
+}
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+// NewReactionRepository membuat ReactionRepository berbasis GORM.
+func NewReactionRepository(db *gorm.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Find(feedID, userID uint) (models.Reaction, error) {
+	var reaction models.Reaction
+	err := r.db.Where("feed_id = ? AND user_id = ?", feedID, userID).First(&reaction).Error
+	return reaction, notFound(err)
+}
+
+func (r *reactionRepository) Create(reaction *models.Reaction) error {
+	return r.db.Create(reaction).Error
+}
+
+func (r *reactionRepository) Save(reaction *models.Reaction) error {
+	return r.db.Save(reaction).Error
+}
+
+func (r *reactionRepository) Delete(reaction *models.Reaction) error {
+	return r.db.Delete(reaction).Error
+}
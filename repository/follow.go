@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// FollowRepository menyimpan relasi follow antar user.
+type FollowRepository interface {
+	// Exists memeriksa apakah followerID mengikuti followingID.
+	Exists(followerID, followingID uint) (bool, error)
+	Create(followerID, followingID uint) error
+	Delete(followerID, followingID uint) error
+	// Followers dan Following mengembalikan user aktif yang mengikuti, atau
+	// diikuti oleh, userID.
+	Followers(userID uint) ([]models.User, error)
+	Following(userID uint) ([]models.User, error)
+	// FollowersPage dan FollowingPage mengembalikan satu halaman Followers
+	// atau Following.
+	FollowersPage(q FollowQuery) ([]FollowEntry, error)
+	FollowingPage(q FollowQuery) ([]FollowEntry, error)
+	// Count mengembalikan jumlah user aktif yang mengikuti, dan diikuti oleh,
+	// userID.
+	Count(userID uint) (followers, following int64, err error)
+}
+
+// FollowQuery adalah parameter satu halaman followers atau following UserID,
+// diurutkan dari follow terbaru. User yang sedang dinonaktifkan dan user yang
+// saling memblokir dengan ViewerID tidak disertakan.
+type FollowQuery struct {
+	UserID   uint
+	ViewerID uint
+	After    *FollowCursor
+	Limit    int
+}
+
+// FollowCursor adalah posisi satu user dalam daftar followers/following.
+// Halaman berikutnya dimulai setelah posisi ini.
+type FollowCursor struct {
+	At time.Time `json:"t"` // waktu follow
+	ID uint      `json:"i"` // ID user
+}
+
+// FollowEntry adalah satu user dalam daftar followers/following beserta
+// posisinya.
+type FollowEntry struct {
+	User   models.User
+	Cursor FollowCursor
+}
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository membuat FollowRepository berbasis GORM.
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Exists(followerID, followingID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepository) Create(followerID, followingID uint) error {
+	return r.db.Create(&models.Follow{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+		CreatedAt:   time.Now(),
+	}).Error
+}
+
+// Delete menghapus baris follow secara permanen agar user dapat mengikuti
+// kembali tanpa bentrok primary key.
+func (r *followRepository) Delete(followerID, followingID uint) error {
+	return r.db.Unscoped().
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Delete(&models.Follow{}).Error
+}
+
+func (r *followRepository) Followers(userID uint) ([]models.User, error) {
+	return r.users("follows.follower_id = users.id", "follows.following_id = ?", userID)
+}
+
+func (r *followRepository) Following(userID uint) ([]models.User, error) {
+	return r.users("follows.following_id = users.id", "follows.follower_id = ?", userID)
+}
+
+func (r *followRepository) users(join, where string, userID uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN follows ON "+join).
+		Where(where, userID).
+		Where("follows.deleted_at IS NULL").
+		Scopes(ActiveUsers).
+		Order("follows.created_at DESC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *followRepository) FollowersPage(q FollowQuery) ([]FollowEntry, error) {
+	return r.page("follows.follower_id = users.id", "follows.following_id = ?", q)
+}
+
+func (r *followRepository) FollowingPage(q FollowQuery) ([]FollowEntry, error) {
+	return r.page("follows.following_id = users.id", "follows.follower_id = ?", q)
+}
+
+// followRow adalah user beserta waktu follow-nya.
+type followRow struct {
+	models.User
+	FollowedAt time.Time
+}
+
+func (r *followRepository) page(join, where string, q FollowQuery) ([]FollowEntry, error) {
+	query := r.db.Model(&models.User{}).
+		Select("users.*, follows.created_at AS followed_at").
+		Joins("JOIN follows ON "+join).
+		Where(where, q.UserID).
+		Where("follows.deleted_at IS NULL").
+		Scopes(ActiveUsers, NotBlockedWith(q.ViewerID)).
+		Order("follows.created_at DESC, users.id DESC")
+	if a := q.After; a != nil {
+		query = query.Where("follows.created_at < ? OR (follows.created_at = ? AND users.id < ?)", a.At, a.At, a.ID)
+	}
+	var rows []followRow
+	if err := query.Limit(q.Limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]FollowEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, FollowEntry{
+			User:   row.User,
+			Cursor: FollowCursor{At: row.FollowedAt, ID: row.ID},
+		})
+	}
+	return entries, nil
+}
+
+func (r *followRepository) Count(userID uint) (followers, following int64, err error) {
+	if followers, err = r.count("follows.follower_id = users.id", "follows.following_id = ?", userID); err != nil {
+		return 0, 0, err
+	}
+	following, err = r.count("follows.following_id = users.id", "follows.follower_id = ?", userID)
+	return followers, following, err
+}
+
+func (r *followRepository) count(join, where string, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Joins("JOIN follows ON "+join).
+		Where(where, userID).
+		Where("follows.deleted_at IS NULL").
+		Scopes(ActiveUsers).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,753 @@
+// Package memory berisi implementasi in-memory dari repository untuk test.
+// Semua repository dari satu Store berbagi data yang sama, sehingga aturan
+// seperti menyembunyikan konten user yang dinonaktifkan berlaku sama seperti
+// pada database.
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/repository"
+)
+
+type followKey struct {
+	followerID, followingID uint
+}
+
+type blockKey struct {
+	blockerID, blockedID uint
+}
+
+// Store menyimpan data semua repository di memori. Aman dipakai dari
+// beberapa goroutine.
+type Store struct {
+	mu        sync.Mutex
+	nextID    uint
+	users     map[uint]models.User
+	follows   map[followKey]time.Time
+	blocks    map[blockKey]time.Time
+	feeds     map[uint]models.Feed
+	comments  map[uint]models.Comment
+	reactions map[uint]models.Reaction
+	chatrooms map[uint]models.Chatroom
+	members   map[uint][]uint
+	messages  map[uint]models.Message
+}
+
+// NewStore membuat Store kosong.
+func NewStore() *Store {
+	return &Store{
+		users:     map[uint]models.User{},
+		follows:   map[followKey]time.Time{},
+		blocks:    map[blockKey]time.Time{},
+		feeds:     map[uint]models.Feed{},
+		comments:  map[uint]models.Comment{},
+		reactions: map[uint]models.Reaction{},
+		chatrooms: map[uint]models.Chatroom{},
+		members:   map[uint][]uint{},
+		messages:  map[uint]models.Message{},
+	}
+}
+
+// Repositories mengembalikan semua repository yang memakai Store ini.
+func (s *Store) Repositories() repository.Repositories {
+	return repository.Repositories{
+		Users:     userRepository{s},
+		Follows:   followRepository{s},
+		Blocks:    blockRepository{s},
+		Feeds:     feedRepository{s},
+		Comments:  commentRepository{s},
+		Reactions: reactionRepository{s},
+		Chatrooms: chatroomRepository{s},
+		Messages:  messageRepository{s},
+	}
+}
+
+// AddUser menyimpan user untuk keperluan test dan mengembalikannya dengan ID
+// yang sudah terisi.
+func (s *Store) AddUser(user models.User) models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user.ID == 0 {
+		user.ID = s.id()
+	} else if user.ID > s.nextID {
+		s.nextID = user.ID
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	s.users[user.ID] = user
+	return user
+}
+
+// id membuat ID baru; dipanggil dengan mu terkunci.
+func (s *Store) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// active memeriksa apakah user ada dan tidak sedang dinonaktifkan; dipanggil
+// dengan mu terkunci.
+func (s *Store) active(userID uint) bool {
+	user, ok := s.users[userID]
+	return ok && user.DeactivatedAt == nil
+}
+
+type userRepository struct{ s *Store }
+
+func (r userRepository) FindByID(id uint) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[id]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r userRepository) FindActiveByID(id uint) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if !r.s.active(id) {
+		return models.User{}, repository.ErrNotFound
+	}
+	return r.s.users[id], nil
+}
+
+func (r userRepository) FindActiveByUsername(username string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, user := range r.s.users {
+		if strings.EqualFold(user.Username, username) && r.s.active(id) {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (r userRepository) Directory(q repository.DirectoryQuery) ([]repository.DirectoryEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var entries []repository.DirectoryEntry
+	for id, user := range r.s.users {
+		if !r.s.active(id) || r.s.blocked(q.ViewerID, id) {
+			continue
+		}
+		_, following := r.s.follows[followKey{q.ViewerID, id}]
+		_, follower := r.s.follows[followKey{id, q.ViewerID}]
+		if (q.Filter == repository.DirectoryFollowing && !following) ||
+			(q.Filter == repository.DirectoryFollowers && !follower) ||
+			(q.Filter == repository.DirectoryMutuals && !(following && follower)) {
+			continue
+		}
+		cursor := repository.DirectoryCursor{Username: strings.ToLower(user.Username), ID: id}
+		if q.Search != "" {
+			rank, ok := repository.SearchRank(user, q.Search)
+			if !ok {
+				continue
+			}
+			cursor.Rank = rank
+		}
+		entries = append(entries, repository.DirectoryEntry{User: user, Cursor: cursor})
+	}
+	less := func(a, b repository.DirectoryCursor) bool {
+		switch q.Sort {
+		case repository.SortNewest:
+			return a.ID > b.ID
+		case repository.SortRelevance:
+			if a.Rank != b.Rank {
+				return a.Rank < b.Rank
+			}
+		}
+		if a.Username != b.Username {
+			return a.Username < b.Username
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(entries, func(i, j int) bool { return less(entries[i].Cursor, entries[j].Cursor) })
+	if q.After != nil {
+		start := sort.Search(len(entries), func(i int) bool { return less(*q.After, entries[i].Cursor) })
+		entries = entries[start:]
+	}
+	if len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+func (r userRepository) Taken(username, email string, exceptID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, user := range r.s.users {
+		if id == exceptID {
+			continue
+		}
+		if username != "" && strings.EqualFold(user.Username, username) {
+			return true, nil
+		}
+		if email != "" && user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r userRepository) UpdateProfile(user *models.User, changes models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	for _, target := range []*models.User{&stored, user} {
+		if changes.Fullname != "" {
+			target.Fullname = changes.Fullname
+		}
+		if changes.Username != "" {
+			target.Username = changes.Username
+		}
+		if changes.Email != "" {
+			target.Email = changes.Email
+		}
+		if changes.JenisKelamin != "" {
+			target.JenisKelamin = changes.JenisKelamin
+		}
+		if changes.PhotoProfile != "" {
+			target.PhotoProfile = changes.PhotoProfile
+		}
+		if changes.Banner != "" {
+			target.Banner = changes.Banner
+		}
+		if changes.PhotoVariants != nil {
+			target.PhotoVariants = changes.PhotoVariants
+		}
+		if changes.BannerVariants != nil {
+			target.BannerVariants = changes.BannerVariants
+		}
+		if changes.TanggalLahir != nil {
+			target.TanggalLahir = changes.TanggalLahir
+		}
+		target.UpdatedAt = time.Now()
+	}
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r userRepository) UpdateDetails(user *models.User, details models.ProfileDetails) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.ProfileDetails = details
+	user.ProfileDetails = details
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r userRepository) SetPrivate(user *models.User, private bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.IsPrivate = private
+	user.IsPrivate = private
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r userRepository) MarkEmailUnverified(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.EmailVerifiedAt = nil
+	user.EmailVerifiedAt = nil
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r userRepository) SetPassword(user *models.User, hash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Password = hash
+	user.Password = hash
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+// blocked memeriksa apakah a dan b saling memblokir; dipanggil dengan mu
+// terkunci.
+func (s *Store) blocked(a, b uint) bool {
+	_, ab := s.blocks[blockKey{a, b}]
+	_, ba := s.blocks[blockKey{b, a}]
+	return ab || ba
+}
+
+type followRepository struct{ s *Store }
+
+func (r followRepository) Exists(followerID, followingID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, ok := r.s.follows[followKey{followerID, followingID}]
+	return ok, nil
+}
+
+func (r followRepository) Create(followerID, followingID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.follows[followKey{followerID, followingID}] = time.Now()
+	return nil
+}
+
+func (r followRepository) Delete(followerID, followingID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.follows, followKey{followerID, followingID})
+	return nil
+}
+
+func (r followRepository) Followers(userID uint) ([]models.User, error) {
+	return r.users(func(key followKey) (uint, bool) { return key.followerID, key.followingID == userID })
+}
+
+func (r followRepository) Following(userID uint) ([]models.User, error) {
+	return r.users(func(key followKey) (uint, bool) { return key.followingID, key.followerID == userID })
+}
+
+func (r followRepository) users(match func(followKey) (uint, bool)) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	type followed struct {
+		user models.User
+		at   time.Time
+	}
+	var found []followed
+	for key, at := range r.s.follows {
+		if id, ok := match(key); ok && r.s.active(id) {
+			found = append(found, followed{r.s.users[id], at})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].at.After(found[j].at) })
+	users := make([]models.User, 0, len(found))
+	for _, f := range found {
+		users = append(users, f.user)
+	}
+	return users, nil
+}
+
+func (r followRepository) FollowersPage(q repository.FollowQuery) ([]repository.FollowEntry, error) {
+	return r.page(q, func(key followKey) (uint, bool) { return key.followerID, key.followingID == q.UserID })
+}
+
+func (r followRepository) FollowingPage(q repository.FollowQuery) ([]repository.FollowEntry, error) {
+	return r.page(q, func(key followKey) (uint, bool) { return key.followingID, key.followerID == q.UserID })
+}
+
+func (r followRepository) page(q repository.FollowQuery, match func(followKey) (uint, bool)) ([]repository.FollowEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var entries []repository.FollowEntry
+	for key, at := range r.s.follows {
+		if id, ok := match(key); ok && r.s.active(id) && !r.s.blocked(q.ViewerID, id) {
+			entries = append(entries, repository.FollowEntry{
+				User:   r.s.users[id],
+				Cursor: repository.FollowCursor{At: at, ID: id},
+			})
+		}
+	}
+	less := func(a, b repository.FollowCursor) bool {
+		if !a.At.Equal(b.At) {
+			return a.At.After(b.At)
+		}
+		return a.ID > b.ID
+	}
+	sort.Slice(entries, func(i, j int) bool { return less(entries[i].Cursor, entries[j].Cursor) })
+	if q.After != nil {
+		start := sort.Search(len(entries), func(i int) bool { return less(*q.After, entries[i].Cursor) })
+		entries = entries[start:]
+	}
+	if len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+func (r followRepository) Count(userID uint) (followers, following int64, err error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for key := range r.s.follows {
+		if key.followingID == userID && r.s.active(key.followerID) {
+			followers++
+		}
+		if key.followerID == userID && r.s.active(key.followingID) {
+			following++
+		}
+	}
+	return followers, following, nil
+}
+
+type feedRepository struct{ s *Store }
+
+func (r feedRepository) List(viewerID uint) ([]models.Feed, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var feeds []models.Feed
+	for _, feed := range r.s.feeds {
+		if !r.s.active(feed.UserID) || !r.s.visible(viewerID, feed.UserID) || r.s.blocked(viewerID, feed.UserID) {
+			continue
+		}
+		feeds = append(feeds, r.s.feedDetails(viewerID, feed))
+	}
+	sort.Slice(feeds, func(i, j int) bool {
+		if !feeds[i].CreatedAt.Equal(feeds[j].CreatedAt) {
+			return feeds[i].CreatedAt.After(feeds[j].CreatedAt)
+		}
+		return feeds[i].ID > feeds[j].ID
+	})
+	return feeds, nil
+}
+
+func (r feedRepository) ListByAuthor(viewerID, authorID, beforeID uint, limit int) ([]models.Feed, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var feeds []models.Feed
+	for _, feed := range r.s.feeds {
+		if feed.UserID == authorID && (beforeID == 0 || feed.ID < beforeID) {
+			feeds = append(feeds, r.s.feedDetails(viewerID, feed))
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].ID > feeds[j].ID })
+	if len(feeds) > limit {
+		feeds = feeds[:limit]
+	}
+	return feeds, nil
+}
+
+func (r feedRepository) CountByAuthor(authorID uint) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for _, feed := range r.s.feeds {
+		if feed.UserID == authorID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// feedDetails mengisi penulis, comment, dan reaksi dari user aktif yang tidak
+// saling memblokir dengan viewerID pada feed; dipanggil dengan mu terkunci.
+func (s *Store) feedDetails(viewerID uint, feed models.Feed) models.Feed {
+	feed.User = s.users[feed.UserID]
+	feed.Comments = nil
+	for _, comment := range s.comments {
+		if comment.FeedID == feed.ID && s.active(comment.UserID) && !s.blocked(viewerID, comment.UserID) {
+			comment.User = s.users[comment.UserID]
+			feed.Comments = append(feed.Comments, comment)
+		}
+	}
+	sort.Slice(feed.Comments, func(i, j int) bool { return feed.Comments[i].ID < feed.Comments[j].ID })
+	feed.Reactions = nil
+	for _, reaction := range s.reactions {
+		if reaction.FeedID == feed.ID && s.active(reaction.UserID) && !s.blocked(viewerID, reaction.UserID) {
+			feed.Reactions = append(feed.Reactions, reaction)
+		}
+	}
+	sort.Slice(feed.Reactions, func(i, j int) bool { return feed.Reactions[i].ID < feed.Reactions[j].ID })
+	return feed
+}
+
+// visible memeriksa apakah konten ownerID boleh dilihat viewerID, sama
+// seperti repository.VisibleTo; dipanggil dengan mu terkunci.
+func (s *Store) visible(viewerID, ownerID uint) bool {
+	_, followed := s.follows[followKey{ownerID, viewerID}]
+	return !s.users[ownerID].IsPrivate || viewerID == ownerID || followed
+}
+
+func (r feedRepository) FindByID(id uint) (models.Feed, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	feed, ok := r.s.feeds[id]
+	if !ok {
+		return models.Feed{}, repository.ErrNotFound
+	}
+	return feed, nil
+}
+
+func (r feedRepository) FindActiveByID(id uint) (models.Feed, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	feed, ok := r.s.feeds[id]
+	if !ok || !r.s.active(feed.UserID) {
+		return models.Feed{}, repository.ErrNotFound
+	}
+	return feed, nil
+}
+
+func (r feedRepository) Create(feed *models.Feed) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	feed.ID = r.s.id()
+	if feed.CreatedAt.IsZero() {
+		feed.CreatedAt = time.Now()
+	}
+	r.s.feeds[feed.ID] = *feed
+	return nil
+}
+
+func (r feedRepository) Save(feed *models.Feed) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.feeds[feed.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.feeds[feed.ID] = *feed
+	return nil
+}
+
+func (r feedRepository) Delete(feed *models.Feed) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.feeds, feed.ID)
+	return nil
+}
+
+type commentRepository struct{ s *Store }
+
+func (r commentRepository) FindByID(id uint) (models.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return models.Comment{}, repository.ErrNotFound
+	}
+	return comment, nil
+}
+
+func (r commentRepository) Create(comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment.ID = r.s.id()
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	r.s.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r commentRepository) Save(comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.comments[comment.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r commentRepository) Delete(comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.comments, comment.ID)
+	return nil
+}
+
+type reactionRepository struct{ s *Store }
+
+func (r reactionRepository) Find(feedID, userID uint) (models.Reaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, reaction := range r.s.reactions {
+		if reaction.FeedID == feedID && reaction.UserID == userID {
+			return reaction, nil
+		}
+	}
+	return models.Reaction{}, repository.ErrNotFound
+}
+
+func (r reactionRepository) Create(reaction *models.Reaction) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reaction.ID = r.s.id()
+	r.s.reactions[reaction.ID] = *reaction
+	return nil
+}
+
+func (r reactionRepository) Save(reaction *models.Reaction) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.reactions[reaction.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.reactions[reaction.ID] = *reaction
+	return nil
+}
+
+func (r reactionRepository) Delete(reaction *models.Reaction) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.reactions, reaction.ID)
+	return nil
+}
+
+type chatroomRepository struct{ s *Store }
+
+func (r chatroomRepository) FindByID(id uint) (models.Chatroom, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	chatroom, ok := r.s.chatrooms[id]
+	if !ok {
+		return models.Chatroom{}, repository.ErrNotFound
+	}
+	return chatroom, nil
+}
+
+func (r chatroomRepository) ListForUser(userID uint) ([]models.Chatroom, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var chatrooms []models.Chatroom
+	for id, chatroom := range r.s.chatrooms {
+		for _, memberID := range r.s.members[id] {
+			if memberID == userID {
+				chatrooms = append(chatrooms, chatroom)
+				break
+			}
+		}
+	}
+	sort.Slice(chatrooms, func(i, j int) bool { return chatrooms[i].ID < chatrooms[j].ID })
+	return chatrooms, nil
+}
+
+func (r chatroomRepository) Create(chatroom *models.Chatroom) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	chatroom.ID = r.s.id()
+	r.s.chatrooms[chatroom.ID] = *chatroom
+	return nil
+}
+
+func (r chatroomRepository) AddMember(chatroom *models.Chatroom, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.chatrooms[chatroom.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, memberID := range r.s.members[chatroom.ID] {
+		if memberID == user.ID {
+			return nil
+		}
+	}
+	r.s.members[chatroom.ID] = append(r.s.members[chatroom.ID], user.ID)
+	return nil
+}
+
+func (r chatroomRepository) Members(chatroom *models.Chatroom) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var members []models.User
+	for _, memberID := range r.s.members[chatroom.ID] {
+		if user, ok := r.s.users[memberID]; ok {
+			members = append(members, user)
+		}
+	}
+	return members, nil
+}
+
+func (r chatroomRepository) Delete(chatroom *models.Chatroom) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.chatrooms, chatroom.ID)
+	return nil
+}
+
+type messageRepository struct{ s *Store }
+
+func (r messageRepository) ListByChatroom(chatroomID uint) ([]models.Message, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var messages []models.Message
+	for _, message := range r.s.messages {
+		if message.ChatroomID == chatroomID && r.s.active(message.UserID) {
+			message.User = r.s.users[message.UserID]
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+func (r messageRepository) FindByID(id uint) (models.Message, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	message, ok := r.s.messages[id]
+	if !ok {
+		return models.Message{}, repository.ErrNotFound
+	}
+	return message, nil
+}
+
+func (r messageRepository) Create(message *models.Message) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	message.ID = r.s.id()
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	r.s.messages[message.ID] = *message
+	return nil
+}
+
+func (r messageRepository) Delete(message *models.Message) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.messages, message.ID)
+	return nil
+}
+
+type blockRepository struct{ s *Store }
+
+func (r blockRepository) Exists(blockerID, blockedID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, ok := r.s.blocks[blockKey{blockerID, blockedID}]
+	return ok, nil
+}
+
+func (r blockRepository) Between(a, b uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.blocked(a, b), nil
+}
+
+func (r blockRepository) Create(blockerID, blockedID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.blocks[blockKey{blockerID, blockedID}] = time.Now()
+	return nil
+}
+
+func (r blockRepository) Delete(blockerID, blockedID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.blocks, blockKey{blockerID, blockedID})
+	return nil
+}
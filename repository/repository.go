@@ -0,0 +1,60 @@
+// Package repository memisahkan akses database dari handler dan service.
+// Setiap entitas memiliki interface repository dengan implementasi GORM di
+// package ini dan implementasi in-memory di package repository/memory untuk
+// test.
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound dikembalikan ketika data yang dicari tidak ada (atau sudah
+// dihapus).
+var ErrNotFound = errors.New("data tidak ditemukan")
+
+// Repositories mengelompokkan semua repository yang dibutuhkan service.
+type Repositories struct {
+	Users     UserRepository
+	Follows   FollowRepository
+	Blocks    BlockRepository
+	Feeds     FeedRepository
+	Comments  CommentRepository
+	Reactions ReactionRepository
+	Chatrooms ChatroomRepository
+	Messages  MessageRepository
+}
+
+// NewGorm membuat semua repository yang disimpan di database db.
+func NewGorm(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:     NewUserRepository(db),
+		Follows:   NewFollowRepository(db),
+		Blocks:    NewBlockRepository(db),
+		Feeds:     NewFeedRepository(db),
+		Comments:  NewCommentRepository(db),
+		Reactions: NewReactionRepository(db),
+		Chatrooms: NewChatroomRepository(db),
+		Messages:  NewMessageRepository(db),
+	}
+}
+
+// ActiveUsers hanya menyertakan user yang tidak sedang dinonaktifkan.
+func ActiveUsers(db *gorm.DB) *gorm.DB {
+	return db.Where("users.deactivated_at IS NULL")
+}
+
+// ByActiveAuthor menyembunyikan baris (feed, comment, reaction, message) milik
+// user yang sedang dinonaktifkan.
+func ByActiveAuthor(db *gorm.DB) *gorm.DB {
+	return db.Where("user_id NOT IN (SELECT id FROM users WHERE deactivated_at IS NOT NULL)")
+}
+
+// notFound menerjemahkan gorm.ErrRecordNotFound menjadi ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
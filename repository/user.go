@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"social-media-backend/models"
+)
+
+// UserRepository menyimpan data user.
+type UserRepository interface {
+	FindByID(id uint) (models.User, error)
+	// FindActiveByID mencari user yang tidak sedang dinonaktifkan.
+	FindActiveByID(id uint) (models.User, error)
+	// FindActiveByUsername mencari user yang tidak sedang dinonaktifkan
+	// berdasarkan username, tanpa membedakan huruf besar/kecil.
+	FindActiveByUsername(username string) (models.User, error)
+	// Directory mengembalikan satu halaman direktori user.
+	Directory(q DirectoryQuery) ([]DirectoryEntry, error)
+	// Taken memeriksa apakah username (tanpa membedakan huruf besar/kecil)
+	// atau email sudah dipakai user lain selain exceptID, termasuk akun yang
+	// sudah dihapus.
+	Taken(username, email string, exceptID uint) (bool, error)
+	// UpdateProfile mengubah field profil user yang tidak kosong di changes.
+	UpdateProfile(user *models.User, changes models.User) error
+	// UpdateDetails mengganti seluruh ProfileDetails user, termasuk field
+	// yang dikosongkan.
+	UpdateDetails(user *models.User, details models.ProfileDetails) error
+	// SetPrivate mengubah pengaturan akun privat user.
+	SetPrivate(user *models.User, private bool) error
+	// MarkEmailUnverified mengosongkan EmailVerifiedAt.
+	MarkEmailUnverified(user *models.User) error
+	// SetPassword mengganti hash password user.
+	SetPassword(user *models.User, hash string) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository membuat UserRepository berbasis GORM.
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) FindByID(id uint) (models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+	return user, notFound(err)
+}
+
+func (r *userRepository) FindActiveByID(id uint) (models.User, error) {
+	var user models.User
+	err := r.db.Scopes(ActiveUsers).First(&user, id).Error
+	return user, notFound(err)
+}
+
+func (r *userRepository) FindActiveByUsername(username string) (models.User, error) {
+	var user models.User
+	err := r.db.Scopes(ActiveUsers).Where("LOWER(username) = ?", strings.ToLower(username)).First(&user).Error
+	return user, notFound(err)
+}
+
+func (r *userRepository) Taken(username, email string, exceptID uint) (bool, error) {
+	query := r.db.Unscoped().Model(&models.User{}).Where("id <> ?", exceptID)
+	switch {
+	case username != "" && email != "":
+		query = query.Where("LOWER(username) = ? OR email = ?", strings.ToLower(username), email)
+	case username != "":
+		query = query.Where("LOWER(username) = ?", strings.ToLower(username))
+	case email != "":
+		query = query.Where("email = ?", email)
+	default:
+		return false, nil
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *userRepository) UpdateProfile(user *models.User, changes models.User) error {
+	return r.db.Model(user).Updates(changes).Error
+}
+
+func (r *userRepository) UpdateDetails(user *models.User, details models.ProfileDetails) error {
+	err := r.db.Model(user).
+		Select("bio", "location", "pronouns", "links", "visibility").
+		Updates(&models.User{ProfileDetails: details}).Error
+	if err != nil {
+		return err
+	}
+	user.ProfileDetails = details
+	return nil
+}
+
+func (r *userRepository) SetPrivate(user *models.User, private bool) error {
+	return r.db.Model(user).Update("is_private", private).Error
+}
+
+func (r *userRepository) MarkEmailUnverified(user *models.User) error {
+	return r.db.Model(user).Update("email_verified_at", nil).Error
+}
+
+func (r *userRepository) SetPassword(user *models.User, hash string) error {
+	return r.db.Model(user).Update("password", hash).Error
+}
@@ -0,0 +1,68 @@
+// Package richtext mengenali mention (@username) dan hashtag (#topik) di teks
+// buatan user, misalnya bio profil, agar client dapat menampilkannya sebagai
+// tautan.
+package richtext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Jenis entity.
+const (
+	Mention = "mention"
+	Hashtag = "hashtag"
+)
+
+// Entity adalah satu mention atau hashtag. Start dan End adalah offset rune
+// (bukan byte) di teks asli, termasuk karakter @ atau #; Value tanpa awalan
+// tersebut.
+type Entity struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Parse mengembalikan mention dan hashtag di text sesuai urutan kemunculan.
+// Awalan hanya dikenali di awal teks atau setelah karakter yang bukan huruf,
+// angka, atau garis bawah, sehingga alamat email tidak dianggap mention.
+// Mention berisi huruf, angka, garis bawah, atau titik (titik di awal dan di
+// akhir tidak termasuk); hashtag berisi huruf, angka, atau garis bawah dan harus
+// mengandung minimal satu huruf.
+func Parse(text string) []Entity {
+	runes := []rune(text)
+	entities := []Entity{}
+	for i := 0; i < len(runes); i++ {
+		var kind string
+		switch runes[i] {
+		case '@':
+			kind = Mention
+		case '#':
+			kind = Hashtag
+		default:
+			continue
+		}
+		if (i > 0 && isWord(runes[i-1])) || i+1 == len(runes) || !isWord(runes[i+1]) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && (isWord(runes[end]) || (kind == Mention && runes[end] == '.')) {
+			end++
+		}
+		for kind == Mention && end > i+1 && runes[end-1] == '.' {
+			end--
+		}
+		value := string(runes[i+1 : end])
+		if value == "" || (kind == Hashtag && strings.IndexFunc(value, unicode.IsLetter) < 0) {
+			continue
+		}
+		entities = append(entities, Entity{Type: kind, Value: value, Start: i, End: end})
+		i = end - 1
+	}
+	return entities
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
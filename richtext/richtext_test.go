@@ -0,0 +1,50 @@
+package richtext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // type:value@start-end, dipisah koma
+	}{
+		{"", ""},
+		{"halo @bob dan #golang", "mention:bob@5-9,hashtag:golang@14-21"},
+		{"@awal", "mention:awal@0-5"},
+		{"email a@b.com bukan mention", ""},
+		{"titik di akhir @bob.", "mention:bob@15-19"},
+		{"@a.b.c... lalu", "mention:a.b.c@0-6"},
+		{"@.bob", ""},
+		{"@ # @@ ##", ""},
+		{"#2024 bukan hashtag, #2024pemilu hashtag", "hashtag:2024pemilu@21-32"},
+		{"#kopi_susu#teh", "hashtag:kopi_susu@0-10"},
+		{"(@bob) [#go]", "mention:bob@1-5,hashtag:go@8-11"},
+		{"x#y x@y", ""},
+		{"kopi ☕ @budi #kopi", "mention:budi@7-12,hashtag:kopi@13-18"},
+		{"日本語 #東京 @ユーザー", "hashtag:東京@4-7,mention:ユーザー@8-13"},
+		{"🎉🎉 #pesta", "hashtag:pesta@3-9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			entities := Parse(tt.text)
+			if entities == nil {
+				t.Fatal("Parse mengembalikan nil, ingin slice kosong")
+			}
+			var got []string
+			runes := []rune(tt.text)
+			for _, e := range entities {
+				got = append(got, fmt.Sprintf("%s:%s@%d-%d", e.Type, e.Value, e.Start, e.End))
+				// Offset rune menunjuk ke teks entity lengkap dengan awalannya.
+				if string(runes[e.Start+1:e.End]) != e.Value {
+					t.Errorf("runes[%d:%d] = %q, ingin %q", e.Start+1, e.End, string(runes[e.Start+1:e.End]), e.Value)
+				}
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("Parse(%q) = %v, ingin %s", tt.text, got, tt.want)
+			}
+		})
+	}
+}
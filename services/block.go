@@ -0,0 +1,53 @@
+package services
+
+import (
+	"social-media-backend/models"
+	"social-media-backend/repository"
+)
+
+// BlockService mengelola daftar user yang diblokir.
+type BlockService struct {
+	users   repository.UserRepository
+	follows repository.FollowRepository
+	blocks  repository.BlockRepository
+}
+
+// NewBlockService membuat BlockService.
+func NewBlockService(users repository.UserRepository, follows repository.FollowRepository, blocks repository.BlockRepository) *BlockService {
+	return &BlockService{users: users, follows: follows, blocks: blocks}
+}
+
+// Block membuat user memblokir targetID. Relasi follow di kedua arah ikut
+// dihapus.
+func (s *BlockService) Block(user models.User, targetID uint) error {
+	target, err := s.users.FindByID(targetID)
+	if err != nil {
+		return lookupError(err, "User tidak ditemukan")
+	}
+	if target.ID == user.ID {
+		return newError(CodeInvalid, "Anda tidak dapat memblokir diri sendiri")
+	}
+	blocked, err := s.blocks.Exists(user.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return newError(CodeInvalid, "Anda sudah memblokir user ini")
+	}
+	if err := s.blocks.Create(user.ID, target.ID); err != nil {
+		return err
+	}
+	if err := s.follows.Delete(user.ID, target.ID); err != nil {
+		return err
+	}
+	return s.follows.Delete(target.ID, user.ID)
+}
+
+// Unblock membuka blokir user terhadap targetID.
+func (s *BlockService) Unblock(user models.User, targetID uint) error {
+	target, err := s.users.FindByID(targetID)
+	if err != nil {
+		return lookupError(err, "User tidak ditemukan")
+	}
+	return s.blocks.Delete(user.ID, target.ID)
+}
@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"testing"
+
+	"social-media-backend/models"
+	"social-media-backend/repository/memory"
+	"social-media-backend/services"
+)
+
+func TestBlock(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.New(store.Repositories())
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+
+	if err := svc.Follows.Follow(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Follows.Follow(bob, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Blocks.Block(alice, alice.ID); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("blokir diri sendiri: err = %v", err)
+	}
+	if err := svc.Blocks.Block(alice, 999); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("blokir user yang tidak ada: err = %v", err)
+	}
+	if err := svc.Blocks.Block(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Blocks.Block(alice, bob.ID); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("blokir dua kali: err = %v", err)
+	}
+
+	// Blokir menghapus follow di kedua arah dan mencegah follow baru.
+	if followers, _ := svc.Follows.Followers(alice); len(followers) != 0 {
+		t.Fatalf("Followers(alice) = %+v", followers)
+	}
+	if following, _ := svc.Follows.Following(alice); len(following) != 0 {
+		t.Fatalf("Following(alice) = %+v", following)
+	}
+	for _, pair := range [][2]models.User{{alice, bob}, {bob, alice}} {
+		if err := svc.Follows.Follow(pair[0], pair[1].ID); !services.IsCode(err, services.CodeForbidden) {
+			t.Fatalf("follow %s -> %s: err = %v", pair[0].Username, pair[1].Username, err)
+		}
+	}
+	page, _ := svc.Users.Directory(bob, services.DirectoryParams{})
+	if len(page.Users) != 1 || page.Users[0].ID != bob.ID {
+		t.Fatalf("direktori bob = %+v", page.Users)
+	}
+
+	if err := svc.Blocks.Unblock(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Follows.Follow(bob, alice.ID); err != nil {
+		t.Fatalf("follow setelah blokir dibuka: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository"
+)
+
+// ChatService mengelola chatroom, keanggotaannya, dan pesan.
+type ChatService struct {
+	users     repository.UserRepository
+	chatrooms repository.ChatroomRepository
+	messages  repository.MessageRepository
+	blocks    repository.BlockRepository
+}
+
+// NewChatService membuat ChatService.
+func NewChatService(users repository.UserRepository, chatrooms repository.ChatroomRepository, messages repository.MessageRepository, blocks repository.BlockRepository) *ChatService {
+	return &ChatService{users: users, chatrooms: chatrooms, messages: messages, blocks: blocks}
+}
+
+// NewChatroom berisi data pembuatan chatroom.
+type NewChatroom struct {
+	IsGroup bool
+	Name    string // wajib untuk group chat
+	UserIDs []uint // peserta selain pembuat chatroom
+}
+
+// Create membuat chatroom dengan user sebagai owner dan peserta pertama.
+// Direct chat harus berisi tepat satu peserta lain. User yang tidak
+// ditemukan atau sedang dinonaktifkan dilewati, sedangkan user yang saling
+// memblokir dengan user membuat chatroom ditolak.
+func (s *ChatService) Create(user models.User, input NewChatroom) (models.Chatroom, error) {
+	if !input.IsGroup && len(input.UserIDs) != 1 {
+		return models.Chatroom{}, newError(CodeInvalid, "Untuk direct chat, harus ada tepat satu user ID")
+	}
+	if input.IsGroup && input.Name == "" {
+		return models.Chatroom{}, newError(CodeInvalid, "Nama grup harus diisi untuk group chat")
+	}
+	var members []models.User
+	for _, uid := range input.UserIDs {
+		member, err := s.users.FindActiveByID(uid)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return models.Chatroom{}, err
+		}
+		blocked, err := s.blocks.Between(user.ID, member.ID)
+		if err != nil {
+			return models.Chatroom{}, err
+		}
+		if blocked {
+			return models.Chatroom{}, newError(CodeForbidden, "Anda tidak dapat membuat chat dengan user ini")
+		}
+		members = append(members, member)
+	}
+	chatroom := models.Chatroom{
+		IsGroup:   input.IsGroup,
+		Name:      input.Name,
+		CreatedAt: time.Now(),
+		OwnerID:   user.ID,
+	}
+	if err := s.chatrooms.Create(&chatroom); err != nil {
+		return chatroom, err
+	}
+	if err := s.chatrooms.AddMember(&chatroom, &user); err != nil {
+		return chatroom, err
+	}
+	for i := range members {
+		if err := s.chatrooms.AddMember(&chatroom, &members[i]); err != nil {
+			return chatroom, err
+		}
+	}
+	return chatroom, nil
+}
+
+// List mengembalikan chatroom yang diikuti user.
+func (s *ChatService) List(user models.User) ([]models.Chatroom, error) {
+	return s.chatrooms.ListForUser(user.ID)
+}
+
+// Open mencari chatroom dan memastikan user adalah pesertanya. Hanya peserta
+// yang boleh membaca dan mengirim pesan.
+func (s *ChatService) Open(user models.User, chatroomID uint) (models.Chatroom, error) {
+	chatroom, _, err := s.open(user, chatroomID)
+	return chatroom, err
+}
+
+// open seperti Open, tetapi juga mengembalikan peserta chatroom.
+func (s *ChatService) open(user models.User, chatroomID uint) (models.Chatroom, []models.User, error) {
+	chatroom, err := s.chatrooms.FindByID(chatroomID)
+	if err != nil {
+		return chatroom, nil, lookupError(err, "Chatroom tidak ditemukan")
+	}
+	members, err := s.chatrooms.Members(&chatroom)
+	if err != nil {
+		return chatroom, nil, err
+	}
+	if !policy.CanAccessChatroom(user, members) {
+		return chatroom, nil, newError(CodeForbidden, "Anda bukan peserta chatroom ini")
+	}
+	return chatroom, members, nil
+}
+
+// Messages mengembalikan pesan di chatroom, terlama lebih dulu.
+func (s *ChatService) Messages(user models.User, chatroomID uint) ([]models.Message, error) {
+	chatroom, err := s.Open(user, chatroomID)
+	if err != nil {
+		return nil, err
+	}
+	return s.messages.ListByChatroom(chatroom.ID)
+}
+
+// Send mengirim pesan user ke chatroom. file adalah path file yang sudah
+// diupload, boleh kosong. Pesan di direct chat ditolak jika user dan
+// peserta lainnya saling memblokir.
+func (s *ChatService) Send(user models.User, chatroomID uint, text, file string) (models.Message, error) {
+	if text == "" {
+		return models.Message{}, newError(CodeInvalid, "Pesan tidak boleh kosong")
+	}
+	chatroom, members, err := s.open(user, chatroomID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if !chatroom.IsGroup {
+		for _, member := range members {
+			if member.ID == user.ID {
+				continue
+			}
+			blocked, err := s.blocks.Between(user.ID, member.ID)
+			if err != nil {
+				return models.Message{}, err
+			}
+			if blocked {
+				return models.Message{}, newError(CodeForbidden, "Anda tidak dapat mengirim pesan ke user ini")
+			}
+		}
+	}
+	message := models.Message{
+		Message:    text,
+		File:       file,
+		ChatroomID: chatroom.ID,
+		UserID:     user.ID,
+		CreatedAt:  time.Now(),
+	}
+	err = s.messages.Create(&message)
+	return message, err
+}
+
+// Delete menghapus chatroom. Group chat hanya dapat dihapus owner, direct
+// chat oleh pesertanya.
+func (s *ChatService) Delete(user models.User, chatroomID uint) error {
+	chatroom, err := s.chatrooms.FindByID(chatroomID)
+	if err != nil {
+		return lookupError(err, "Chatroom tidak ditemukan")
+	}
+	members, err := s.chatrooms.Members(&chatroom)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeleteChatroom(user, chatroom, members) {
+		if chatroom.IsGroup {
+			return newError(CodeForbidden, "Anda tidak memiliki hak untuk menghapus group chat ini")
+		}
+		return newError(CodeForbidden, "Anda tidak memiliki hak untuk menghapus chatroom ini")
+	}
+	return s.chatrooms.Delete(&chatroom)
+}
+
+// DeleteMessage menghapus pesan. Pengirim pesan atau moderator yang dapat
+// menghapusnya.
+func (s *ChatService) DeleteMessage(user models.User, messageID uint) error {
+	message, err := s.messages.FindByID(messageID)
+	if err != nil {
+		return lookupError(err, "Pesan tidak ditemukan")
+	}
+	if !policy.CanDeleteMessage(user, message) {
+		return newError(CodeForbidden, "Anda tidak memiliki hak untuk menghapus pesan ini")
+	}
+	return s.messages.Delete(&message)
+}
@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository/memory"
+	"social-media-backend/services"
+)
+
+func TestChatMembership(t *testing.T) {
+	store := memory.NewStore()
+	chats := services.New(store.Repositories()).Chats
+	now := time.Now()
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+	eve := store.AddUser(models.User{Username: "eve"})
+	gone := store.AddUser(models.User{Username: "gone", DeactivatedAt: &now})
+
+	if _, err := chats.Create(alice, services.NewChatroom{UserIDs: []uint{bob.ID, eve.ID}}); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("direct chat dengan dua user: err = %v", err)
+	}
+	if _, err := chats.Create(alice, services.NewChatroom{IsGroup: true}); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("group chat tanpa nama: err = %v", err)
+	}
+
+	direct, err := chats.Create(alice, services.NewChatroom{UserIDs: []uint{bob.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chats.Send(bob, direct.ID, "halo", ""); err != nil {
+		t.Fatalf("peserta mengirim pesan: %v", err)
+	}
+	if _, err := chats.Send(eve, direct.ID, "halo", ""); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("bukan peserta mengirim pesan: err = %v", err)
+	}
+	if _, err := chats.Messages(eve, direct.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("bukan peserta membaca pesan: err = %v", err)
+	}
+	if _, err := chats.Send(alice, direct.ID, "", ""); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("pesan kosong: err = %v", err)
+	}
+	messages, err := chats.Messages(alice, direct.ID)
+	if err != nil || len(messages) != 1 || messages[0].User.ID != bob.ID {
+		t.Fatalf("Messages = %+v, err = %v", messages, err)
+	}
+
+	// User nonaktif dilewati saat chatroom dibuat.
+	group, err := chats.Create(alice, services.NewChatroom{IsGroup: true, Name: "tim", UserIDs: []uint{bob.ID, gone.ID, 999}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chats.Open(gone, group.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("user nonaktif ikut menjadi peserta: err = %v", err)
+	}
+	rooms, _ := chats.List(bob)
+	if len(rooms) != 2 {
+		t.Fatalf("chatroom bob = %d, ingin 2", len(rooms))
+	}
+
+	// Group chat hanya dapat dihapus owner, direct chat oleh pesertanya.
+	if err := chats.Delete(bob, group.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("peserta menghapus group chat: err = %v", err)
+	}
+	if err := chats.Delete(alice, group.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := chats.Delete(eve, direct.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("bukan peserta menghapus direct chat: err = %v", err)
+	}
+	if err := chats.Delete(bob, direct.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chats.Open(alice, direct.ID); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("chatroom yang sudah dihapus: err = %v", err)
+	}
+}
+
+func TestDeleteMessage(t *testing.T) {
+	store := memory.NewStore()
+	chats := services.New(store.Repositories()).Chats
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+	moderator := store.AddUser(models.User{Username: "mod", Role: policy.RoleModerator})
+
+	room, _ := chats.Create(alice, services.NewChatroom{UserIDs: []uint{bob.ID}})
+	first, _ := chats.Send(alice, room.ID, "satu", "")
+	second, _ := chats.Send(alice, room.ID, "dua", "")
+
+	if err := chats.DeleteMessage(bob, first.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("hapus pesan orang lain: err = %v", err)
+	}
+	if err := chats.DeleteMessage(alice, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := chats.DeleteMessage(moderator, second.ID); err != nil {
+		t.Fatalf("moderator boleh menghapus pesan: %v", err)
+	}
+	if err := chats.DeleteMessage(alice, first.ID); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("pesan yang sudah dihapus: err = %v", err)
+	}
+}
+
+func TestChatBlocks(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.New(store.Repositories())
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+	carol := store.AddUser(models.User{Username: "carol"})
+
+	direct, err := svc.Chats.Create(alice, services.NewChatroom{UserIDs: []uint{bob.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := svc.Chats.Create(alice, services.NewChatroom{IsGroup: true, Name: "grup", UserIDs: []uint{bob.ID, carol.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Blocks.Block(bob, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		user  models.User
+		input services.NewChatroom
+	}{
+		{"direct chat ke user yang memblokir", alice, services.NewChatroom{UserIDs: []uint{bob.ID}}},
+		{"direct chat ke user yang diblokir", bob, services.NewChatroom{UserIDs: []uint{alice.ID}}},
+		{"group chat dengan user yang memblokir", alice, services.NewChatroom{IsGroup: true, Name: "baru", UserIDs: []uint{carol.ID, bob.ID}}},
+	} {
+		if _, err := svc.Chats.Create(c.user, c.input); !services.IsCode(err, services.CodeForbidden) {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+	if rooms, _ := svc.Chats.List(carol); len(rooms) != 1 {
+		t.Errorf("chatroom yang ditolak tetap dibuat: %+v", rooms)
+	}
+
+	for _, user := range []models.User{alice, bob} {
+		if _, err := svc.Chats.Send(user, direct.ID, "halo", ""); !services.IsCode(err, services.CodeForbidden) {
+			t.Errorf("%s mengirim direct chat setelah blokir: err = %v", user.Username, err)
+		}
+	}
+	if _, err := svc.Chats.Send(alice, group.ID, "halo", ""); err != nil {
+		t.Errorf("pesan group chat tetap boleh: %v", err)
+	}
+
+	if err := svc.Blocks.Unblock(bob, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Chats.Send(alice, direct.ID, "halo lagi", ""); err != nil {
+		t.Fatalf("direct chat setelah blokir dibuka: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository"
+)
+
+// Jenis reaksi pada feed.
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// ReactionResult menjelaskan perubahan yang terjadi setelah React.
+type ReactionResult int
+
+const (
+	ReactionAdded   ReactionResult = iota + 1 // reaksi baru dibuat
+	ReactionRemoved                           // reaksi yang sama dikirim ulang sehingga dihapus
+	ReactionChanged                           // reaksi lain diganti dengan reaksi ini
+)
+
+// FeedService mengelola feed, comment, dan reaksi.
+type FeedService struct {
+	feeds     repository.FeedRepository
+	comments  repository.CommentRepository
+	reactions repository.ReactionRepository
+	users     repository.UserRepository
+	follows   repository.FollowRepository
+	blocks    repository.BlockRepository
+}
+
+// NewFeedService membuat FeedService.
+func NewFeedService(feeds repository.FeedRepository, comments repository.CommentRepository, reactions repository.ReactionRepository, users repository.UserRepository, follows repository.FollowRepository, blocks repository.BlockRepository) *FeedService {
+	return &FeedService{feeds: feeds, comments: comments, reactions: reactions, users: users, follows: follows, blocks: blocks}
+}
+
+// List mengembalikan feed yang boleh dilihat viewer beserta user, comment,
+// dan reaksinya. Konten milik user yang sedang dinonaktifkan, feed akun
+// privat yang tidak mengikuti viewer, dan feed user yang saling memblokir
+// dengan viewer disembunyikan.
+func (s *FeedService) List(viewer models.User) ([]models.Feed, error) {
+	return s.feeds.List(viewer.ID)
+}
+
+// Create membuat feed baru milik user. files adalah path file yang sudah
+// diupload.
+func (s *FeedService) Create(user models.User, text string, files []string) (models.Feed, error) {
+	feed := models.Feed{
+		Feed:      text,
+		File:      strings.Join(files, ","),
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+	}
+	err := s.feeds.Create(&feed)
+	return feed, err
+}
+
+// Editable mencari feed dan memastikan user boleh mengubahnya. Handler
+// memanggilnya sebelum menyimpan file upload.
+func (s *FeedService) Editable(user models.User, feedID uint) (models.Feed, error) {
+	feed, err := s.feeds.FindByID(feedID)
+	if err != nil {
+		return feed, lookupError(err, "Feed tidak ditemukan")
+	}
+	if !policy.CanEditFeed(user, feed) {
+		return feed, newError(CodeForbidden, "Anda tidak memiliki hak untuk mengubah feed ini")
+	}
+	return feed, nil
+}
+
+// Update mengubah teks feed. File lama hanya diganti jika files tidak kosong.
+func (s *FeedService) Update(user models.User, feedID uint, text string, files []string) (models.Feed, error) {
+	feed, err := s.Editable(user, feedID)
+	if err != nil {
+		return feed, err
+	}
+	if len(files) > 0 {
+		feed.File = strings.Join(files, ",")
+	}
+	feed.Feed = text
+	feed.UpdatedAt = time.Now()
+	err = s.feeds.Save(&feed)
+	return feed, err
+}
+
+// Delete menghapus feed (soft delete). Pemilik feed atau moderator yang dapat
+// menghapus feed.
+func (s *FeedService) Delete(user models.User, feedID uint) error {
+	feed, err := s.feeds.FindByID(feedID)
+	if err != nil {
+		return lookupError(err, "Feed tidak ditemukan")
+	}
+	if !policy.CanDeleteFeed(user, feed) {
+		return newError(CodeForbidden, "Anda tidak memiliki hak untuk menghapus feed ini")
+	}
+	return s.feeds.Delete(&feed)
+}
+
+// CreateComment menambahkan comment user pada feed yang boleh dilihat user:
+// pemiliknya aktif, tidak saling memblokir dengan user, dan jika akunnya
+// privat, mengikuti user.
+func (s *FeedService) CreateComment(user models.User, feedID uint, text, file string) (models.Comment, error) {
+	feed, err := s.reachable(user, feedID)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	comment := models.Comment{
+		Comment:   text,
+		File:      file,
+		FeedID:    feed.ID,
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.comments.Create(&comment); err != nil {
+		return comment, err
+	}
+	comment.User = user
+	return comment, nil
+}
+
+// UpdateComment mengubah teks comment. Hanya pengirim comment yang dapat
+// mengubahnya.
+func (s *FeedService) UpdateComment(user models.User, commentID uint, text string) (models.Comment, error) {
+	comment, err := s.comments.FindByID(commentID)
+	if err != nil {
+		return comment, lookupError(err, "Comment tidak ditemukan")
+	}
+	if !policy.CanEditComment(user, comment) {
+		return comment, newError(CodeForbidden, "Anda tidak memiliki hak untuk mengedit comment ini")
+	}
+	comment.Comment = text
+	comment.UpdatedAt = time.Now()
+	err = s.comments.Save(&comment)
+	return comment, err
+}
+
+// DeleteComment menghapus comment. Pemilik comment atau moderator yang dapat
+// menghapusnya.
+func (s *FeedService) DeleteComment(user models.User, commentID uint) error {
+	comment, err := s.comments.FindByID(commentID)
+	if err != nil {
+		return lookupError(err, "Comment tidak ditemukan")
+	}
+	if !policy.CanDeleteComment(user, comment) {
+		return newError(CodeForbidden, "Anda tidak memiliki hak untuk menghapus comment ini")
+	}
+	return s.comments.Delete(&comment)
+}
+
+// React memberikan reaksi user pada feed dengan perilaku toggle: reaksi yang
+// sama dikirim dua kali akan menghapus reaksi, reaksi berbeda menggantikan
+// reaksi sebelumnya. Aturan akses feed sama dengan CreateComment.
+func (s *FeedService) React(user models.User, feedID uint, kind string) (ReactionResult, error) {
+	if kind != ReactionLike && kind != ReactionDislike {
+		return 0, newError(CodeInvalid, "Reaksi tidak valid")
+	}
+	feed, err := s.reachable(user, feedID)
+	if err != nil {
+		return 0, err
+	}
+	reaction, err := s.reactions.Find(feed.ID, user.ID)
+	switch {
+	case err == nil && reaction.Reaction == kind:
+		return ReactionRemoved, s.reactions.Delete(&reaction)
+	case err == nil:
+		reaction.Reaction = kind
+		return ReactionChanged, s.reactions.Save(&reaction)
+	case !errors.Is(err, repository.ErrNotFound):
+		return 0, err
+	}
+	reaction = models.Reaction{
+		FeedID:    feed.ID,
+		UserID:    user.ID,
+		Reaction:  kind,
+		CreatedAt: time.Now(),
+	}
+	return ReactionAdded, s.reactions.Create(&reaction)
+}
+
+// reachable mencari feed yang pemiliknya aktif. Feed milik user yang saling
+// memblokir dengan user dan feed akun privat yang tidak boleh dilihat user
+// (policy.CanViewContent) diperlakukan seperti feed yang tidak ada, sama seperti
+// di List.
+func (s *FeedService) reachable(user models.User, feedID uint) (models.Feed, error) {
+	feed, err := s.feeds.FindActiveByID(feedID)
+	if err != nil {
+		return feed, lookupError(err, "Feed tidak ditemukan")
+	}
+	blocked, err := s.blocks.Between(user.ID, feed.UserID)
+	if err != nil {
+		return feed, err
+	}
+	if blocked {
+		return feed, newError(CodeNotFound, "Feed tidak ditemukan")
+	}
+	owner, err := s.users.FindByID(feed.UserID)
+	if err != nil {
+		return feed, lookupError(err, "Feed tidak ditemukan")
+	}
+	followedBy, err := s.follows.Exists(owner.ID, user.ID)
+	if err != nil {
+		return feed, err
+	}
+	if !policy.CanViewContent(user, owner, followedBy) {
+		return feed, newError(CodeNotFound, "Feed tidak ditemukan")
+	}
+	return feed, nil
+}
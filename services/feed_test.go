@@ -0,0 +1,274 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository/memory"
+	"social-media-backend/services"
+)
+
+func newFeedService(t *testing.T) (*services.FeedService, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	return services.New(store.Repositories()).Feeds, store
+}
+
+func TestFeedOwnership(t *testing.T) {
+	feeds, store := newFeedService(t)
+	owner := store.AddUser(models.User{Username: "owner"})
+	other := store.AddUser(models.User{Username: "other"})
+	moderator := store.AddUser(models.User{Username: "mod", Role: policy.RoleModerator})
+
+	feed, err := feeds.Create(owner, "halo", []string{"a.jpg", "b.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.File != "a.jpg,b.jpg" {
+		t.Fatalf("File = %q", feed.File)
+	}
+
+	if _, err := feeds.Update(other, feed.ID, "diubah", nil); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("update oleh user lain: err = %v", err)
+	}
+	if _, err := feeds.Update(moderator, feed.ID, "diubah", nil); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("moderator tidak boleh mengubah feed: err = %v", err)
+	}
+	updated, err := feeds.Update(owner, feed.ID, "diubah", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Feed != "diubah" || updated.File != "a.jpg,b.jpg" {
+		t.Fatalf("update tanpa file baru: %+v", updated)
+	}
+	if _, err := feeds.Update(owner, 999, "x", nil); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("feed tidak ada: err = %v", err)
+	}
+
+	if err := feeds.Delete(other, feed.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("hapus oleh user lain: err = %v", err)
+	}
+	if err := feeds.Delete(moderator, feed.ID); err != nil {
+		t.Fatalf("moderator boleh menghapus feed: %v", err)
+	}
+	if err := feeds.Delete(owner, feed.ID); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("feed yang sudah dihapus: err = %v", err)
+	}
+}
+
+func TestCommentOwnership(t *testing.T) {
+	feeds, store := newFeedService(t)
+	owner := store.AddUser(models.User{Username: "owner"})
+	commenter := store.AddUser(models.User{Username: "commenter"})
+	admin := store.AddUser(models.User{Username: "admin", Role: policy.RoleAdmin})
+
+	feed, _ := feeds.Create(owner, "halo", nil)
+	comment, err := feeds.CreateComment(commenter, feed.ID, "mantap", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.User.ID != commenter.ID {
+		t.Fatalf("comment.User = %+v", comment.User)
+	}
+	if _, err := feeds.UpdateComment(owner, comment.ID, "x"); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("pemilik feed tidak boleh mengedit comment orang lain: err = %v", err)
+	}
+	if _, err := feeds.UpdateComment(commenter, comment.ID, "mantap sekali"); err != nil {
+		t.Fatal(err)
+	}
+	if err := feeds.DeleteComment(owner, comment.ID); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("hapus comment oleh user lain: err = %v", err)
+	}
+	if err := feeds.DeleteComment(admin, comment.ID); err != nil {
+		t.Fatalf("admin boleh menghapus comment: %v", err)
+	}
+	if _, err := feeds.CreateComment(commenter, 999, "x", ""); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("comment pada feed yang tidak ada: err = %v", err)
+	}
+}
+
+func TestReactionToggle(t *testing.T) {
+	feeds, store := newFeedService(t)
+	owner := store.AddUser(models.User{Username: "owner"})
+	fan := store.AddUser(models.User{Username: "fan"})
+	feed, _ := feeds.Create(owner, "halo", nil)
+
+	steps := []struct {
+		kind  string
+		want  services.ReactionResult
+		count int
+	}{
+		{services.ReactionLike, services.ReactionAdded, 1},
+		{services.ReactionDislike, services.ReactionChanged, 1},
+		{services.ReactionDislike, services.ReactionRemoved, 0},
+		{services.ReactionLike, services.ReactionAdded, 1},
+		{services.ReactionLike, services.ReactionRemoved, 0},
+	}
+	for i, step := range steps {
+		got, err := feeds.React(fan, feed.ID, step.kind)
+		if err != nil {
+			t.Fatalf("langkah %d: %v", i, err)
+		}
+		if got != step.want {
+			t.Fatalf("langkah %d (%s): hasil = %v, ingin %v", i, step.kind, got, step.want)
+		}
+		list, _ := feeds.List(fan)
+		if len(list[0].Reactions) != step.count {
+			t.Fatalf("langkah %d: %d reaksi, ingin %d", i, len(list[0].Reactions), step.count)
+		}
+	}
+
+	if _, err := feeds.React(fan, feed.ID, "love"); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("reaksi tidak dikenal: err = %v", err)
+	}
+}
+
+func TestDeactivatedAuthorHidden(t *testing.T) {
+	feeds, store := newFeedService(t)
+	now := time.Now()
+	active := store.AddUser(models.User{Username: "active"})
+	gone := store.AddUser(models.User{Username: "gone", DeactivatedAt: &now})
+
+	visible, _ := feeds.Create(active, "terlihat", nil)
+	hidden, _ := feeds.Create(gone, "tersembunyi", nil)
+	if _, err := feeds.CreateComment(gone, visible.ID, "tersembunyi", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := feeds.List(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != visible.ID {
+		t.Fatalf("List = %+v", list)
+	}
+	if len(list[0].Comments) != 0 {
+		t.Fatalf("comment dari user nonaktif ikut tampil: %+v", list[0].Comments)
+	}
+	if _, err := feeds.React(active, hidden.ID, services.ReactionLike); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("reaksi pada feed user nonaktif: err = %v", err)
+	}
+}
+
+func TestBlockedAuthorHidden(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.New(store.Repositories())
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+	carol := store.AddUser(models.User{Username: "carol"})
+
+	aliceFeed, _ := svc.Feeds.Create(alice, "dari alice", nil)
+	bobFeed, _ := svc.Feeds.Create(bob, "dari bob", nil)
+	if err := svc.Blocks.Block(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		viewer models.User
+		want   []uint
+	}{
+		{alice, []uint{aliceFeed.ID}},
+		{bob, []uint{bobFeed.ID}},
+		{carol, []uint{bobFeed.ID, aliceFeed.ID}},
+	} {
+		list, err := svc.Feeds.List(c.viewer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []uint
+		for _, feed := range list {
+			got = append(got, feed.ID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("List(%s) = %v, ingin %v", c.viewer.Username, got, c.want)
+		}
+	}
+
+	// Comment dan reaksi user yang diblokir pada feed user lain juga
+	// disembunyikan.
+	carolFeed, _ := svc.Feeds.Create(carol, "dari carol", nil)
+	if _, err := svc.Feeds.CreateComment(bob, carolFeed.ID, "dari bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Feeds.React(bob, carolFeed.ID, services.ReactionLike); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		viewer models.User
+		want   int
+	}{
+		{alice, 0},
+		{carol, 1},
+	} {
+		list, err := svc.Feeds.List(c.viewer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list[0].Comments) != c.want || len(list[0].Reactions) != c.want {
+			t.Errorf("List(%s): %d comment, %d reaksi pada feed carol, ingin %d", c.viewer.Username, len(list[0].Comments), len(list[0].Reactions), c.want)
+		}
+	}
+
+	// Blokir berlaku di kedua arah.
+	for _, c := range []struct {
+		user models.User
+		feed models.Feed
+	}{
+		{alice, bobFeed},
+		{bob, aliceFeed},
+	} {
+		if _, err := svc.Feeds.CreateComment(c.user, c.feed.ID, "halo", ""); !services.IsCode(err, services.CodeNotFound) {
+			t.Errorf("comment %s pada feed %d: err = %v", c.user.Username, c.feed.ID, err)
+		}
+		if _, err := svc.Feeds.React(c.user, c.feed.ID, services.ReactionLike); !services.IsCode(err, services.CodeNotFound) {
+			t.Errorf("reaksi %s pada feed %d: err = %v", c.user.Username, c.feed.ID, err)
+		}
+	}
+	if _, err := svc.Feeds.CreateComment(carol, bobFeed.ID, "halo", ""); err != nil {
+		t.Fatalf("comment user lain: %v", err)
+	}
+
+	if err := svc.Blocks.Unblock(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Feeds.CreateComment(bob, aliceFeed.ID, "halo", ""); err != nil {
+		t.Fatalf("comment setelah blokir dibuka: %v", err)
+	}
+	if result, err := svc.Feeds.React(alice, bobFeed.ID, services.ReactionLike); err != nil || result != services.ReactionAdded {
+		t.Fatalf("reaksi setelah blokir dibuka: result = %v, err = %v", result, err)
+	}
+}
+
+func TestPrivateAuthorHidden(t *testing.T) {
+	feeds, store := newFeedService(t)
+	owner := store.AddUser(models.User{Username: "owner", IsPrivate: true})
+	friend := store.AddUser(models.User{Username: "friend"})
+	stranger := store.AddUser(models.User{Username: "stranger"})
+	// Konten akun privat terlihat oleh user yang diikuti pemiliknya.
+	if err := store.Repositories().Follows.Create(owner.ID, friend.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Mengikuti akun privat saja tidak cukup.
+	if err := store.Repositories().Follows.Create(stranger.ID, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	feed, _ := feeds.Create(owner, "privat", nil)
+
+	if _, err := feeds.CreateComment(stranger, feed.ID, "halo", ""); !services.IsCode(err, services.CodeNotFound) {
+		t.Errorf("comment oleh user yang tidak diikuti: err = %v", err)
+	}
+	if _, err := feeds.React(stranger, feed.ID, services.ReactionLike); !services.IsCode(err, services.CodeNotFound) {
+		t.Errorf("reaksi oleh user yang tidak diikuti: err = %v", err)
+	}
+	for _, user := range []models.User{owner, friend} {
+		if _, err := feeds.CreateComment(user, feed.ID, "halo", ""); err != nil {
+			t.Errorf("comment oleh %s: %v", user.Username, err)
+		}
+		if _, err := feeds.React(user, feed.ID, services.ReactionLike); err != nil {
+			t.Errorf("reaksi oleh %s: %v", user.Username, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"social-media-backend/models"
+	"social-media-backend/repository"
+)
+
+// FollowService mengelola relasi follow antar user.
+type FollowService struct {
+	users   repository.UserRepository
+	follows repository.FollowRepository
+	blocks  repository.BlockRepository
+}
+
+// NewFollowService membuat FollowService.
+func NewFollowService(users repository.UserRepository, follows repository.FollowRepository, blocks repository.BlockRepository) *FollowService {
+	return &FollowService{users: users, follows: follows, blocks: blocks}
+}
+
+// Follow membuat user mengikuti targetID. User yang sedang dinonaktifkan
+// tidak dapat diikuti, begitu juga user yang memblokir atau diblokir oleh
+// user.
+func (s *FollowService) Follow(user models.User, targetID uint) error {
+	target, err := s.users.FindActiveByID(targetID)
+	if err != nil {
+		return lookupError(err, "User tidak ditemukan")
+	}
+	blocked, err := s.blocks.Between(user.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return newError(CodeForbidden, "Anda tidak dapat mengikuti user ini")
+	}
+	following, err := s.follows.Exists(user.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if following {
+		return newError(CodeInvalid, "Anda sudah mengikuti user ini")
+	}
+	return s.follows.Create(user.ID, target.ID)
+}
+
+// Unfollow membuat user berhenti mengikuti targetID.
+func (s *FollowService) Unfollow(user models.User, targetID uint) error {
+	target, err := s.users.FindByID(targetID)
+	if err != nil {
+		return lookupError(err, "User tidak ditemukan")
+	}
+	return s.follows.Delete(user.ID, target.ID)
+}
+
+// Followers mengembalikan user aktif yang mengikuti user.
+func (s *FollowService) Followers(user models.User) ([]models.User, error) {
+	return s.follows.Followers(user.ID)
+}
+
+// Following mengembalikan user aktif yang diikuti user.
+func (s *FollowService) Following(user models.User) ([]models.User, error) {
+	return s.follows.Following(user.ID)
+}
@@ -0,0 +1,51 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/repository/memory"
+	"social-media-backend/services"
+)
+
+func TestFollow(t *testing.T) {
+	store := memory.NewStore()
+	follows := services.New(store.Repositories()).Follows
+	now := time.Now()
+	alice := store.AddUser(models.User{Username: "alice"})
+	bob := store.AddUser(models.User{Username: "bob"})
+	gone := store.AddUser(models.User{Username: "gone", DeactivatedAt: &now})
+
+	if err := follows.Follow(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := follows.Follow(alice, bob.ID); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("follow dua kali: err = %v", err)
+	}
+	if err := follows.Follow(alice, gone.ID); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("follow user nonaktif: err = %v", err)
+	}
+	if err := follows.Follow(alice, 999); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("follow user yang tidak ada: err = %v", err)
+	}
+
+	followers, _ := follows.Followers(bob)
+	if len(followers) != 1 || followers[0].ID != alice.ID {
+		t.Fatalf("Followers(bob) = %+v", followers)
+	}
+	following, _ := follows.Following(alice)
+	if len(following) != 1 || following[0].ID != bob.ID {
+		t.Fatalf("Following(alice) = %+v", following)
+	}
+
+	if err := follows.Unfollow(alice, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if followers, _ := follows.Followers(bob); len(followers) != 0 {
+		t.Fatalf("Followers(bob) setelah unfollow = %+v", followers)
+	}
+	if err := follows.Follow(alice, bob.ID); err != nil {
+		t.Fatalf("follow ulang setelah unfollow: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Batas jumlah item per halaman untuk daftar yang memakai cursor.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageParams adalah parameter pagination dari client. Cursor adalah
+// NextCursor dari halaman sebelumnya; Limit 0 berarti DefaultPageLimit.
+type PageParams struct {
+	Cursor string
+	Limit  int
+}
+
+// pageLimit menerapkan default dan batas maksimum pada limit dari client.
+func pageLimit(limit int) (int, error) {
+	switch {
+	case limit == 0:
+		return DefaultPageLimit, nil
+	case limit < 0:
+		return 0, newError(CodeInvalid, "Parameter limit tidak valid")
+	case limit > MaxPageLimit:
+		return MaxPageLimit, nil
+	}
+	return limit, nil
+}
+
+// encodeCursor mengubah posisi halaman menjadi cursor opaque untuk client.
+func encodeCursor(position interface{}) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor membaca cursor dari encodeCursor ke position. Cursor yang
+// rusak menghasilkan CodeInvalid.
+func decodeCursor(value string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, position)
+	}
+	if err != nil {
+		return newError(CodeInvalid, "Cursor tidak valid")
+	}
+	return nil
+}
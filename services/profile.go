@@ -0,0 +1,187 @@
+package services
+
+import (
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository"
+)
+
+// ProfileService menampilkan profil publik user beserta feed, followers, dan
+// following-nya sesuai pengaturan privasi dan blokir.
+type ProfileService struct {
+	users   repository.UserRepository
+	follows repository.FollowRepository
+	blocks  repository.BlockRepository
+	feeds   repository.FeedRepository
+}
+
+// NewProfileService membuat ProfileService.
+func NewProfileService(users repository.UserRepository, follows repository.FollowRepository, blocks repository.BlockRepository, feeds repository.FeedRepository) *ProfileService {
+	return &ProfileService{users: users, follows: follows, blocks: blocks, feeds: feeds}
+}
+
+// PublicProfile adalah profil User seperti dilihat oleh viewer.
+type PublicProfile struct {
+	User           models.User
+	FollowersCount int64
+	FollowingCount int64
+	FeedsCount     int64
+	Following      bool // viewer mengikuti User
+	FollowedBy     bool // User mengikuti viewer
+	// CanViewContent bernilai true jika viewer boleh melihat feed, followers,
+	// dan following User.
+	CanViewContent bool
+}
+
+// FeedPage adalah satu halaman feed. NextCursor kosong jika tidak ada halaman
+// berikutnya.
+type FeedPage struct {
+	Feeds      []models.Feed
+	NextCursor string
+}
+
+// UserPage adalah satu halaman user. NextCursor kosong jika tidak ada halaman
+// berikutnya.
+type UserPage struct {
+	Users      []models.User
+	NextCursor string
+}
+
+// feedCursor adalah isi cursor halaman feed: ID feed terakhir.
+type feedCursor struct {
+	ID uint `json:"i"`
+}
+
+// Get mengembalikan profil publik username untuk viewer. User yang sedang
+// dinonaktifkan, atau yang saling memblokir dengan viewer, dianggap tidak
+// ada. Jumlah followers, following, dan feed tetap ditampilkan untuk akun
+// privat.
+func (s *ProfileService) Get(viewer models.User, username string) (PublicProfile, error) {
+	user, err := s.find(viewer, username)
+	if err != nil {
+		return PublicProfile{}, err
+	}
+	profile := PublicProfile{User: user}
+	if profile.FollowersCount, profile.FollowingCount, err = s.follows.Count(user.ID); err != nil {
+		return PublicProfile{}, err
+	}
+	if profile.FeedsCount, err = s.feeds.CountByAuthor(user.ID); err != nil {
+		return PublicProfile{}, err
+	}
+	if profile.Following, err = s.follows.Exists(viewer.ID, user.ID); err != nil {
+		return PublicProfile{}, err
+	}
+	if profile.FollowedBy, err = s.follows.Exists(user.ID, viewer.ID); err != nil {
+		return PublicProfile{}, err
+	}
+	profile.CanViewContent = policy.CanViewContent(viewer, user, profile.FollowedBy)
+	return profile, nil
+}
+
+// Feeds mengembalikan satu halaman feed milik username, terbaru lebih dulu.
+func (s *ProfileService) Feeds(viewer models.User, username string, params PageParams) (FeedPage, error) {
+	user, err := s.content(viewer, username)
+	if err != nil {
+		return FeedPage{}, err
+	}
+	limit, err := pageLimit(params.Limit)
+	if err != nil {
+		return FeedPage{}, err
+	}
+	var after feedCursor
+	if params.Cursor != "" {
+		if err := decodeCursor(params.Cursor, &after); err != nil {
+			return FeedPage{}, err
+		}
+	}
+	// Satu baris tambahan menandakan masih ada halaman berikutnya.
+	feeds, err := s.feeds.ListByAuthor(viewer.ID, user.ID, after.ID, limit+1)
+	if err != nil {
+		return FeedPage{}, err
+	}
+	page := FeedPage{Feeds: feeds}
+	if len(feeds) > limit {
+		page.Feeds = feeds[:limit]
+		page.NextCursor = encodeCursor(feedCursor{feeds[limit-1].ID})
+	}
+	return page, nil
+}
+
+// Followers mengembalikan satu halaman user yang mengikuti username, dari
+// follow terbaru. User yang saling memblokir dengan viewer tidak
+// ditampilkan.
+func (s *ProfileService) Followers(viewer models.User, username string, params PageParams) (UserPage, error) {
+	return s.followPage(viewer, username, params, s.follows.FollowersPage)
+}
+
+// Following mengembalikan satu halaman user yang diikuti username, dengan
+// aturan yang sama seperti Followers.
+func (s *ProfileService) Following(viewer models.User, username string, params PageParams) (UserPage, error) {
+	return s.followPage(viewer, username, params, s.follows.FollowingPage)
+}
+
+func (s *ProfileService) followPage(viewer models.User, username string, params PageParams, list func(repository.FollowQuery) ([]repository.FollowEntry, error)) (UserPage, error) {
+	user, err := s.content(viewer, username)
+	if err != nil {
+		return UserPage{}, err
+	}
+	limit, err := pageLimit(params.Limit)
+	if err != nil {
+		return UserPage{}, err
+	}
+	query := repository.FollowQuery{UserID: user.ID, ViewerID: viewer.ID, Limit: limit + 1}
+	if params.Cursor != "" {
+		var after repository.FollowCursor
+		if err := decodeCursor(params.Cursor, &after); err != nil {
+			return UserPage{}, err
+		}
+		query.After = &after
+	}
+	entries, err := list(query)
+	if err != nil {
+		return UserPage{}, err
+	}
+	page := UserPage{Users: make([]models.User, 0, len(entries))}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = encodeCursor(entries[limit-1].Cursor)
+	}
+	for _, entry := range entries {
+		page.Users = append(page.Users, entry.User)
+	}
+	return page, nil
+}
+
+// find mencari user aktif berdasarkan username. User yang saling memblokir
+// dengan viewer diperlakukan seperti user yang tidak ada.
+func (s *ProfileService) find(viewer models.User, username string) (models.User, error) {
+	user, err := s.users.FindActiveByUsername(username)
+	if err != nil {
+		return models.User{}, lookupError(err, "User tidak ditemukan")
+	}
+	blocked, err := s.blocks.Between(viewer.ID, user.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if blocked {
+		return models.User{}, newError(CodeNotFound, "User tidak ditemukan")
+	}
+	return user, nil
+}
+
+// content seperti find, tetapi juga memastikan viewer boleh melihat konten
+// user sesuai pengaturan privasinya.
+func (s *ProfileService) content(viewer models.User, username string) (models.User, error) {
+	user, err := s.find(viewer, username)
+	if err != nil {
+		return models.User{}, err
+	}
+	followedBy, err := s.follows.Exists(user.ID, viewer.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if !policy.CanViewContent(viewer, user, followedBy) {
+		return models.User{}, newError(CodeForbidden, "Akun ini privat")
+	}
+	return user, nil
+}
@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-backend/models"
+	"social-media-backend/repository/memory"
+	"social-media-backend/services"
+)
+
+func TestPublicProfile(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.New(store.Repositories())
+	owner := store.AddUser(models.User{Username: "Owner", IsPrivate: true})
+	friend := store.AddUser(models.User{Username: "friend"})
+	fan := store.AddUser(models.User{Username: "fan"})
+	var fans []models.User
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		fans = append(fans, store.AddUser(models.User{Username: name}))
+	}
+	now := time.Now()
+	gone := store.AddUser(models.User{Username: "gone", DeactivatedAt: &now})
+
+	for _, user := range append(fans, friend, fan, gone) {
+		if user.DeactivatedAt == nil {
+			if err := svc.Follows.Follow(user, owner.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := svc.Follows.Follow(owner, friend.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Feeds.Create(owner, "rahasia", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := svc.Profiles.Get(fan, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.FollowersCount != 7 || profile.FollowingCount != 1 || profile.FeedsCount != 1 ||
+		!profile.Following || profile.FollowedBy || profile.CanViewContent {
+		t.Fatalf("profil dilihat fan = %+v", profile)
+	}
+	if _, err := svc.Profiles.Feeds(fan, "owner", services.PageParams{}); !services.IsCode(err, services.CodeForbidden) {
+		t.Fatalf("feed akun privat: err = %v", err)
+	}
+	if list, _ := svc.Feeds.List(fan); len(list) != 0 {
+		t.Fatalf("feed akun privat tampil di timeline fan: %+v", list)
+	}
+	if list, _ := svc.Feeds.List(friend); len(list) != 1 {
+		t.Fatalf("timeline friend = %+v", list)
+	}
+
+	// Pagination followers: follow terbaru lebih dulu, tanpa duplikat.
+	var names []string
+	params := services.PageParams{Limit: 3}
+	for {
+		page, err := svc.Profiles.Followers(friend, "owner", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, user := range page.Users {
+			names = append(names, user.Username)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+	if got := len(names); got != 7 || names[0] != "fan" || names[6] != "a" {
+		t.Fatalf("followers = %v", names)
+	}
+
+	if _, err := svc.Profiles.Get(fan, "gone"); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("profil akun nonaktif: err = %v", err)
+	}
+	if _, err := svc.Profiles.Followers(friend, "owner", services.PageParams{Cursor: "x"}); !services.IsCode(err, services.CodeInvalid) {
+		t.Fatalf("cursor tidak valid: err = %v", err)
+	}
+	if err := svc.Blocks.Block(owner, fan.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Profiles.Get(fan, "owner"); !services.IsCode(err, services.CodeNotFound) {
+		t.Fatalf("profil user yang memblokir: err = %v", err)
+	}
+}
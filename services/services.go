@@ -0,0 +1,72 @@
+// Package services berisi aturan bisnis fitur sosial (feed, comment, reaksi,
+// follow, blokir, profil publik, chat) di atas package repository. Service
+// tidak bergantung pada HTTP; handler di controllers menerjemahkan *Error
+// menjadi status HTTP.
+package services
+
+import (
+	"errors"
+
+	"social-media-backend/repository"
+)
+
+// Code mengelompokkan kesalahan yang disebabkan oleh request, bukan oleh
+// kegagalan sistem.
+type Code int
+
+const (
+	CodeInvalid Code = iota + 1
+	CodeNotFound
+	CodeForbidden
+	CodeConflict
+)
+
+// Error adalah kesalahan bisnis dengan pesan yang aman ditampilkan ke client.
+// Kesalahan lain (misalnya dari database) dikembalikan apa adanya.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func newError(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// IsCode memeriksa apakah err adalah *Error dengan code tertentu.
+func IsCode(err error, code Code) bool {
+	var serviceErr *Error
+	return errors.As(err, &serviceErr) && serviceErr.Code == code
+}
+
+// lookupError menerjemahkan repository.ErrNotFound menjadi CodeNotFound
+// dengan pesan message.
+func lookupError(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return newError(CodeNotFound, message)
+	}
+	return err
+}
+
+// Services mengelompokkan semua service yang dipakai handler.
+type Services struct {
+	Users    *UserService
+	Profiles *ProfileService
+	Follows  *FollowService
+	Blocks   *BlockService
+	Feeds    *FeedService
+	Chats    *ChatService
+}
+
+// New membuat semua service di atas repos.
+func New(repos repository.Repositories) Services {
+	return Services{
+		Users:    NewUserService(repos.Users),
+		Profiles: NewProfileService(repos.Users, repos.Follows, repos.Blocks, repos.Feeds),
+		Follows:  NewFollowService(repos.Users, repos.Follows, repos.Blocks),
+		Blocks:   NewBlockService(repos.Users, repos.Follows, repos.Blocks),
+		Feeds:    NewFeedService(repos.Feeds, repos.Comments, repos.Reactions, repos.Users, repos.Follows, repos.Blocks),
+		Chats:    NewChatService(repos.Users, repos.Chatrooms, repos.Messages, repos.Blocks),
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/repository"
+)
+
+// UserService mengelola data profil user.
+type UserService struct {
+	users repository.UserRepository
+}
+
+// NewUserService membuat UserService.
+func NewUserService(users repository.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+// ProfileUpdate berisi perubahan profil; field kosong tidak diubah.
+type ProfileUpdate struct {
+	Fullname     string
+	Username     string
+	Email        string
+	JenisKelamin string
+	TanggalLahir *time.Time
+	PhotoProfile string
+	Banner       string
+	// PhotoVariants dan BannerVariants diisi bersama PhotoProfile dan Banner.
+	PhotoVariants  map[string]string
+	BannerVariants map[string]string
+	IsPrivate      *bool
+	// Bio, Location, Pronouns, dan Links nil jika tidak diubah; nilai kosong
+	// mengosongkan field.
+	Bio      *string
+	Location *string
+	Pronouns *string
+	Links    []string
+	// Visibility hanya mengubah visibilitas field yang disebut.
+	Visibility map[string]string
+}
+
+// Batas detail profil.
+const (
+	BioMaxLength      = 300
+	LocationMaxLength = 100
+	PronounsMaxLength = 50
+	MaxProfileLinks   = 5
+	LinkMaxLength     = 255
+)
+
+// directorySearchMaxLength adalah panjang maksimum kata kunci pencarian.
+const directorySearchMaxLength = 100
+
+// DirectoryParams adalah parameter direktori user dari client. Nilai kosong
+// memakai default: tanpa filter, diurutkan berdasarkan relevansi jika ada
+// kata kunci atau username jika tidak.
+type DirectoryParams struct {
+	Search string
+	Filter string
+	Sort   string
+	Cursor string // NextCursor dari halaman sebelumnya
+	Limit  int
+}
+
+// DirectoryPage adalah satu halaman direktori user. NextCursor kosong jika
+// tidak ada halaman berikutnya.
+type DirectoryPage struct {
+	Users      []models.User
+	NextCursor string
+}
+
+// directoryCursor adalah isi cursor yang dikirim ke client. Urutan ikut
+// disimpan agar cursor tidak dipakai dengan urutan lain.
+type directoryCursor struct {
+	Sort string `json:"s"`
+	repository.DirectoryCursor
+}
+
+// Directory mengembalikan satu halaman direktori user untuk viewer. User yang
+// dinonaktifkan dan user yang saling memblokir dengan viewer tidak
+// ditampilkan.
+func (s *UserService) Directory(viewer models.User, params DirectoryParams) (DirectoryPage, error) {
+	query := repository.DirectoryQuery{
+		ViewerID: viewer.ID,
+		Search:   strings.ToLower(strings.TrimSpace(params.Search)),
+		Filter:   params.Filter,
+		Sort:     params.Sort,
+		Limit:    params.Limit,
+	}
+	if utf8.RuneCountInString(query.Search) > directorySearchMaxLength {
+		return DirectoryPage{}, newError(CodeInvalid, fmt.Sprintf("Kata kunci pencarian maksimal %d karakter", directorySearchMaxLength))
+	}
+	switch query.Filter {
+	case "", repository.DirectoryFollowing, repository.DirectoryFollowers, repository.DirectoryMutuals:
+	default:
+		return DirectoryPage{}, newError(CodeInvalid, "Filter tidak valid")
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = repository.SortUsername
+		if query.Search != "" {
+			query.Sort = repository.SortRelevance
+		}
+	case repository.SortUsername, repository.SortNewest:
+	case repository.SortRelevance:
+		if query.Search == "" {
+			return DirectoryPage{}, newError(CodeInvalid, "Urutan relevance membutuhkan kata kunci pencarian")
+		}
+	default:
+		return DirectoryPage{}, newError(CodeInvalid, "Urutan tidak valid")
+	}
+	limit, err := pageLimit(query.Limit)
+	if err != nil {
+		return DirectoryPage{}, err
+	}
+	if params.Cursor != "" {
+		var cursor directoryCursor
+		if err := decodeCursor(params.Cursor, &cursor); err != nil {
+			return DirectoryPage{}, err
+		}
+		if cursor.Sort != query.Sort {
+			return DirectoryPage{}, newError(CodeInvalid, "Cursor tidak valid")
+		}
+		query.After = &cursor.DirectoryCursor
+	}
+
+	// Satu baris tambahan menandakan masih ada halaman berikutnya.
+	query.Limit = limit + 1
+	entries, err := s.users.Directory(query)
+	if err != nil {
+		return DirectoryPage{}, err
+	}
+	page := DirectoryPage{Users: make([]models.User, 0, len(entries))}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = encodeCursor(directoryCursor{query.Sort, entries[limit-1].Cursor})
+	}
+	for _, entry := range entries {
+		page.Users = append(page.Users, entry.User)
+	}
+	return page, nil
+}
+
+// UpdateProfile mengubah profil user dan memperbarui *user. Jika email
+// berubah, status verifikasi email dikosongkan dan emailChanged bernilai
+// true agar pemanggil dapat mengirim email verifikasi baru.
+func (s *UserService) UpdateProfile(user *models.User, update ProfileUpdate) (emailChanged bool, err error) {
+	details, detailsChanged, err := applyDetails(user.ProfileDetails, update)
+	if err != nil {
+		return false, err
+	}
+	taken, err := s.users.Taken(update.Username, update.Email, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if taken {
+		return false, newError(CodeConflict, "Username atau email sudah dipakai akun lain")
+	}
+	emailChanged = update.Email != "" && update.Email != user.Email
+	changes := models.User{
+		Fullname:       update.Fullname,
+		Username:       update.Username,
+		Email:          update.Email,
+		JenisKelamin:   update.JenisKelamin,
+		TanggalLahir:   update.TanggalLahir,
+		PhotoProfile:   update.PhotoProfile,
+		Banner:         update.Banner,
+		PhotoVariants:  update.PhotoVariants,
+		BannerVariants: update.BannerVariants,
+	}
+	if err := s.users.UpdateProfile(user, changes); err != nil {
+		return false, err
+	}
+	if update.IsPrivate != nil && *update.IsPrivate != user.IsPrivate {
+		if err := s.users.SetPrivate(user, *update.IsPrivate); err != nil {
+			return false, err
+		}
+	}
+	if detailsChanged {
+		if err := s.users.UpdateDetails(user, details); err != nil {
+			return false, err
+		}
+	}
+	// Alamat email baru harus diverifikasi ulang.
+	if emailChanged {
+		if err := s.users.MarkEmailUnverified(user); err != nil {
+			return true, err
+		}
+	}
+	return emailChanged, nil
+}
+
+// SetPassword menyimpan hash password baru user dan memperbarui *user.
+// Pemeriksaan password lama, kebijakan password, dan hashing dilakukan
+// pemanggil karena hasher dikonfigurasi di controllers.
+func (s *UserService) SetPassword(user *models.User, hash string) error {
+	if hash == "" {
+		return newError(CodeInvalid, "Hash password tidak boleh kosong")
+	}
+	return s.users.SetPassword(user, hash)
+}
+
+// applyDetails memvalidasi perubahan detail profil di update dan
+// mengembalikan ProfileDetails baru. Nilai current tidak diubah.
+func applyDetails(current models.ProfileDetails, update ProfileUpdate) (models.ProfileDetails, bool, error) {
+	details := models.ProfileDetails{
+		Bio:        current.Bio,
+		Location:   current.Location,
+		Pronouns:   current.Pronouns,
+		Links:      current.Links,
+		Visibility: map[string]string{},
+	}
+	for field, visibility := range current.Visibility {
+		details.Visibility[field] = visibility
+	}
+	changed := false
+	texts := []struct {
+		value  *string
+		target *string
+		max    int
+		name   string
+	}{
+		{update.Bio, &details.Bio, BioMaxLength, "Bio"},
+		{update.Location, &details.Location, LocationMaxLength, "Lokasi"},
+		{update.Pronouns, &details.Pronouns, PronounsMaxLength, "Pronoun"},
+	}
+	for _, text := range texts {
+		if text.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*text.value)
+		if utf8.RuneCountInString(value) > text.max {
+			return details, false, newError(CodeInvalid, fmt.Sprintf("%s maksimal %d karakter", text.name, text.max))
+		}
+		*text.target, changed = value, true
+	}
+	if update.Links != nil {
+		if len(update.Links) > MaxProfileLinks {
+			return details, false, newError(CodeInvalid, fmt.Sprintf("Maksimal %d tautan", MaxProfileLinks))
+		}
+		details.Links = make([]string, 0, len(update.Links))
+		seen := map[string]bool{}
+		for _, raw := range update.Links {
+			link, err := normalizeLink(raw)
+			if err != nil {
+				return details, false, err
+			}
+			if !seen[link] {
+				seen[link] = true
+				details.Links = append(details.Links, link)
+			}
+		}
+		changed = true
+	}
+	for field, visibility := range update.Visibility {
+		if !policy.ValidFieldVisibility(field, visibility) {
+			return details, false, newError(CodeInvalid, fmt.Sprintf("Visibilitas %q tidak valid untuk field %q", visibility, field))
+		}
+		details.Visibility[field], changed = visibility, true
+	}
+	return details, changed, nil
+}
+
+// normalizeLink memvalidasi tautan website. Tautan tanpa skema dianggap
+// https; hanya http dan https yang diterima.
+func normalizeLink(raw string) (string, error) {
+	link := strings.TrimSpace(raw)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	invalid := newError(CodeInvalid, fmt.Sprintf("Tautan %q tidak valid", raw))
+	if len(link) > LinkMaxLength || strings.ContainsAny(link, " \t\r\n") {
+		return "", invalid
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.User != nil || !strings.Contains(u.Hostname(), ".") {
+		return "", invalid
+	}
+	u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", invalid
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"social-media-backend/config"
+	"social-media-backend/controllers"
+	"social-media-backend/loginguard"
+	"social-media-backend/mailer"
+	"social-media-backend/migrations"
+	"social-media-backend/models"
+)
+
+const testPassword = "Secret-pass9"
+
+// Route yang pernah dipanggil test, dalam format "METHOD /pattern". Setelah
+// semua test selesai, TestMain memastikan setiap route di newRouter pernah
+// diuji.
+var (
+	coveredMu     sync.Mutex
+	coveredRoutes = map[string]bool{}
+	knownRoutes   = map[string]bool{}
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	log.SetOutput(io.Discard)
+
+	code := m.Run()
+	// Pemeriksaan cakupan hanya berlaku jika semua test dijalankan.
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		var missing []string
+		for route := range knownRoutes {
+			if !coveredRoutes[route] {
+				missing = append(missing, route)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			fmt.Fprintf(os.Stderr, "route belum diuji:\n  %s\n", strings.Join(missing, "\n  "))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// testApp adalah aplikasi lengkap (router dari newRouter) di atas database
+// SQLite baru di direktori sementara.
+type testApp struct {
+	t      *testing.T
+	router *gin.Engine
+	mail   *mailer.LogMailer
+}
+
+// testUser adalah user yang sudah register dan login.
+type testUser struct {
+	ID           uint
+	Username     string
+	Email        string
+	Token        string
+	RefreshToken string
+}
+
+// newTestApp menyiapkan database, konfigurasi controllers, dan router.
+// Working directory dipindah ke direktori sementara agar file upload tidak
+// ditulis ke repository.
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	cfg := config.Default()
+	cfg.Database = testDatabase(t, dir)
+	// Hash password dengan cost minimum agar test cepat.
+	cfg.Password.Hasher = "bcrypt"
+	cfg.Password.BcryptCost = bcrypt.MinCost
+
+	dialector, err := config.Dialector(cfg.Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Database selain SQLite dipakai ulang antar test, sehingga dikosongkan
+	// dulu dengan membatalkan semua migrasi.
+	if cfg.Database.Driver != "sqlite" {
+		if _, err := migrations.Down(db, len(migrations.All())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	config.DB = db
+	// Pekerjaan background (misalnya email reset password) harus selesai
+	// sebelum database ditutup.
+	t.Cleanup(controllers.WaitBackground)
+
+	if err := configureControllers(cfg); err != nil {
+		t.Fatal(err)
+	}
+	controllers.LoginGuard.Store = loginguard.NewMemoryStore()
+	mail, ok := controllers.Mailer.(*mailer.LogMailer)
+	if !ok {
+		t.Fatalf("Mailer = %T, ingin *mailer.LogMailer", controllers.Mailer)
+	}
+
+	app := &testApp{t: t, router: newRouter(cfg), mail: mail}
+	coveredMu.Lock()
+	for _, route := range app.router.Routes() {
+		knownRoutes[route.Method+" "+route.Path] = true
+	}
+	coveredMu.Unlock()
+	return app
+}
+
+// testDatabase memilih database testApp. Default-nya SQLite baru di dir.
+// Dengan TEST_DATABASE_DRIVER=postgres atau mysql, semua test dijalankan di
+// database dari TEST_POSTGRES_DSN atau TEST_MYSQL_DSN, misalnya:
+//
+//	TEST_DATABASE_DRIVER=postgres TEST_POSTGRES_DSN="host=localhost user=app dbname=app_test sslmode=disable" go test ./...
+//
+// Database tersebut harus khusus untuk test karena isinya dihapus.
+func testDatabase(t *testing.T, dir string) config.DatabaseConfig {
+	t.Helper()
+	switch driver := os.Getenv("TEST_DATABASE_DRIVER"); driver {
+	case "", "sqlite":
+		return config.DatabaseConfig{Driver: "sqlite", DSN: filepath.Join(dir, "test.db")}
+	case "postgres", "mysql":
+		env := "TEST_" + strings.ToUpper(driver) + "_DSN"
+		dsn := os.Getenv(env)
+		if dsn == "" {
+			t.Fatalf("TEST_DATABASE_DRIVER=%s membutuhkan %s", driver, env)
+		}
+		return config.DatabaseConfig{Driver: driver, DSN: dsn}
+	default:
+		t.Fatalf("TEST_DATABASE_DRIVER tidak dikenal: %q", driver)
+		return config.DatabaseConfig{}
+	}
+}
+
+// formBody adalah body multipart/form-data untuk endpoint upload.
+type formBody struct {
+	fields map[string]string
+	files  map[string]string // nama field -> isi file
+	// names adalah nama file per field; default nama field + ".txt".
+	names map[string]string
+}
+
+// pngImage membuat isi file PNG berukuran width x height untuk test upload
+// gambar.
+func pngImage(width, height int) string {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.String()
+}
+
+// imageSize mengambil file upload lewat route static dan mengembalikan format
+// serta dimensinya.
+func (a *testApp) imageSize(path string) (format string, width, height int) {
+	a.t.Helper()
+	rec := a.request("GET", "/"+path, "", nil)
+	if rec.Code != http.StatusOK {
+		a.t.Fatalf("GET /%s: status %d", path, rec.Code)
+	}
+	config, format, err := image.DecodeConfig(rec.Body)
+	if err != nil {
+		a.t.Fatalf("GET /%s: %v", path, err)
+	}
+	return format, config.Width, config.Height
+}
+
+// request mengirim request ke router. body berupa formBody dikirim sebagai
+// multipart, selain itu di-encode sebagai JSON.
+func (a *testApp) request(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case formBody:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for name, value := range b.fields {
+			w.WriteField(name, value)
+		}
+		for name, content := range b.files {
+			filename := name + ".txt"
+			if b.names[name] != "" {
+				filename = b.names[name]
+			}
+			part, err := w.CreateFormFile(name, filename)
+			if err != nil {
+				a.t.Fatal(err)
+			}
+			part.Write([]byte(content))
+		}
+		w.Close()
+		reader, contentType = &buf, w.FormDataContentType()
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader, contentType = bytes.NewReader(data), "application/json"
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	a.cover(method, req.URL.Path)
+	if passwordHashPattern.Match(rec.Body.Bytes()) {
+		a.t.Errorf("%s %s: respons berisi hash password: %s", method, path, rec.Body.String())
+	}
+	return rec
+}
+
+// passwordHashPattern cocok dengan field password dan format hash bcrypt
+// maupun argon2id. Setiap respons yang melewati request diperiksa dengan
+// pola ini.
+var passwordHashPattern = regexp.MustCompile(`"(?i:password)"\s*:|\$2[aby]\$|\$argon2id\$`)
+
+// do mengirim request dan mengembalikan status serta body JSON.
+func (a *testApp) do(method, path, token string, body interface{}) (int, map[string]interface{}) {
+	a.t.Helper()
+	rec := a.request(method, path, token, body)
+	var decoded map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &decoded)
+	return rec.Code, decoded
+}
+
+// mustDo seperti do, tetapi menghentikan test jika status tidak sesuai.
+func (a *testApp) mustDo(want int, method, path, token string, body interface{}) map[string]interface{} {
+	a.t.Helper()
+	code, decoded := a.do(method, path, token, body)
+	if code != want {
+		a.t.Fatalf("%s %s: status %d, ingin %d: %v", method, path, code, want, decoded)
+	}
+	return decoded
+}
+
+// probe mengirim request tanpa body dan hanya mengembalikan status. Tidak
+// dihitung sebagai cakupan route, sehingga cocok untuk pemeriksaan massal
+// seperti autentikasi.
+func (a *testApp) probe(method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// cover mencatat route yang cocok dengan path.
+func (a *testApp) cover(method, path string) {
+	for _, route := range a.router.Routes() {
+		if route.Method == method && matchRoute(route.Path, path) {
+			coveredMu.Lock()
+			coveredRoutes[route.Method+" "+route.Path] = true
+			coveredMu.Unlock()
+			return
+		}
+	}
+}
+
+func matchRoute(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return i <= len(pathParts)
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+		if strings.HasPrefix(part, ":") && pathParts[i] == "" {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
+
+// register mendaftarkan user baru lalu login.
+func (a *testApp) register(username string) testUser {
+	a.t.Helper()
+	email := username + "@example.com"
+	a.mustDo(http.StatusCreated, "POST", "/register", "", map[string]interface{}{
+		"fullname":      "User " + username,
+		"username":      username,
+		"email":         email,
+		"password":      testPassword,
+		"jenis_kelamin": "L",
+		"tanggal_lahir": "2000-01-01",
+	})
+	user := a.login(username, testPassword)
+	var record models.User
+	if err := config.DB.Where("username = ?", username).First(&record).Error; err != nil {
+		a.t.Fatal(err)
+	}
+	user.ID, user.Username, user.Email = record.ID, username, email
+	return user
+}
+
+// login mengembalikan token hasil login dengan password.
+func (a *testApp) login(login, password string) testUser {
+	a.t.Helper()
+	body := a.mustDo(http.StatusOK, "POST", "/login", "", map[string]interface{}{"login": login, "password": password})
+	token, _ := body["token"].(string)
+	refresh, _ := body["refresh_token"].(string)
+	if token == "" || refresh == "" {
+		a.t.Fatalf("login %s tidak mengembalikan token: %v", login, body)
+	}
+	return testUser{Token: token, RefreshToken: refresh}
+}
+
+// setRole mengubah role user langsung di database.
+func (a *testApp) setRole(user testUser, role string) {
+	a.t.Helper()
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("role", role).Error; err != nil {
+		a.t.Fatal(err)
+	}
+}
+
+// apiToken membuat personal access token milik user dengan scope tertentu.
+func (a *testApp) apiToken(user testUser, scopes ...string) string {
+	a.t.Helper()
+	body := a.mustDo(http.StatusCreated, "POST", "/tokens", user.Token, map[string]interface{}{"name": "test", "scopes": scopes})
+	token, _ := body["token"].(map[string]interface{})["token"].(string)
+	if token == "" {
+		a.t.Fatalf("token tidak ditemukan di respons: %v", body)
+	}
+	return token
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_%-]+)`)
+
+// mailToken mengambil token dari tautan di email terakhir untuk alamat to
+// dengan subject tertentu. Sebagian email dikirim di background, sehingga
+// mailToken menunggu sebentar sampai email tersebut ada.
+func (a *testApp) mailToken(to, subject string) string {
+	a.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	msg, ok := a.mail.LastTo(to)
+	for (!ok || msg.Subject != subject) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		msg, ok = a.mail.LastTo(to)
+	}
+	if !ok || msg.Subject != subject {
+		a.t.Fatalf("tidak ada email %q untuk %s", subject, to)
+	}
+	match := mailTokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		a.t.Fatalf("email untuk %s tidak berisi token: %q", to, msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return token
+}
+
+// id mengambil field ID dari objek bernama key di body respons.
+func id(t *testing.T, body map[string]interface{}, key string) string {
+	t.Helper()
+	object, _ := body[key].(map[string]interface{})
+	value, ok := object["ID"].(float64)
+	if !ok {
+		t.Fatalf("respons tidak berisi %s.ID: %v", key, body)
+	}
+	return fmt.Sprint(uint(value))
+}
+
+// routeCase adalah satu langkah dalam test yang berurutan: request dan
+// status yang diharapkan.
+type routeCase struct {
+	name   string
+	method string
+	path   string
+	token  string
+	body   interface{}
+	want   int
+}
+
+// with mengembalikan salinan testApp yang melaporkan kegagalan ke t. Dipakai
+// di dalam subtest agar Fatal tidak dipanggil pada test induk.
+func (a *testApp) with(t *testing.T) *testApp {
+	copied := *a
+	copied.t = t
+	return &copied
+}
+
+// run menjalankan cases secara berurutan sebagai subtest.
+func (a *testApp) run(t *testing.T, cases []routeCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, body := a.with(t).do(tc.method, tc.path, tc.token, tc.body)
+			if code != tc.want {
+				t.Errorf("%s %s: status %d, ingin %d: %v", tc.method, tc.path, code, tc.want, body)
+			}
+		})
+	}
+}
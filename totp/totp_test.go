@@ -0,0 +1,92 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret SHA1 dari RFC 6238 Appendix B ("12345678901234567890").
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// Vektor RFC 6238 Appendix B (SHA1). RFC memakai 8 digit; kode 6 digit adalah
+// 6 digit terakhirnya.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestCodeRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		got, err := Code(rfcSecret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v.code {
+			t.Errorf("Code(%d) = %s, ingin %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	tests := []struct {
+		name   string
+		offset time.Duration
+		skew   int
+		ok     bool
+	}{
+		{"langkah sekarang", 0, 0, true},
+		{"langkah sebelumnya tanpa skew", -Period * time.Second, 0, false},
+		{"langkah sebelumnya", -Period * time.Second, 1, true},
+		{"langkah berikutnya", Period * time.Second, 1, true},
+		{"di luar skew", 2 * Period * time.Second, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := now.Add(tt.offset)
+			code, err := Code(rfcSecret, at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			step, ok := Validate(rfcSecret, code, now, tt.skew)
+			if ok != tt.ok {
+				t.Fatalf("Validate = %v, ingin %v", ok, tt.ok)
+			}
+			if ok && step != Step(at) {
+				t.Errorf("step = %d, ingin %d", step, Step(at))
+			}
+		})
+	}
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate(%q) diterima", code)
+		}
+	}
+	if _, ok := Validate("bukan base32!", "287082", time.Unix(59, 0), 1); ok {
+		t.Error("secret tidak valid diterima")
+	}
+}
+
+func TestSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := decodeSecret(secret)
+	if err != nil || len(key) != secretSize {
+		t.Fatalf("secret %q: %d byte, err = %v", secret, len(key), err)
+	}
+	// Secret boleh ditulis dengan huruf kecil, spasi, dan padding.
+	spaced := "gezd gnbv gy3t qojq gezd gnbv gy3t qojq===="
+	if code, err := Code(spaced, time.Unix(59, 0)); err != nil || code != "287082" {
+		t.Errorf("Code(secret berspasi) = %q, %v", code, err)
+	}
+}
@@ -0,0 +1,165 @@
+package views
+
+import (
+	"time"
+
+	"social-media-backend/models"
+)
+
+// Feed adalah feed beserta penulis, komentar, dan reaksinya.
+type Feed struct {
+	ID        uint
+	Feed      string
+	File      string
+	UserID    uint
+	User      UserSummary
+	Reactions []Reaction
+	Comments  []Comment
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewFeed membuat Feed. Komentar dan reaksi hanya terisi jika sudah di-preload.
+func NewFeed(feed models.Feed) Feed {
+	view := Feed{
+		ID:        feed.ID,
+		Feed:      feed.Feed,
+		File:      feed.File,
+		UserID:    feed.UserID,
+		User:      NewUserSummary(feed.User),
+		Reactions: make([]Reaction, 0, len(feed.Reactions)),
+		Comments:  make([]Comment, 0, len(feed.Comments)),
+		CreatedAt: feed.CreatedAt,
+		UpdatedAt: feed.UpdatedAt,
+	}
+	for _, reaction := range feed.Reactions {
+		view.Reactions = append(view.Reactions, NewReaction(reaction))
+	}
+	for _, comment := range feed.Comments {
+		view.Comments = append(view.Comments, NewComment(comment))
+	}
+	return view
+}
+
+// Feeds membuat daftar Feed.
+func Feeds(feeds []models.Feed) []Feed {
+	result := make([]Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		result = append(result, NewFeed(feed))
+	}
+	return result
+}
+
+// Comment adalah komentar pada feed beserta penulisnya.
+type Comment struct {
+	ID        uint
+	Comment   string
+	File      string
+	FeedID    uint
+	UserID    uint
+	User      UserSummary
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewComment membuat Comment.
+func NewComment(comment models.Comment) Comment {
+	return Comment{
+		ID:        comment.ID,
+		Comment:   comment.Comment,
+		File:      comment.File,
+		FeedID:    comment.FeedID,
+		UserID:    comment.UserID,
+		User:      NewUserSummary(comment.User),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+// Reaction adalah reaksi user pada feed.
+type Reaction struct {
+	ID        uint
+	FeedID    uint
+	UserID    uint
+	Reaction  string
+	CreatedAt time.Time
+}
+
+// NewReaction membuat Reaction.
+func NewReaction(reaction models.Reaction) Reaction {
+	return Reaction{
+		ID:        reaction.ID,
+		FeedID:    reaction.FeedID,
+		UserID:    reaction.UserID,
+		Reaction:  reaction.Reaction,
+		CreatedAt: reaction.CreatedAt,
+	}
+}
+
+// Chatroom adalah chatroom beserta pesertanya, jika sudah dimuat.
+type Chatroom struct {
+	ID        uint
+	Name      string
+	OwnerID   uint
+	IsGroup   bool
+	Users     []UserSummary
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewChatroom membuat Chatroom.
+func NewChatroom(chatroom models.Chatroom) Chatroom {
+	return Chatroom{
+		ID:        chatroom.ID,
+		Name:      chatroom.Name,
+		OwnerID:   chatroom.OwnerID,
+		IsGroup:   chatroom.IsGroup,
+		Users:     UserSummaries(chatroom.Users),
+		CreatedAt: chatroom.CreatedAt,
+		UpdatedAt: chatroom.UpdatedAt,
+	}
+}
+
+// Chatrooms membuat daftar Chatroom.
+func Chatrooms(chatrooms []models.Chatroom) []Chatroom {
+	result := make([]Chatroom, 0, len(chatrooms))
+	for _, chatroom := range chatrooms {
+		result = append(result, NewChatroom(chatroom))
+	}
+	return result
+}
+
+// Message adalah pesan di chatroom beserta pengirimnya.
+type Message struct {
+	ID         uint
+	Message    string
+	File       string
+	ChatroomID uint
+	UserID     uint
+	User       UserSummary
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewMessage membuat Message.
+func NewMessage(message models.Message) Message {
+	return Message{
+		ID:         message.ID,
+		Message:    message.Message,
+		File:       message.File,
+		ChatroomID: message.ChatroomID,
+		UserID:     message.UserID,
+		User:       NewUserSummary(message.User),
+		CreatedAt:  message.CreatedAt,
+		UpdatedAt:  message.UpdatedAt,
+	}
+}
+
+// Messages membuat daftar Message.
+func Messages(messages []models.Message) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, NewMessage(message))
+	}
+	return result
+}
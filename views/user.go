@@ -0,0 +1,239 @@
+// Package views berisi bentuk respons JSON untuk model. Handler tidak
+// mengirim model GORM secara langsung agar field sensitif, seperti hash
+// password atau email user lain, tidak ikut terkirim ke client.
+//
+// Nama field JSON mengikuti respons yang sudah dipakai client sebelumnya.
+package views
+
+import (
+	"time"
+
+	"social-media-backend/imaging"
+	"social-media-backend/models"
+	"social-media-backend/policy"
+	"social-media-backend/richtext"
+)
+
+// UserSummary adalah data publik user yang boleh dilihat user lain, misalnya
+// penulis feed, follower, atau peserta chatroom.
+type UserSummary struct {
+	ID                   uint
+	Fullname             string
+	Username             string
+	PhotoProfile         string
+	PhotoProfileVariants map[string]string
+}
+
+// NewUserSummary membuat UserSummary dari user.
+func NewUserSummary(user models.User) UserSummary {
+	return UserSummary{
+		ID:                   user.ID,
+		Fullname:             user.Fullname,
+		Username:             user.Username,
+		PhotoProfile:         user.PhotoProfile,
+		PhotoProfileVariants: photoVariants(user),
+	}
+}
+
+// UserSummaries membuat daftar UserSummary. Hasilnya tidak pernah nil agar
+// client selalu menerima array.
+func UserSummaries(users []models.User) []UserSummary {
+	result := make([]UserSummary, 0, len(users))
+	for _, user := range users {
+		result = append(result, NewUserSummary(user))
+	}
+	return result
+}
+
+// PublicUser adalah data profil user yang ditampilkan di halaman profilnya.
+// Field yang disembunyikan pemiliknya (lihat policy.FieldVisibility) bernilai
+// null.
+type PublicUser struct {
+	ID                   uint
+	Fullname             string
+	Username             string
+	PhotoProfile         string
+	PhotoProfileVariants map[string]string
+	Banner               string
+	BannerVariants       map[string]string
+	Bio                  string
+	BioEntities          []richtext.Entity
+	Location             *string
+	Pronouns             *string
+	Links                []string
+	JenisKelamin         *string
+	// TanggalLahir berformat YYYY-MM-DD, atau MM-DD jika pemiliknya hanya
+	// menampilkan bulan dan tanggal.
+	TanggalLahir *string
+	IsPrivate    bool
+	CreatedAt    time.Time
+}
+
+// NewPublicUser membuat PublicUser dari user sesuai pengaturan visibilitas
+// field profilnya.
+func NewPublicUser(user models.User) PublicUser {
+	view := PublicUser{
+		ID:                   user.ID,
+		Fullname:             user.Fullname,
+		Username:             user.Username,
+		PhotoProfile:         user.PhotoProfile,
+		PhotoProfileVariants: photoVariants(user),
+		Banner:               user.Banner,
+		BannerVariants:       imageVariants(user.Banner, user.BannerVariants, imaging.BannerSizes),
+		Bio:                  user.Bio,
+		BioEntities:          richtext.Parse(user.Bio),
+		IsPrivate:            user.IsPrivate,
+		CreatedAt:            user.CreatedAt,
+	}
+	public := func(field string) bool {
+		return policy.FieldVisibility(user, field) == policy.VisibilityPublic
+	}
+	if public(policy.FieldLocation) {
+		view.Location = &user.Location
+	}
+	if public(policy.FieldPronouns) {
+		view.Pronouns = &user.Pronouns
+	}
+	if public(policy.FieldLinks) {
+		view.Links = links(user.Links)
+	}
+	if public(policy.FieldJenisKelamin) {
+		view.JenisKelamin = &user.JenisKelamin
+	}
+	if user.TanggalLahir != nil {
+		var birthdate string
+		switch policy.FieldVisibility(user, policy.FieldTanggalLahir) {
+		case policy.VisibilityPublic:
+			birthdate = user.TanggalLahir.Format("2006-01-02")
+		case policy.VisibilityMonthDay:
+			birthdate = user.TanggalLahir.Format("01-02")
+		}
+		if birthdate != "" {
+			view.TanggalLahir = &birthdate
+		}
+	}
+	return view
+}
+
+// photoVariants mengembalikan path setiap ukuran foto profil user.
+func photoVariants(user models.User) map[string]string {
+	return imageVariants(user.PhotoProfile, user.PhotoVariants, imaging.AvatarSizes)
+}
+
+// imageVariants mengembalikan path gambar untuk setiap ukuran di sizes.
+// Gambar default dan gambar yang di-upload sebelum ada pemrosesan tidak
+// memiliki variant, sehingga semua ukuran memakai path aslinya. Hasilnya
+// kosong jika tidak ada gambar.
+func imageVariants(path string, variants map[string]string, sizes []imaging.Size) map[string]string {
+	result := make(map[string]string, len(sizes))
+	if path == "" {
+		return result
+	}
+	for _, size := range sizes {
+		result[size.Name] = path
+		if variant := variants[size.Name]; variant != "" {
+			result[size.Name] = variant
+		}
+	}
+	return result
+}
+
+// links mengembalikan daftar tautan yang tidak pernah nil.
+func links(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// Profile adalah data akun milik user yang sedang login.
+type Profile struct {
+	ID                   uint
+	Fullname             string
+	Username             string
+	Email                string
+	PhotoProfile         string
+	PhotoProfileVariants map[string]string
+	JenisKelamin         string
+	TanggalLahir         *time.Time
+	EmailVerifiedAt      *time.Time
+	Role                 string
+	TOTPEnabled          bool
+	IsPrivate            bool
+	Banner               string
+	BannerVariants       map[string]string
+	Bio                  string
+	BioEntities          []richtext.Entity
+	Location             string
+	Pronouns             string
+	Links                []string
+	// Visibility adalah visibilitas setiap field profil bagi user lain.
+	Visibility map[string]string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewProfile membuat Profile dari user.
+func NewProfile(user models.User) Profile {
+	return Profile{
+		ID:                   user.ID,
+		Fullname:             user.Fullname,
+		Username:             user.Username,
+		Email:                user.Email,
+		PhotoProfile:         user.PhotoProfile,
+		PhotoProfileVariants: photoVariants(user),
+		JenisKelamin:         user.JenisKelamin,
+		TanggalLahir:         user.TanggalLahir,
+		EmailVerifiedAt:      user.EmailVerifiedAt,
+		Role:                 policy.RoleOf(user),
+		TOTPEnabled:          user.TOTPEnabled,
+		IsPrivate:            user.IsPrivate,
+		Banner:               user.Banner,
+		BannerVariants:       imageVariants(user.Banner, user.BannerVariants, imaging.BannerSizes),
+		Bio:                  user.Bio,
+		BioEntities:          richtext.Parse(user.Bio),
+		Location:             user.Location,
+		Pronouns:             user.Pronouns,
+		Links:                links(user.Links),
+		Visibility:           policy.FieldVisibilities(user),
+		CreatedAt:            user.CreatedAt,
+		UpdatedAt:            user.UpdatedAt,
+	}
+}
+
+// AdminUser adalah data user untuk halaman pengelolaan user oleh admin.
+type AdminUser struct {
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Fullname        string     `json:"fullname"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPEnabled     bool       `json:"totp_enabled"`
+	DeactivatedAt   *time.Time `json:"deactivated_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// NewAdminUser membuat AdminUser dari user.
+func NewAdminUser(user models.User) AdminUser {
+	return AdminUser{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		Fullname:        user.Fullname,
+		Role:            policy.RoleOf(user),
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		TOTPEnabled:     user.TOTPEnabled,
+		DeactivatedAt:   user.DeactivatedAt,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+// AdminUsers membuat daftar AdminUser.
+func AdminUsers(users []models.User) []AdminUser {
+	result := make([]AdminUser, 0, len(users))
+	for _, user := range users {
+		result = append(result, NewAdminUser(user))
+	}
+	return result
+}